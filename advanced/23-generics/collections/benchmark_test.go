@@ -0,0 +1,102 @@
+package collections
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// Jalankan dengan: go test -bench . -benchmem ./advanced/23-generics/collections
+// Setiap struktur dibandingkan dengan cara naif memakai tipe bawaan.
+
+func BenchmarkSetIntersect(b *testing.B) {
+	x, y := NewSet[int](), NewSet[int]()
+	for i := 0; i < 10_000; i++ {
+		x.Add(i)
+		y.Add(i * 3)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = x.Intersect(y)
+	}
+}
+
+func BenchmarkOrderedMap(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	b.Run("OrderedMap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m := NewOrderedMap[string, int]()
+			for j, k := range keys {
+				m.Set(k, j)
+			}
+			_ = m.Keys()
+		}
+	})
+	// alternatif naif: map biasa + sort key setiap kali butuh urutan
+	b.Run("map+sort", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m := make(map[string]int)
+			for j, k := range keys {
+				m[k] = j
+			}
+			out := make([]string, 0, len(m))
+			for k := range m {
+				out = append(out, k)
+			}
+			sort.Slice(out, func(a, b int) bool { return m[out[a]] < m[out[b]] })
+		}
+	})
+}
+
+func BenchmarkQueue(b *testing.B) {
+	// antrian yang selalu berisi ~100 elemen: push satu, pop satu
+	b.Run("Deque", func(b *testing.B) {
+		b.ReportAllocs()
+		d := NewDeque[int](128)
+		for i := 0; i < 100; i++ {
+			d.PushBack(i)
+		}
+		for i := 0; i < b.N; i++ {
+			d.PushBack(i)
+			d.PopFront()
+		}
+	})
+	b.Run("slice", func(b *testing.B) {
+		b.ReportAllocs()
+		q := make([]int, 100)
+		for i := 0; i < b.N; i++ {
+			q = append(q, i)
+			q = q[1:]
+		}
+	})
+}
+
+func BenchmarkPriorityQueue(b *testing.B) {
+	b.ReportAllocs()
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+	for i := 0; i < b.N; i++ {
+		pq.Push((i * 7919) % 1000)
+		if pq.Len() > 1000 {
+			pq.Pop()
+		}
+	}
+}
+
+func BenchmarkLRU(b *testing.B) {
+	cache := NewLRU[int, int](1000, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := (i * 7919) % 2000 // hit rate sekitar 50%
+		if _, ok := cache.Get(k); !ok {
+			cache.Put(k, i)
+		}
+	}
+}
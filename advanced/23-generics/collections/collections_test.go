@@ -0,0 +1,257 @@
+package collections
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetOperations(t *testing.T) {
+	backend := NewSet("go", "rust", "java")
+	mobile := NewSet("kotlin", "swift", "java")
+
+	cases := map[string]struct {
+		got  Set[string]
+		want []string
+	}{
+		"union":     {backend.Union(mobile), []string{"go", "java", "kotlin", "rust", "swift"}},
+		"intersect": {backend.Intersect(mobile), []string{"java"}},
+		"diff":      {backend.Diff(mobile), []string{"go", "rust"}},
+		"symdiff":   {backend.SymmetricDiff(mobile), []string{"go", "kotlin", "rust", "swift"}},
+	}
+	for name, c := range cases {
+		if got := Sorted(c.got); !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %v want %v", name, got, c.want)
+		}
+	}
+
+	if backend.Len() != 3 || !backend.Contains("go") {
+		t.Fatal("set operations must not modify the receiver")
+	}
+	if !NewSet("go").IsSubset(backend) || backend.IsSubset(NewSet("go")) {
+		t.Fatal("IsSubset")
+	}
+	if !backend.Equal(NewSet("java", "go", "rust", "go")) {
+		t.Fatal("Equal ignores order and duplicates")
+	}
+
+	backend.Remove("java")
+	for lang := range backend {
+		if lang == "java" {
+			t.Fatal("Remove")
+		}
+	}
+}
+
+func TestOrderedMapKeepsInsertionOrder(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	for i, k := range []string{"zeta", "alpha", "mid", "beta"} {
+		m.Set(k, i)
+	}
+	if m.Set("alpha", 100) {
+		t.Fatal("updating an existing key must report false")
+	}
+	m.Delete("mid")
+	m.Set("mid", 7)
+
+	if got := strings.Join(m.Keys(), ","); got != "zeta,alpha,beta,mid" {
+		t.Fatalf("keys %s", got)
+	}
+	if v, _ := m.Get("alpha"); v != 100 || !slices.Equal(m.Values(), []int{0, 100, 3, 7}) {
+		t.Fatalf("values %v", m.Values())
+	}
+
+	var seen []string
+	m.All()(func(k string, v int) bool {
+		seen = append(seen, fmt.Sprint(k, "=", v))
+		m.Delete(k) // hapus key yang sedang di-yield boleh
+		return len(seen) < 3
+	})
+	if strings.Join(seen, " ") != "zeta=0 alpha=100 beta=3" || m.Len() != 1 {
+		t.Fatalf("iteration %v, left %v", seen, m.Keys())
+	}
+	if k, v, ok := m.Oldest(); k != "mid" || v != 7 || !ok {
+		t.Fatal("Oldest")
+	}
+}
+
+func TestDequeMatchesSlice(t *testing.T) {
+	var d Deque[int] // zero value
+	var model []int
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20000; i++ {
+		switch rng.Intn(4) {
+		case 0:
+			d.PushBack(i)
+			model = append(model, i)
+		case 1:
+			d.PushFront(i)
+			model = append([]int{i}, model...)
+		case 2:
+			v, ok := d.PopFront()
+			if ok != (len(model) > 0) || (ok && v != model[0]) {
+				t.Fatalf("step %d: PopFront %d %v", i, v, ok)
+			}
+			if ok {
+				model = model[1:]
+			}
+		case 3:
+			v, ok := d.PopBack()
+			if ok != (len(model) > 0) || (ok && v != model[len(model)-1]) {
+				t.Fatalf("step %d: PopBack %d %v", i, v, ok)
+			}
+			if ok {
+				model = model[:len(model)-1]
+			}
+		}
+		if d.Len() != len(model) {
+			t.Fatalf("step %d: len %d want %d", i, d.Len(), len(model))
+		}
+	}
+	if !slices.Equal(d.ToSlice(), model) {
+		t.Fatal("final contents differ")
+	}
+	if len(model) > 0 && d.At(len(model)-1) != model[len(model)-1] {
+		t.Fatal("At")
+	}
+
+	big := NewDeque[int](0)
+	for i := 0; i < 1000; i++ {
+		big.PushBack(i)
+	}
+	for i := 0; i < 995; i++ {
+		big.PopFront()
+	}
+	if big.Cap() > 32 || !slices.Equal(big.ToSlice(), []int{995, 996, 997, 998, 999}) {
+		t.Fatalf("deque must shrink after draining, cap %d %v", big.Cap(), big.ToSlice())
+	}
+
+	sized := NewDeque[int](100)
+	for i := 0; i < 1000; i++ {
+		sized.PushBack(i)
+	}
+	for sized.Len() > 0 {
+		sized.PopBack()
+	}
+	if sized.Cap() != 128 {
+		t.Fatalf("deque must not shrink below its initial capacity, cap %d", sized.Cap())
+	}
+}
+
+type task struct {
+	name     string
+	priority int
+}
+
+func TestPriorityQueueUpdateAndRemove(t *testing.T) {
+	pq := NewPriorityQueue(func(a, b task) bool { return a.priority > b.priority })
+
+	pq.Push(task{"email", 1})
+	report := pq.Push(task{"report", 2})
+	deploy := pq.Push(task{"deploy", 5})
+	pq.Push(task{"backup", 3})
+
+	if top, _ := pq.Peek(); top.name != "deploy" {
+		t.Fatalf("peek %v", top)
+	}
+	pq.Update(report, task{"report", 10})
+	pq.Remove(deploy)
+
+	var order []string
+	for pq.Len() > 0 {
+		v, _ := pq.Pop()
+		order = append(order, v.name)
+	}
+	if strings.Join(order, ",") != "report,backup,email" {
+		t.Fatalf("order %v", order)
+	}
+	if pq.Update(report, task{"report", 1}) || pq.Remove(deploy) {
+		t.Fatal("items that left the queue must be rejected")
+	}
+	if _, ok := pq.Pop(); ok {
+		t.Fatal("empty queue")
+	}
+}
+
+func TestPriorityQueueSortsLikeSort(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+	var items []*PQItem[int]
+	var want []int
+	for i := 0; i < 500; i++ {
+		v := rng.Intn(1000)
+		items = append(items, pq.Push(v))
+		want = append(want, v)
+	}
+	// ubah separuh prioritas secara acak
+	for i := 0; i < 250; i++ {
+		j := rng.Intn(len(items))
+		v := rng.Intn(1000)
+		pq.Update(items[j], v)
+		want[j] = v
+	}
+	sort.Ints(want)
+
+	for i, w := range want {
+		if v, _ := pq.Pop(); v != w {
+			t.Fatalf("pop %d: got %d want %d", i, v, w)
+		}
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	var evicted []string
+	cache := NewLRU(2, func(k string, v int) { evicted = append(evicted, k) })
+
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Get("a") // a jadi paling baru
+	if !cache.Put("c", 3) {
+		t.Fatal("third key must evict")
+	}
+	if _, ok := cache.Get("b"); ok || strings.Join(evicted, ",") != "b" {
+		t.Fatalf("b must be evicted, evicted %v", evicted)
+	}
+
+	cache.Peek("a") // Peek tidak mengubah urutan
+	cache.Put("d", 4)
+	if strings.Join(cache.Keys(), ",") != "c,d" {
+		t.Fatalf("keys %v", cache.Keys())
+	}
+
+	cache.Put("c", 30) // update juga dihitung sebagai akses
+	cache.Put("e", 5)
+	if v, ok := cache.Get("c"); !ok || v != 30 {
+		t.Fatal("updated key must survive")
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Fatalf("stats %d/%d", hits, misses)
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	cache := NewLRU[int, int](64, nil)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				k := (g*31 + i) % 100
+				if v, ok := cache.Get(k); ok && v != k*k {
+					t.Errorf("key %d has %d", k, v)
+				}
+				cache.Put(k, k*k)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if cache.Len() != 64 {
+		t.Fatalf("len %d", cache.Len())
+	}
+}
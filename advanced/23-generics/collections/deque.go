@@ -0,0 +1,134 @@
+package collections
+
+// Deque adalah antrian dua arah di atas ring buffer. Push dan Pop di kedua
+// ujung O(1) amortized, dan berbeda dengan queue dari slice
+// (q = q[1:]) memori di depan dipakai ulang, bukan bocor sampai slice
+// di-append ulang.
+//
+// Kapasitas selalu pangkat dua supaya indeks cukup di-mask, bukan modulo.
+type Deque[T any] struct {
+	buf    []T
+	head   int // indeks elemen pertama
+	len    int
+	minCap int // capacity dari NewDeque, buffer tidak pernah dikecilkan di bawahnya
+}
+
+const minDequeCap = 8
+
+// NewDeque dengan capacity awal opsional; zero value Deque juga siap dipakai.
+func NewDeque[T any](capacity int) *Deque[T] {
+	c := minDequeCap
+	for c < capacity {
+		c <<= 1
+	}
+	return &Deque[T]{buf: make([]T, c), minCap: c}
+}
+
+func (d *Deque[T]) Len() int { return d.len }
+
+func (d *Deque[T]) Cap() int { return len(d.buf) }
+
+func (d *Deque[T]) PushBack(v T) {
+	d.growIfFull()
+	d.buf[(d.head+d.len)&(len(d.buf)-1)] = v
+	d.len++
+}
+
+func (d *Deque[T]) PushFront(v T) {
+	d.growIfFull()
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = v
+	d.len++
+}
+
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.len == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	// nolkan slot supaya pointer di dalamnya bisa di-GC
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) & (len(d.buf) - 1)
+	d.len--
+	d.shrinkIfSparse()
+	return v, true
+}
+
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.len == 0 {
+		return zero, false
+	}
+	i := (d.head + d.len - 1) & (len(d.buf) - 1)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.len--
+	d.shrinkIfSparse()
+	return v, true
+}
+
+func (d *Deque[T]) Front() (T, bool) {
+	if d.len == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+func (d *Deque[T]) Back() (T, bool) {
+	if d.len == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.len-1)&(len(d.buf)-1)], true
+}
+
+// At mengembalikan elemen ke-i dari depan. Panic kalau i di luar jangkauan,
+// sama seperti indeks slice.
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.len {
+		panic("collections: deque index out of range")
+	}
+	return d.buf[(d.head+i)&(len(d.buf)-1)]
+}
+
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.len = 0, 0
+}
+
+func (d *Deque[T]) ToSlice() []T {
+	out := make([]T, d.len)
+	for i := range out {
+		out[i] = d.buf[(d.head+i)&(len(d.buf)-1)]
+	}
+	return out
+}
+
+func (d *Deque[T]) growIfFull() {
+	if d.buf == nil {
+		d.buf = make([]T, minDequeCap)
+		return
+	}
+	if d.len == len(d.buf) {
+		d.resize(len(d.buf) << 1)
+	}
+}
+
+// shrinkIfSparse mengecilkan buffer kalau terisi kurang dari seperempat,
+// supaya deque yang sempat besar tidak menahan memori selamanya. Capacity
+// yang diminta di NewDeque tetap dipertahankan supaya pola isi-kosongkan
+// tidak terus-menerus alokasi ulang.
+func (d *Deque[T]) shrinkIfSparse() {
+	if len(d.buf) > max(d.minCap, minDequeCap) && d.len <= len(d.buf)/4 {
+		d.resize(len(d.buf) >> 1)
+	}
+}
+
+func (d *Deque[T]) resize(size int) {
+	buf := make([]T, size)
+	n := copy(buf, d.buf[d.head:min(d.head+d.len, len(d.buf))])
+	copy(buf[n:], d.buf[:d.len-n])
+	d.buf, d.head = buf, 0
+}
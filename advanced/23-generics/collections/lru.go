@@ -0,0 +1,104 @@
+package collections
+
+import "sync"
+
+// LRU adalah cache berkapasitas tetap yang membuang entry paling lama tidak
+// diakses ketika penuh. Dibangun di atas OrderedMap: urutan insert dipakai
+// sebagai urutan akses, key yang baru diakses dipindah ke belakang dan yang
+// dibuang selalu yang paling depan.
+//
+// Berbeda dengan tipe lain di package ini, LRU aman dipakai dari banyak
+// goroutine karena Get pun mengubah urutan.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	entries  *OrderedMap[K, V]
+	onEvict  func(K, V)
+
+	hits, misses int
+}
+
+// NewLRU membuat cache. onEvict opsional, dipanggil saat entry dibuang
+// karena cache penuh (bukan saat Remove), misalnya untuk menutup resource.
+func NewLRU[K comparable, V any](capacity int, onEvict func(key K, value V)) *LRU[K, V] {
+	if capacity <= 0 {
+		panic("collections: LRU capacity must be positive")
+	}
+	if onEvict == nil {
+		onEvict = func(K, V) {}
+	}
+	return &LRU[K, V]{capacity: capacity, entries: NewOrderedMap[K, V](), onEvict: onEvict}
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.entries.Get(key)
+	if !ok {
+		c.misses++
+		return v, false
+	}
+	c.hits++
+	c.entries.MoveToBack(key)
+	return v, true
+}
+
+// Peek membaca tanpa menandai key sebagai baru diakses.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Get(key)
+}
+
+// Put menyimpan atau memperbarui key. Hasilnya true kalau ada entry lain
+// yang dibuang.
+func (c *LRU[K, V]) Put(key K, value V) bool {
+	c.mu.Lock()
+
+	if !c.entries.Set(key, value) {
+		c.entries.MoveToBack(key)
+		c.mu.Unlock()
+		return false
+	}
+	if c.entries.Len() <= c.capacity {
+		c.mu.Unlock()
+		return false
+	}
+
+	oldKey, oldValue, _ := c.entries.Oldest()
+	c.entries.Delete(oldKey)
+	c.mu.Unlock()
+
+	// callback di luar lock supaya boleh memanggil method cache lagi
+	c.onEvict(oldKey, oldValue)
+	return true
+}
+
+func (c *LRU[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.entries.Delete(key)
+	return ok
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+// Keys mengembalikan key dari yang paling lama sampai yang paling baru
+// diakses.
+func (c *LRU[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Keys()
+}
+
+// Stats mengembalikan jumlah hit dan miss dari Get.
+func (c *LRU[K, V]) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
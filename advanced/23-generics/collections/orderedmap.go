@@ -0,0 +1,138 @@
+package collections
+
+// OrderedMap adalah map yang mengingat urutan insert, seperti dict di
+// Python. Get/Set/Delete tetap O(1): map menyimpan pointer ke node di
+// doubly linked list yang menjaga urutan.
+type OrderedMap[K comparable, V any] struct {
+	index map[K]*omEntry[K, V]
+	root  omEntry[K, V] // sentinel; root.next paling lama, root.prev paling baru
+}
+
+type omEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *omEntry[K, V]
+}
+
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	m := &OrderedMap[K, V]{index: make(map[K]*omEntry[K, V])}
+	m.root.next = &m.root
+	m.root.prev = &m.root
+	return m
+}
+
+func (m *OrderedMap[K, V]) Len() int { return len(m.index) }
+
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.index[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.index[key]
+	return ok
+}
+
+// Set menambah key di akhir urutan. Kalau key sudah ada, nilainya diganti
+// dan posisinya tetap. Hasilnya true kalau key baru.
+func (m *OrderedMap[K, V]) Set(key K, value V) bool {
+	if e, ok := m.index[key]; ok {
+		e.value = value
+		return false
+	}
+	e := &omEntry[K, V]{key: key, value: value}
+	m.linkBefore(e, &m.root)
+	m.index[key] = e
+	return true
+}
+
+func (m *OrderedMap[K, V]) Delete(key K) (V, bool) {
+	e, ok := m.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.unlink(e)
+	delete(m.index, key)
+	return e.value, true
+}
+
+// MoveToBack menjadikan key yang paling baru, dipakai LRU untuk menandai
+// key yang baru diakses.
+func (m *OrderedMap[K, V]) MoveToBack(key K) bool {
+	e, ok := m.index[key]
+	if !ok {
+		return false
+	}
+	m.unlink(e)
+	m.linkBefore(e, &m.root)
+	return true
+}
+
+// Oldest mengembalikan pasangan key/value pertama dalam urutan.
+func (m *OrderedMap[K, V]) Oldest() (K, V, bool) {
+	if len(m.index) == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	e := m.root.next
+	return e.key, e.value, true
+}
+
+func (m *OrderedMap[K, V]) Newest() (K, V, bool) {
+	if len(m.index) == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	e := m.root.prev
+	return e.key, e.value, true
+}
+
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.index))
+	for e := m.root.next; e != &m.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func (m *OrderedMap[K, V]) Values() []V {
+	values := make([]V, 0, len(m.index))
+	for e := m.root.next; e != &m.root; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// All adalah iterator urut insert dengan bentuk iter.Seq2, berhenti kalau
+// yield mengembalikan false. Jangan Delete selain key yang sedang
+// di-yield selama iterasi.
+func (m *OrderedMap[K, V]) All() func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		for e := m.root.next; e != &m.root; {
+			next := e.next
+			if !yield(e.key, e.value) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) linkBefore(e, at *omEntry[K, V]) {
+	e.next = at
+	e.prev = at.prev
+	at.prev.next = e
+	at.prev = e
+}
+
+func (m *OrderedMap[K, V]) unlink(e *omEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+}
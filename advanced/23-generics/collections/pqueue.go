@@ -0,0 +1,101 @@
+package collections
+
+import "container/heap"
+
+// PQItem adalah handle ke elemen di PriorityQueue. Simpan handle dari Push
+// kalau prioritasnya perlu diubah nanti dengan Update.
+type PQItem[T any] struct {
+	Value T
+	index int // posisi di heap, -1 kalau sudah keluar dari queue
+}
+
+// PriorityQueue adalah binary heap di atas container/heap. less menentukan
+// urutan: less(a, b) true berarti a keluar lebih dulu. Jadi
+// func(a, b int) bool { return a < b } adalah min-heap.
+type PriorityQueue[T any] struct {
+	h pqHeap[T]
+}
+
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{h: pqHeap[T]{less: less}}
+}
+
+func (pq *PriorityQueue[T]) Len() int { return len(pq.h.items) }
+
+func (pq *PriorityQueue[T]) Push(v T) *PQItem[T] {
+	item := &PQItem[T]{Value: v}
+	heap.Push(&pq.h, item)
+	return item
+}
+
+func (pq *PriorityQueue[T]) Pop() (T, bool) {
+	if len(pq.h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(&pq.h).(*PQItem[T]).Value, true
+}
+
+func (pq *PriorityQueue[T]) Peek() (T, bool) {
+	if len(pq.h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return pq.h.items[0].Value, true
+}
+
+// Update mengganti nilai item (dan dengan itu prioritasnya) lalu
+// memperbaiki posisinya dalam O(log n), misalnya untuk decrease-key di
+// algoritma Dijkstra. Hasilnya false kalau item sudah tidak ada di queue.
+func (pq *PriorityQueue[T]) Update(item *PQItem[T], v T) bool {
+	if !pq.owns(item) {
+		return false
+	}
+	item.Value = v
+	heap.Fix(&pq.h, item.index)
+	return true
+}
+
+// Remove mengeluarkan item dari tengah queue dalam O(log n).
+func (pq *PriorityQueue[T]) Remove(item *PQItem[T]) bool {
+	if !pq.owns(item) {
+		return false
+	}
+	heap.Remove(&pq.h, item.index)
+	return true
+}
+
+func (pq *PriorityQueue[T]) owns(item *PQItem[T]) bool {
+	return item != nil && item.index >= 0 && item.index < len(pq.h.items) && pq.h.items[item.index] == item
+}
+
+// pqHeap mengimplementasikan heap.Interface. Method-nya dipanggil
+// container/heap, bukan oleh pengguna PriorityQueue.
+type pqHeap[T any] struct {
+	items []*PQItem[T]
+	less  func(a, b T) bool
+}
+
+func (h *pqHeap[T]) Len() int           { return len(h.items) }
+func (h *pqHeap[T]) Less(i, j int) bool { return h.less(h.items[i].Value, h.items[j].Value) }
+
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *pqHeap[T]) Push(x any) {
+	item := x.(*PQItem[T])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *pqHeap[T]) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	item.index = -1
+	return item
+}
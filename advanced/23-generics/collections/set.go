@@ -0,0 +1,127 @@
+// Package collections berisi struktur data generic yang tidak ada di
+// standard library: Set, OrderedMap, Deque, PriorityQueue, dan LRU.
+//
+// Kecuali LRU, semua tipe di sini tidak aman dipakai dari banyak goroutine
+// tanpa lock sendiri, sama seperti map dan slice bawaan.
+package collections
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Set adalah himpunan di atas map[T]struct{} (struct{} tidak makan memori).
+// Karena tipe dasarnya map, len(s) dan for range langsung bisa dipakai.
+type Set[T comparable] map[T]struct{}
+
+func NewSet[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+func (s Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+func (s Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s, item)
+	}
+}
+
+func (s Set[T]) Contains(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+func (s Set[T]) Len() int { return len(s) }
+
+func (s Set[T]) Clone() Set[T] {
+	out := make(Set[T], len(s))
+	for item := range s {
+		out[item] = struct{}{}
+	}
+	return out
+}
+
+// Union: anggota s atau other.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	out := s.Clone()
+	for item := range other {
+		out[item] = struct{}{}
+	}
+	return out
+}
+
+// Intersect: anggota s dan other. Iterasi dari set yang lebih kecil.
+func (s Set[T]) Intersect(other Set[T]) Set[T] {
+	small, big := s, other
+	if len(small) > len(big) {
+		small, big = big, small
+	}
+	out := make(Set[T])
+	for item := range small {
+		if big.Contains(item) {
+			out[item] = struct{}{}
+		}
+	}
+	return out
+}
+
+// Diff: anggota s yang tidak ada di other.
+func (s Set[T]) Diff(other Set[T]) Set[T] {
+	out := make(Set[T])
+	for item := range s {
+		if !other.Contains(item) {
+			out[item] = struct{}{}
+		}
+	}
+	return out
+}
+
+// SymmetricDiff: anggota salah satu saja, tidak keduanya.
+func (s Set[T]) SymmetricDiff(other Set[T]) Set[T] {
+	out := s.Diff(other)
+	for item := range other {
+		if !s.Contains(item) {
+			out[item] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+	for item := range s {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
+
+// Items mengembalikan anggota dengan urutan acak, seperti range di map.
+func (s Set[T]) Items() []T {
+	out := make([]T, 0, len(s))
+	for item := range s {
+		out = append(out, item)
+	}
+	return out
+}
+
+// Sorted mengembalikan anggota set secara terurut, berguna untuk output yang
+// deterministik.
+func Sorted[T cmp.Ordered](s Set[T]) []T {
+	out := s.Items()
+	slices.Sort(out)
+	return out
+}
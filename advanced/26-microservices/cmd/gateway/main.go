@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"go-journey/advanced/26-microservices/gateway"
+	"go-journey/advanced/26-microservices/registry"
+)
+
+func main() {
+	configPath := flag.String("config", "routes.json", "path ke file routes gateway")
+	addr := flag.String("addr", ":8080", "alamat listen gateway")
+	adminAddr := flag.String("admin-addr", "127.0.0.1:9090", "alamat listen API registry dan health detail, jangan dibuka ke publik")
+	insecureAdmin := flag.Bool("insecure-admin", false, "izinkan API admin tanpa REGISTRY_TOKEN, hanya kalau -admin-addr loopback")
+	ttl := flag.Duration("registry-ttl", 30*time.Second, "instance tanpa heartbeat selama ini dianggap mati")
+	flag.Parse()
+
+	cfg, err := gateway.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// listener admin yang kebetulan sama dengan alamat upstream akan
+	// menerima traffic route yang sudah terautentikasi
+	for service, addrs := range cfg.Services {
+		for _, upstream := range addrs {
+			if u, err := url.Parse(upstream); err == nil && u.Host == *adminAddr {
+				log.Fatalf("-admin-addr %s is also an upstream of service %q", *adminAddr, service)
+			}
+		}
+	}
+
+	// registry punya listener sendiri: kalau dipasang di listener publik,
+	// client mana pun bisa mendaftarkan host-nya sebagai upstream dan
+	// menerima traffic yang sudah terautentikasi
+	adminToken := os.Getenv("REGISTRY_TOKEN")
+	if adminToken == "" {
+		if !*insecureAdmin || !isLoopback(*adminAddr) {
+			log.Fatalf("REGISTRY_TOKEN is not set; refusing to serve the admin API on %s (use -insecure-admin with a loopback address for local development)", *adminAddr)
+		}
+		log.Printf("REGISTRY_TOKEN is not set, admin API on %s is unauthenticated", *adminAddr)
+	}
+
+	reg := registry.New(*ttl)
+
+	gw, err := gateway.New(cfg, reg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle(gateway.HealthEndpoint, registry.RequireToken(adminToken, gw.HealthHandler()))
+	adminMux.Handle("/", reg.Handler(adminToken))
+
+	admin := &http.Server{
+		Addr:              *adminAddr,
+		Handler:           adminMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("admin API listening on %s", *adminAddr)
+		log.Fatal(admin.ListenAndServe())
+	}()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           gw,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("gateway listening on %s with %d routes", *addr, len(cfg.Routes))
+	log.Fatal(server.ListenAndServe())
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,363 @@
+package eventbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClosed      = errors.New("eventbus: broker is closed")
+	ErrInvalidName = errors.New("eventbus: invalid topic or group name")
+)
+
+// nama topic dan group dipakai sebagai nama file, jadi dibatasi
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+const (
+	HeaderOriginalTopic  = "x-original-topic"
+	HeaderOriginalOffset = "x-original-offset"
+	HeaderDeliveryCount  = "x-delivery-count"
+	HeaderConsumerGroup  = "x-consumer-group"
+	HeaderContentType    = "content-type"
+)
+
+type Config struct {
+	// Dir tempat file log disimpan. Wajib diisi.
+	Dir string
+
+	// AckTimeout lama message boleh in-flight sebelum dikirim ulang.
+	AckTimeout time.Duration
+
+	// MaxDeliveries jumlah maksimal pengiriman sebuah message ke satu group.
+	// Setelah itu message dipindah ke dead-letter topic.
+	MaxDeliveries int
+
+	// DeadLetterSuffix ditambahkan ke nama topic asal, default ".dead-letter".
+	DeadLetterSuffix string
+
+	// SyncWrites memanggil fsync setiap publish, delivery dan ack. Lebih
+	// lambat tapi tidak ada data hilang walau mesin mati mendadak.
+	SyncWrites bool
+}
+
+type groupKey struct {
+	topic string
+	group string
+}
+
+// Broker adalah message broker embedded dengan semantik at-least-once:
+// message yang belum di-ack akan dikirim ulang, termasuk setelah restart.
+type Broker struct {
+	cfg Config
+
+	mu     sync.Mutex
+	closed bool
+	topics map[string]*topicLog
+	groups map[groupKey]*group
+
+	// signal ditutup dan diganti setiap ada message baru atau nack,
+	// untuk membangunkan Receive yang sedang menunggu.
+	signal chan struct{}
+
+	now func() time.Time
+}
+
+func Open(cfg Config) (*Broker, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("eventbus: Config.Dir is required")
+	}
+	if cfg.AckTimeout <= 0 {
+		cfg.AckTimeout = 30 * time.Second
+	}
+	if cfg.MaxDeliveries <= 0 {
+		cfg.MaxDeliveries = 5
+	}
+	if cfg.DeadLetterSuffix == "" {
+		cfg.DeadLetterSuffix = ".dead-letter"
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("eventbus: create dir: %w", err)
+	}
+
+	return &Broker{
+		cfg:    cfg,
+		topics: make(map[string]*topicLog),
+		groups: make(map[groupKey]*group),
+		signal: make(chan struct{}),
+		now:    time.Now,
+	}, nil
+}
+
+// DeadLetterTopic mengembalikan nama dead-letter topic untuk topic tertentu.
+func (b *Broker) DeadLetterTopic(topic string) string {
+	return topic + b.cfg.DeadLetterSuffix
+}
+
+func (b *Broker) Publish(ctx context.Context, topic string, payload []byte, headers map[string]string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, ErrClosed
+	}
+
+	offset, err := b.publishLocked(topic, payload, headers)
+	if err != nil {
+		return 0, err
+	}
+
+	b.broadcastLocked()
+
+	return offset, nil
+}
+
+// PublishJSON meng-encode v sebagai JSON, cocok untuk domain event seperti
+// "user.created".
+func (b *Broker) PublishJSON(ctx context.Context, topic string, v any) (int64, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return 0, fmt.Errorf("eventbus: encode payload: %w", err)
+	}
+
+	return b.Publish(ctx, topic, payload, map[string]string{HeaderContentType: "application/json"})
+}
+
+func (b *Broker) publishLocked(topic string, payload []byte, headers map[string]string) (int64, error) {
+	log, err := b.topicLocked(topic)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return 0, err
+	}
+
+	rec := record{
+		ID:      id,
+		Time:    b.now().UTC(),
+		Headers: copyHeaders(headers),
+		Payload: payload,
+	}
+	if err := log.append(rec); err != nil {
+		return 0, fmt.Errorf("eventbus: append to %s: %w", topic, err)
+	}
+
+	return log.nextOffset() - 1, nil
+}
+
+// Subscribe bergabung ke consumer group. Setiap group menerima semua message
+// di topic; beberapa Subscription di group yang sama saling berbagi message.
+func (b *Broker) Subscribe(topic, groupName string) (*Subscription, error) {
+	if !validName.MatchString(groupName) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, groupName)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	key := groupKey{topic: topic, group: groupName}
+	if _, ok := b.groups[key]; !ok {
+		log, err := b.topicLocked(topic)
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(b.cfg.Dir, topic+"@"+groupName+".acks")
+		acks, state, err := openAckLog(path, b.cfg.SyncWrites)
+		if err != nil {
+			return nil, err
+		}
+
+		b.groups[key] = newGroup(groupName, topic, log, acks, state)
+	}
+
+	return &Subscription{broker: b, topic: topic, group: groupName}, nil
+}
+
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	b.broadcastLocked()
+
+	var errs []error
+	for _, g := range b.groups {
+		errs = append(errs, g.acks.close())
+	}
+	for _, log := range b.topics {
+		errs = append(errs, log.close())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Broker) topicLocked(topic string) (*topicLog, error) {
+	if !validName.MatchString(topic) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, topic)
+	}
+
+	if log, ok := b.topics[topic]; ok {
+		return log, nil
+	}
+
+	log, err := openTopicLog(filepath.Join(b.cfg.Dir, topic+".log"), b.cfg.SyncWrites)
+	if err != nil {
+		return nil, err
+	}
+	b.topics[topic] = log
+
+	return log, nil
+}
+
+func (b *Broker) broadcastLocked() {
+	close(b.signal)
+	b.signal = make(chan struct{})
+}
+
+// nextLocked mencari message berikutnya untuk group. Kalau tidak ada,
+// wait berisi lama sampai ada delivery yang timeout (0 kalau tidak ada).
+func (b *Broker) nextLocked(g *group) (*Message, time.Duration, error) {
+	now := b.now()
+
+	for {
+		offset, attempt, wait := g.next(now, b.cfg.AckTimeout)
+		if offset < 0 {
+			return nil, wait, nil
+		}
+
+		rec, err := g.log.read(offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if attempt > b.cfg.MaxDeliveries {
+			if err := b.deadLetterLocked(g, rec, attempt-1); err != nil {
+				return nil, 0, err
+			}
+			b.broadcastLocked()
+			continue
+		}
+
+		// dicatat sebelum dikirim: kalau consumer membuat proses crash,
+		// attempt ini tetap terhitung setelah restart
+		if err := g.acks.appendDelivery(offset, attempt); err != nil {
+			return nil, 0, err
+		}
+
+		return &Message{
+			Topic:   g.topic,
+			Group:   g.name,
+			Offset:  rec.Offset,
+			ID:      rec.ID,
+			Time:    rec.Time,
+			Headers: copyHeaders(rec.Headers),
+			Payload: append([]byte(nil), rec.Payload...),
+			Attempt: attempt,
+			broker:  b,
+		}, 0, nil
+	}
+}
+
+func (b *Broker) deadLetterLocked(g *group, rec record, deliveries int) error {
+	headers := copyHeaders(rec.Headers)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers[HeaderOriginalTopic] = g.topic
+	headers[HeaderOriginalOffset] = strconv.FormatInt(rec.Offset, 10)
+	headers[HeaderDeliveryCount] = strconv.Itoa(deliveries)
+	headers[HeaderConsumerGroup] = g.name
+
+	if _, err := b.publishLocked(b.DeadLetterTopic(g.topic), rec.Payload, headers); err != nil {
+		return err
+	}
+
+	return g.ack(rec.Offset)
+}
+
+func (b *Broker) lookupGroup(topic, groupName string) (*group, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	g, ok := b.groups[groupKey{topic: topic, group: groupName}]
+	if !ok {
+		return nil, fmt.Errorf("eventbus: unknown group %q on topic %q", groupName, topic)
+	}
+
+	return g, nil
+}
+
+func (b *Broker) ack(m *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, err := b.lookupGroup(m.Topic, m.Group)
+	if err != nil {
+		return err
+	}
+
+	return g.ack(m.Offset)
+}
+
+func (b *Broker) nack(m *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, err := b.lookupGroup(m.Topic, m.Group)
+	if err != nil {
+		return err
+	}
+
+	if g.expire(m.Offset, m.Attempt, b.now()) {
+		b.broadcastLocked()
+	}
+
+	return nil
+}
+
+func copyHeaders(h map[string]string) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = v
+	}
+
+	return out
+}
+
+func newID() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", fmt.Errorf("eventbus: generate id: %w", err)
+	}
+
+	return hex.EncodeToString(buf[:]), nil
+}
@@ -0,0 +1,405 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type userCreated struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+func openTestBroker(t *testing.T, dir string, cfg Config) *Broker {
+	t.Helper()
+
+	cfg.Dir = dir
+	b, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("open broker: %v", err)
+	}
+
+	return b
+}
+
+func receive(t *testing.T, sub *Subscription) *Message {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	msg, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	return msg
+}
+
+func expectEmpty(t *testing.T, sub *Subscription) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	if msg, err := sub.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected no message, got %+v err=%v", msg, err)
+	}
+}
+
+func TestPublishAndConsumeJSON(t *testing.T) {
+	b := openTestBroker(t, t.TempDir(), Config{})
+	defer b.Close()
+
+	ctx := context.Background()
+	sub, err := b.Subscribe("user.created", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.PublishJSON(ctx, "user.created", userCreated{ID: 1, Username: "bisma"}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := receive(t, sub)
+
+	var ev userCreated
+	if err := msg.DecodeJSON(&ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Username != "bisma" || msg.Headers[HeaderContentType] != "application/json" || msg.Attempt != 1 {
+		t.Fatalf("unexpected message %+v / %+v", msg, ev)
+	}
+
+	if err := msg.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	expectEmpty(t, sub)
+}
+
+func TestReceiveWaitsForPublish(t *testing.T) {
+	b := openTestBroker(t, t.TempDir(), Config{})
+	defer b.Close()
+
+	sub, _ := b.Subscribe("orders", "billing")
+
+	time.AfterFunc(20*time.Millisecond, func() {
+		b.Publish(context.Background(), "orders", []byte("order-1"), nil)
+	})
+
+	if msg := receive(t, sub); string(msg.Payload) != "order-1" {
+		t.Fatalf("got %q", msg.Payload)
+	}
+}
+
+func TestConsumerGroups(t *testing.T) {
+	b := openTestBroker(t, t.TempDir(), Config{})
+	defer b.Close()
+
+	ctx := context.Background()
+	audit1, _ := b.Subscribe("user.created", "audit")
+	audit2, _ := b.Subscribe("user.created", "audit")
+	notify, _ := b.Subscribe("user.created", "notification")
+
+	b.Publish(ctx, "user.created", []byte("a"), nil)
+	b.Publish(ctx, "user.created", []byte("b"), nil)
+
+	// dua subscriber di group yang sama berbagi message
+	m1 := receive(t, audit1)
+	m2 := receive(t, audit2)
+	if string(m1.Payload) != "a" || string(m2.Payload) != "b" {
+		t.Fatalf("audit got %q and %q", m1.Payload, m2.Payload)
+	}
+	m1.Ack()
+	m2.Ack()
+	expectEmpty(t, audit1)
+
+	// group lain tetap menerima semua message
+	for _, want := range []string{"a", "b"} {
+		msg := receive(t, notify)
+		if string(msg.Payload) != want {
+			t.Fatalf("notification got %q, want %q", msg.Payload, want)
+		}
+		msg.Ack()
+	}
+}
+
+func TestRedeliveryOnAckTimeout(t *testing.T) {
+	b := openTestBroker(t, t.TempDir(), Config{AckTimeout: 20 * time.Millisecond})
+	defer b.Close()
+
+	sub, _ := b.Subscribe("payments", "ledger")
+	b.Publish(context.Background(), "payments", []byte("p-1"), nil)
+
+	first := receive(t, sub)
+	second := receive(t, sub)
+
+	if first.Offset != second.Offset || second.Attempt != 2 {
+		t.Fatalf("expected redelivery of offset %d, got %+v", first.Offset, second)
+	}
+
+	// nack dari delivery lama tidak berpengaruh
+	first.Nack()
+	second.Ack()
+	expectEmpty(t, sub)
+}
+
+func TestNackMovesToDeadLetterAfterMaxDeliveries(t *testing.T) {
+	b := openTestBroker(t, t.TempDir(), Config{MaxDeliveries: 2})
+	defer b.Close()
+
+	ctx := context.Background()
+	sub, _ := b.Subscribe("emails", "sender")
+	dlq, _ := b.Subscribe(b.DeadLetterTopic("emails"), "ops")
+
+	b.Publish(ctx, "emails", []byte("welcome"), map[string]string{"user": "1"})
+
+	receive(t, sub).Nack()
+	receive(t, sub).Nack()
+	expectEmpty(t, sub)
+
+	dead := receive(t, dlq)
+	if string(dead.Payload) != "welcome" ||
+		dead.Headers[HeaderOriginalTopic] != "emails" ||
+		dead.Headers[HeaderDeliveryCount] != "2" ||
+		dead.Headers[HeaderConsumerGroup] != "sender" ||
+		dead.Headers["user"] != "1" {
+		t.Fatalf("unexpected dead letter %+v", dead)
+	}
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	b := openTestBroker(t, dir, Config{SyncWrites: true})
+	sub, _ := b.Subscribe("user.created", "audit")
+	for _, p := range []string{"u1", "u2", "u3"} {
+		b.Publish(ctx, "user.created", []byte(p), nil)
+	}
+
+	receive(t, sub).Ack()
+	receive(t, sub) // u2 in-flight, belum di-ack
+	receive(t, sub).Ack()
+	b.Close()
+
+	b = openTestBroker(t, dir, Config{})
+	defer b.Close()
+
+	sub, _ = b.Subscribe("user.created", "audit")
+	msg := receive(t, sub)
+	if string(msg.Payload) != "u2" {
+		t.Fatalf("expected unacked u2 after restart, got %q", msg.Payload)
+	}
+	msg.Ack()
+	expectEmpty(t, sub)
+
+	if offset, _ := b.Publish(ctx, "user.created", []byte("u4"), nil); offset != 3 {
+		t.Fatalf("offsets must continue after restart, got %d", offset)
+	}
+}
+
+func TestTornWriteIsDiscarded(t *testing.T) {
+	dir := t.TempDir()
+
+	b := openTestBroker(t, dir, Config{})
+	b.Publish(context.Background(), "orders", []byte("ok"), nil)
+	b.Close()
+
+	f, err := os.OpenFile(filepath.Join(dir, "orders.log"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"offset":1,"id":"x","payl`)
+	f.Close()
+
+	b = openTestBroker(t, dir, Config{})
+	defer b.Close()
+
+	offset, err := b.Publish(context.Background(), "orders", []byte("next"), nil)
+	if err != nil || offset != 1 {
+		t.Fatalf("expected offset 1 after recovery, got %d err=%v", offset, err)
+	}
+
+	sub, _ := b.Subscribe("orders", "reader")
+	for _, want := range []string{"ok", "next"} {
+		if msg := receive(t, sub); string(msg.Payload) != want {
+			t.Fatalf("got %q, want %q", msg.Payload, want)
+		}
+	}
+}
+
+func TestInvalidNamesAndClose(t *testing.T) {
+	b := openTestBroker(t, t.TempDir(), Config{})
+
+	if _, err := b.Publish(context.Background(), "../etc", nil, nil); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("expected ErrInvalidName, got %v", err)
+	}
+
+	sub, _ := b.Subscribe("orders", "g")
+	b.Close()
+
+	if _, err := sub.Receive(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+// faultyFile mensimulasikan disk yang bermasalah: write hanya menulis
+// separuh baris lalu gagal, atau fsync gagal.
+type faultyFile struct {
+	logFile
+	failWrite, failSync, failTruncate bool
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.failWrite {
+		n, _ := f.logFile.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return f.logFile.Write(p)
+}
+
+func (f *faultyFile) Sync() error {
+	if f.failSync {
+		return errors.New("fsync failed")
+	}
+	return f.logFile.Sync()
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return errors.New("truncate failed")
+	}
+	return f.logFile.Truncate(size)
+}
+
+// injectFaults membungkus file log topic dengan faultyFile.
+func injectFaults(t *testing.T, topic string) *faultyFile {
+	t.Helper()
+
+	fault := &faultyFile{}
+	orig := openLogFile
+	openLogFile = func(path string) (logFile, error) {
+		f, err := orig(path)
+		if err != nil || filepath.Base(path) != topic+".log" {
+			return f, err
+		}
+		fault.logFile = f
+		return fault, nil
+	}
+	t.Cleanup(func() { openLogFile = orig })
+
+	return fault
+}
+
+func TestFailedWriteIsRolledBack(t *testing.T) {
+	dir := t.TempDir()
+	fault := injectFaults(t, "orders")
+	ctx := context.Background()
+
+	b := openTestBroker(t, dir, Config{})
+	b.Publish(ctx, "orders", []byte("first"), nil)
+
+	fault.failWrite = true
+	if _, err := b.Publish(ctx, "orders", []byte("lost"), nil); err == nil {
+		t.Fatal("publish must report the failed write")
+	}
+	fault.failWrite = false
+
+	if offset, err := b.Publish(ctx, "orders", []byte("second"), nil); err != nil || offset != 1 {
+		t.Fatalf("publish after rollback: offset %d err %v", offset, err)
+	}
+	b.Close()
+
+	// file tidak berisi potongan baris, jadi reopen membaca semua record
+	b = openTestBroker(t, dir, Config{})
+	defer b.Close()
+	sub, err := b.Subscribe("orders", "reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"first", "second"} {
+		if msg := receive(t, sub); string(msg.Payload) != want {
+			t.Fatalf("got %q, want %q", msg.Payload, want)
+		}
+	}
+	expectEmpty(t, sub)
+}
+
+func TestUnrecoverableWriteBreaksLog(t *testing.T) {
+	cases := map[string]func(f *faultyFile){
+		"fsync":    func(f *faultyFile) { f.failSync = true },
+		"rollback": func(f *faultyFile) { f.failWrite, f.failTruncate = true, true },
+	}
+	for name, fail := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			fault := injectFaults(t, "orders")
+			ctx := context.Background()
+
+			b := openTestBroker(t, dir, Config{SyncWrites: true})
+			b.Publish(ctx, "orders", []byte("first"), nil)
+
+			fail(fault)
+			if _, err := b.Publish(ctx, "orders", []byte("unknown"), nil); err == nil {
+				t.Fatal("publish must fail")
+			}
+			*fault = faultyFile{logFile: fault.logFile}
+			if _, err := b.Publish(ctx, "orders", []byte("refused"), nil); !errors.Is(err, ErrLogBroken) {
+				t.Fatalf("expected ErrLogBroken, got %v", err)
+			}
+			b.Close()
+
+			// setelah reopen, offset tidak pernah dipakai dua kali
+			b = openTestBroker(t, dir, Config{})
+			defer b.Close()
+			offset, err := b.Publish(ctx, "orders", []byte("after"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sub, _ := b.Subscribe("orders", "reader")
+			var got []string
+			for i := int64(0); i <= offset; i++ {
+				msg := receive(t, sub)
+				if msg.Offset != i {
+					t.Fatalf("offset %d delivered as %d", i, msg.Offset)
+				}
+				got = append(got, string(msg.Payload))
+			}
+			if got[0] != "first" || got[len(got)-1] != "after" {
+				t.Fatalf("records after reopen: %v", got)
+			}
+		})
+	}
+}
+
+func TestDeliveryAttemptsSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	cfg := Config{MaxDeliveries: 2}
+
+	b := openTestBroker(t, dir, cfg)
+	b.Publish(ctx, "emails", []byte("poison"), nil)
+
+	// consumer crash dua kali sebelum sempat ack
+	for attempt := 1; attempt <= 2; attempt++ {
+		sub, _ := b.Subscribe("emails", "sender")
+		if msg := receive(t, sub); msg.Attempt != attempt {
+			t.Fatalf("attempt %d delivered as %d", attempt, msg.Attempt)
+		}
+		b.Close()
+		b = openTestBroker(t, dir, cfg)
+	}
+	defer b.Close()
+
+	sub, _ := b.Subscribe("emails", "sender")
+	dlq, _ := b.Subscribe(b.DeadLetterTopic("emails"), "ops")
+	expectEmpty(t, sub)
+	if dead := receive(t, dlq); string(dead.Payload) != "poison" || dead.Headers[HeaderDeliveryCount] != "2" {
+		t.Fatalf("unexpected dead letter %+v", dead)
+	}
+}
@@ -0,0 +1,136 @@
+package eventbus
+
+import "time"
+
+type delivery struct {
+	attempt  int
+	deadline time.Time
+}
+
+// group menyimpan progres satu consumer group di satu topic.
+// Semua method dipanggil dengan lock broker sudah dipegang.
+type group struct {
+	name  string
+	topic string
+	log   *topicLog
+	acks  *ackLog
+
+	// semua offset < low sudah di-ack, acked berisi offset >= low yang
+	// sudah di-ack tapi belum berurutan
+	low   int64
+	acked map[int64]bool
+
+	// cursor offset berikutnya yang belum pernah dikirim
+	cursor   int64
+	inflight map[int64]*delivery
+
+	// attempts berisi jumlah pengiriman sebelum restart untuk offset yang
+	// belum di-ack, supaya hitungan MaxDeliveries dilanjutkan
+	attempts map[int64]int
+}
+
+func newGroup(name, topic string, log *topicLog, acks *ackLog, state groupState) *group {
+	g := &group{
+		name:     name,
+		topic:    topic,
+		log:      log,
+		acks:     acks,
+		acked:    make(map[int64]bool),
+		inflight: make(map[int64]*delivery),
+		attempts: state.attempts,
+	}
+	for _, offset := range state.acked {
+		g.markAcked(offset)
+	}
+	g.cursor = g.low
+
+	return g
+}
+
+// next memilih offset yang harus dikirim: pertama delivery yang sudah
+// timeout, lalu message baru. Mengembalikan offset -1 kalau tidak ada.
+func (g *group) next(now time.Time, ackTimeout time.Duration) (offset int64, attempt int, wait time.Duration) {
+	expired := int64(-1)
+	var earliest time.Time
+
+	for off, d := range g.inflight {
+		if !d.deadline.After(now) {
+			if expired < 0 || off < expired {
+				expired = off
+			}
+			continue
+		}
+		if earliest.IsZero() || d.deadline.Before(earliest) {
+			earliest = d.deadline
+		}
+	}
+
+	if expired >= 0 {
+		d := g.inflight[expired]
+		d.attempt++
+		d.deadline = now.Add(ackTimeout)
+		return expired, d.attempt, 0
+	}
+
+	for g.cursor < g.log.nextOffset() {
+		off := g.cursor
+		g.cursor++
+
+		if off < g.low || g.acked[off] {
+			continue
+		}
+
+		attempt := g.attempts[off] + 1
+		delete(g.attempts, off)
+
+		g.inflight[off] = &delivery{attempt: attempt, deadline: now.Add(ackTimeout)}
+		return off, attempt, 0
+	}
+
+	if !earliest.IsZero() {
+		wait = earliest.Sub(now)
+	}
+
+	return -1, 0, wait
+}
+
+func (g *group) ack(offset int64) error {
+	if offset < g.low || g.acked[offset] {
+		return nil
+	}
+
+	if err := g.acks.append(offset); err != nil {
+		return err
+	}
+
+	delete(g.inflight, offset)
+	g.markAcked(offset)
+
+	return nil
+}
+
+// expire membuat delivery langsung dianggap timeout (dipakai oleh Nack).
+// Nack dari delivery lama yang sudah dikirim ulang diabaikan.
+func (g *group) expire(offset int64, attempt int, now time.Time) bool {
+	d, ok := g.inflight[offset]
+	if !ok || d.attempt != attempt {
+		return false
+	}
+
+	d.deadline = now
+
+	return true
+}
+
+func (g *group) markAcked(offset int64) {
+	if offset < g.low {
+		return
+	}
+
+	g.acked[offset] = true
+	delete(g.attempts, offset)
+	for g.acked[g.low] {
+		delete(g.acked, g.low)
+		g.low++
+	}
+}
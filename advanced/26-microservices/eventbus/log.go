@@ -0,0 +1,274 @@
+package eventbus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLogBroken dikembalikan setelah sebuah write gagal dan isi file tidak
+// bisa dipastikan lagi. Broker harus dibuka ulang supaya file dibaca dan
+// dipulihkan dari disk.
+var ErrLogBroken = errors.New("eventbus: log is broken after a failed write, reopen the broker")
+
+// record adalah bentuk message di file log, satu JSON per baris.
+type record struct {
+	Offset  int64             `json:"offset"`
+	ID      string            `json:"id"`
+	Time    time.Time         `json:"time"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload []byte            `json:"payload"`
+}
+
+// logFile adalah bagian dari *os.File yang dipakai log, dipisah supaya
+// kegagalan disk bisa disimulasikan di test.
+type logFile interface {
+	io.Writer
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+var openLogFile = func(path string) (logFile, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+}
+
+// appendFile menulis baris utuh ke akhir file. Kalau write gagal di tengah
+// jalan, file dipotong kembali ke ukuran sebelum write supaya tidak ada
+// baris setengah jadi yang menempel ke baris berikutnya.
+type appendFile struct {
+	file   logFile
+	size   int64
+	sync   bool
+	broken error
+}
+
+func (f *appendFile) appendLine(line []byte) error {
+	if f.broken != nil {
+		return f.broken
+	}
+
+	if _, err := f.file.Write(line); err != nil {
+		if rerr := f.rollback(); rerr != nil {
+			f.broken = fmt.Errorf("%w: %v", ErrLogBroken, errors.Join(err, rerr))
+		}
+		return err
+	}
+	if f.sync {
+		if err := f.file.Sync(); err != nil {
+			// setelah fsync gagal tidak ada yang tahu apakah baris ini sampai
+			// ke disk, jadi jangan menulis lagi. Kalau ternyata tersimpan,
+			// baris ini terbaca sebagai record biasa setelah reopen dan
+			// offset-nya tidak dipakai ulang.
+			f.broken = fmt.Errorf("%w: %v", ErrLogBroken, err)
+			return err
+		}
+	}
+	f.size += int64(len(line))
+
+	return nil
+}
+
+func (f *appendFile) rollback() error {
+	if err := f.file.Truncate(f.size); err != nil {
+		return err
+	}
+	_, err := f.file.Seek(f.size, io.SeekStart)
+
+	return err
+}
+
+func (f *appendFile) close() error {
+	return f.file.Close()
+}
+
+// openAppendFile membuka file dan membuang baris terakhir yang terpotong
+// (crash saat menulis). scan membaca isi file dan mengembalikan jumlah byte
+// yang valid.
+func openAppendFile(path string, sync bool, scan func(io.Reader) (int64, error)) (*appendFile, error) {
+	file, err := openLogFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("eventbus: open %s: %w", path, err)
+	}
+
+	validSize, err := scan(io.NewSectionReader(file, 0, 1<<63-1))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("eventbus: read %s: %w", path, err)
+	}
+
+	f := &appendFile{file: file, size: validSize, sync: sync}
+	if err := f.rollback(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// topicLog adalah append-only log untuk satu topic. Di memory hanya ada
+// posisi byte setiap record (8 byte per message); isi record dibaca dari
+// file saat akan dikirim, jadi topic besar tidak perlu muat di RAM.
+type topicLog struct {
+	*appendFile
+	index []int64
+}
+
+func openTopicLog(path string, sync bool) (*topicLog, error) {
+	l := &topicLog{}
+
+	f, err := openAppendFile(path, sync, func(r io.Reader) (int64, error) {
+		var err error
+		if l.index, err = scanRecords(r); err != nil {
+			return 0, err
+		}
+		return l.index[len(l.index)-1], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.appendFile = f
+
+	return l, nil
+}
+
+// scanRecords memvalidasi setiap baris dan mengembalikan posisi awal setiap
+// record ditambah satu posisi akhir (ukuran bagian file yang valid).
+func scanRecords(r io.Reader) ([]int64, error) {
+	index := []int64{0}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		end := index[len(index)-1]
+		var rec record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return nil, fmt.Errorf("corrupt record at byte %d: %w", end, err)
+		}
+		if rec.Offset != int64(len(index)-1) {
+			return nil, fmt.Errorf("unexpected offset %d at byte %d", rec.Offset, end)
+		}
+
+		index = append(index, end+int64(len(line)))
+	}
+}
+
+func (l *topicLog) nextOffset() int64 {
+	return int64(len(l.index) - 1)
+}
+
+func (l *topicLog) append(rec record) error {
+	rec.Offset = l.nextOffset()
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if err := l.appendLine(line); err != nil {
+		return err
+	}
+	l.index = append(l.index, l.size)
+
+	return nil
+}
+
+func (l *topicLog) read(offset int64) (record, error) {
+	start, end := l.index[offset], l.index[offset+1]
+
+	buf := make([]byte, end-start)
+	if _, err := l.file.ReadAt(buf, start); err != nil {
+		return record{}, fmt.Errorf("eventbus: read offset %d: %w", offset, err)
+	}
+
+	var rec record
+	if err := json.Unmarshal(buf, &rec); err != nil {
+		return record{}, fmt.Errorf("eventbus: decode offset %d: %w", offset, err)
+	}
+
+	return rec, nil
+}
+
+// ackLog menyimpan progres satu consumer group, satu entry per baris:
+// "<offset>" untuk ack dan "<offset> <attempt>" setiap kali message dikirim.
+// Attempt ikut disimpan supaya MaxDeliveries tetap berlaku walau broker
+// restart, misalnya karena consumer crash saat memproses poison message.
+type ackLog struct {
+	*appendFile
+}
+
+// groupState adalah isi ackLog saat dibuka: offset yang sudah di-ack dan
+// jumlah pengiriman terakhir untuk offset yang belum di-ack.
+type groupState struct {
+	acked    []int64
+	attempts map[int64]int
+}
+
+func openAckLog(path string, sync bool) (*ackLog, groupState, error) {
+	state := groupState{attempts: make(map[int64]int)}
+
+	f, err := openAppendFile(path, sync, func(r io.Reader) (int64, error) {
+		var validSize int64
+
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if errors.Is(err, io.EOF) {
+				// baris tanpa newline dianggap tidak pernah ditulis
+				return validSize, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+
+			fields := strings.Fields(string(line))
+			nums := make([]int64, len(fields))
+			for i, field := range fields {
+				if nums[i], err = strconv.ParseInt(field, 10, 64); err != nil {
+					break
+				}
+			}
+			switch {
+			case err != nil || len(nums) == 0 || len(nums) > 2:
+				return 0, fmt.Errorf("corrupt entry at byte %d: %q", validSize, bytes.TrimSpace(line))
+			case len(nums) == 1:
+				state.acked = append(state.acked, nums[0])
+				delete(state.attempts, nums[0])
+			default:
+				state.attempts[nums[0]] = int(nums[1])
+			}
+
+			validSize += int64(len(line))
+		}
+	})
+	if err != nil {
+		return nil, groupState{}, err
+	}
+
+	return &ackLog{f}, state, nil
+}
+
+func (l *ackLog) append(offset int64) error {
+	return l.appendLine([]byte(strconv.FormatInt(offset, 10) + "\n"))
+}
+
+func (l *ackLog) appendDelivery(offset int64, attempt int) error {
+	return l.appendLine([]byte(strconv.FormatInt(offset, 10) + " " + strconv.Itoa(attempt) + "\n"))
+}
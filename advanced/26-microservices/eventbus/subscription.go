@@ -0,0 +1,113 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type Message struct {
+	Topic   string
+	Group   string
+	Offset  int64
+	ID      string
+	Time    time.Time
+	Headers map[string]string
+	Payload []byte
+
+	// Attempt dimulai dari 1 dan naik setiap message dikirim ulang.
+	Attempt int
+
+	broker *Broker
+}
+
+// Ack menandai message selesai diproses oleh group ini. Ack disimpan ke
+// disk sehingga message tidak dikirim ulang setelah restart.
+func (m *Message) Ack() error {
+	return m.broker.ack(m)
+}
+
+// Nack meminta message dikirim ulang secepatnya tanpa menunggu AckTimeout.
+func (m *Message) Nack() error {
+	return m.broker.nack(m)
+}
+
+func (m *Message) DecodeJSON(v any) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+type Subscription struct {
+	broker *Broker
+	topic  string
+	group  string
+}
+
+func (s *Subscription) Topic() string { return s.topic }
+func (s *Subscription) Group() string { return s.group }
+
+// Receive menunggu message berikutnya untuk group ini sampai ctx selesai.
+func (s *Subscription) Receive(ctx context.Context) (*Message, error) {
+	b := s.broker
+
+	for {
+		b.mu.Lock()
+		g, err := b.lookupGroup(s.topic, s.group)
+		if err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+
+		msg, wait, err := b.nextLocked(g)
+		signal := b.signal
+		b.mu.Unlock()
+
+		if err != nil || msg != nil {
+			return msg, err
+		}
+
+		if err := waitFor(ctx, signal, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Consume memanggil handler untuk setiap message sampai ctx selesai.
+// Handler yang mengembalikan nil akan di-ack, selain itu di-nack.
+func (s *Subscription) Consume(ctx context.Context, handler func(context.Context, *Message) error) error {
+	for {
+		msg, err := s.Receive(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			if err := msg.Nack(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := msg.Ack(); err != nil {
+			return err
+		}
+	}
+}
+
+// waitFor menunggu signal, timeout wait (kalau > 0), atau ctx selesai.
+func waitFor(ctx context.Context, signal <-chan struct{}, wait time.Duration) error {
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-signal:
+	case <-timeout:
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config dibaca dari file JSON, contoh:
+//
+//	{
+//	  "routes": [
+//	    {"prefix": "/api/users", "service": "users", "strip_prefix": "/api", "auth": true},
+//	    {"prefix": "/public", "service": "web", "rewrite": "/static"}
+//	  ],
+//	  "services": {"users": ["http://127.0.0.1:8081"]},
+//	  "auth": {"secret": "rahasia", "issuer": "go-journey"},
+//	  "rate_limit": {"requests_per_second": 10, "burst": 20}
+//	}
+type Config struct {
+	Routes []Route `json:"routes"`
+
+	// Services adalah upstream statis yang didaftarkan ke registry saat start.
+	// Upstream lain bisa mendaftar sendiri lewat API registry.
+	Services map[string][]string `json:"services"`
+
+	Auth      AuthConfig      `json:"auth"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+
+	// HealthPath endpoint health di setiap upstream, default "/health".
+	HealthPath    string   `json:"health_path"`
+	HealthTimeout Duration `json:"health_timeout"`
+}
+
+type Route struct {
+	// Prefix dicocokkan per segmen path, jadi "/api/users" cocok dengan
+	// "/api/users/1" tapi tidak dengan "/api/usersx".
+	Prefix  string `json:"prefix"`
+	Service string `json:"service"`
+
+	// StripPrefix dibuang dari depan path sebelum diteruskan, lalu Rewrite
+	// ditambahkan di depannya. Contoh prefix "/api/users", strip "/api",
+	// rewrite "/v1": /api/users/1 -> /v1/users/1.
+	StripPrefix string `json:"strip_prefix"`
+	Rewrite     string `json:"rewrite"`
+
+	Auth bool `json:"auth"`
+}
+
+type AuthConfig struct {
+	Secret   string `json:"secret"`
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+}
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+}
+
+// Duration supaya file config bisa menulis "2s" alih-alih nanodetik.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: read config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("gateway: parse config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	seen := make(map[string]bool)
+	for i, r := range c.Routes {
+		if !strings.HasPrefix(r.Prefix, "/") {
+			errs = append(errs, fmt.Errorf("routes[%d]: prefix %q must start with /", i, r.Prefix))
+		}
+		if r.Service == "" {
+			errs = append(errs, fmt.Errorf("routes[%d]: service is required", i))
+		}
+		if r.StripPrefix != "" && !hasPathPrefix(r.Prefix, r.StripPrefix) {
+			errs = append(errs, fmt.Errorf("routes[%d]: strip_prefix %q is not a prefix of %q", i, r.StripPrefix, r.Prefix))
+		}
+		if r.Auth && c.Auth.Secret == "" {
+			errs = append(errs, fmt.Errorf("routes[%d]: auth enabled but auth.secret is empty", i))
+		}
+		if seen[r.Prefix] {
+			errs = append(errs, fmt.Errorf("routes[%d]: duplicate prefix %q", i, r.Prefix))
+		}
+		seen[r.Prefix] = true
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("gateway: invalid config: %w", err)
+	}
+
+	return nil
+}
+
+// sortedRoutes mengurutkan route dari prefix terpanjang supaya match pertama
+// adalah yang paling spesifik.
+func sortedRoutes(routes []Route) []Route {
+	out := append([]Route(nil), routes...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i].Prefix) > len(out[j].Prefix)
+	})
+
+	return out
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func (r Route) matches(path string) bool {
+	return hasPathPrefix(path, r.Prefix)
+}
+
+// upstreamPath menghitung path yang dikirim ke upstream.
+func (r Route) upstreamPath(path string) string {
+	if r.StripPrefix != "" {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(r.StripPrefix, "/"))
+	}
+	if r.Rewrite != "" {
+		path = strings.TrimSuffix(r.Rewrite, "/") + path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return path
+}
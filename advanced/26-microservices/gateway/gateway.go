@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-journey/advanced/26-microservices/registry"
+)
+
+const (
+	// HealthEndpoint di listener publik hanya mengembalikan status agregat.
+	// Laporan per instance tersedia lewat HealthHandler.
+	HealthEndpoint = "/_gateway/health"
+
+	// SubjectHeader dikirim ke upstream berisi subject dari JWT. Header yang
+	// sama dari client selalu dibuang supaya tidak bisa dipalsukan.
+	SubjectHeader = "X-Authenticated-Subject"
+)
+
+type Option func(*Gateway)
+
+// WithTransport mengganti transport ke upstream, misalnya untuk
+// membungkusnya dengan resilience atau tracing.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(g *Gateway) { g.transport = rt }
+}
+
+func WithLogger(logger *log.Logger) Option {
+	return func(g *Gateway) { g.logger = logger }
+}
+
+type Gateway struct {
+	routes   []Route
+	registry *registry.Registry
+	auth     AuthConfig
+	limiter  *rateLimiter
+
+	transport http.RoundTripper
+	proxy     *httputil.ReverseProxy
+	logger    *log.Logger
+
+	healthPath    string
+	healthTimeout time.Duration
+
+	healthMu     sync.Mutex
+	healthAt     time.Time
+	healthStatus string
+
+	now func() time.Time
+}
+
+type upstreamTarget struct {
+	url  *url.URL
+	path string
+}
+
+type targetKey struct{}
+
+// New membuat gateway dan mendaftarkan upstream statis dari cfg.Services
+// ke registry.
+func New(cfg *Config, reg *registry.Registry, opts ...Option) (*Gateway, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	for service, addrs := range cfg.Services {
+		for i, addr := range addrs {
+			inst := registry.Instance{
+				ID:      fmt.Sprintf("%s-static-%d", service, i+1),
+				Service: service,
+				Addr:    addr,
+				Static:  true,
+			}
+			if err := reg.Register(inst); err != nil {
+				return nil, fmt.Errorf("gateway: register %s: %w", service, err)
+			}
+		}
+	}
+
+	g := &Gateway{
+		routes:        sortedRoutes(cfg.Routes),
+		registry:      reg,
+		auth:          cfg.Auth,
+		limiter:       newRateLimiter(cfg.RateLimit),
+		transport:     http.DefaultTransport,
+		logger:        log.Default(),
+		healthPath:    cfg.HealthPath,
+		healthTimeout: time.Duration(cfg.HealthTimeout),
+		now:           time.Now,
+	}
+	if g.healthPath == "" {
+		g.healthPath = "/health"
+	}
+	if g.healthTimeout <= 0 {
+		g.healthTimeout = 2 * time.Second
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	g.proxy = &httputil.ReverseProxy{
+		Rewrite:      g.rewrite,
+		Transport:    g.transport,
+		ErrorHandler: g.proxyError,
+	}
+
+	return g, nil
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// path dibersihkan dulu supaya "/public/../api/users" tidak lolos
+	// sebagai route /public lalu diteruskan apa adanya ke upstream
+	reqPath := cleanPath(r.URL.Path)
+
+	if reqPath == HealthEndpoint {
+		g.servePublicHealth(w, r)
+		return
+	}
+
+	route, ok := g.match(reqPath)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no route for "+reqPath)
+		return
+	}
+
+	// limit per IP dicek sebelum autentikasi supaya request dengan token
+	// salah ikut terhitung dan token tidak bisa di-brute force gratis
+	if !g.allow(w, "ip:"+clientIP(r)) {
+		return
+	}
+	if route.Auth {
+		claims, err := g.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gateway"`)
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !g.allow(w, "sub:"+claims.Subject) {
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims))
+	}
+
+	inst, err := g.registry.Resolve(route.Service)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	target, err := url.Parse(inst.Addr)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "invalid upstream address")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), targetKey{}, &upstreamTarget{
+		url:  target,
+		path: route.upstreamPath(reqPath),
+	})
+	g.proxy.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// allow menulis 429 dan mengembalikan false kalau bucket key sudah habis.
+func (g *Gateway) allow(w http.ResponseWriter, key string) bool {
+	if g.limiter == nil {
+		return true
+	}
+	ok, retryAfter := g.limiter.allow(key)
+	if !ok {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+	}
+
+	return ok
+}
+
+func (g *Gateway) match(path string) (Route, bool) {
+	for _, route := range g.routes {
+		if route.matches(path) {
+			return route, true
+		}
+	}
+
+	return Route{}, false
+}
+
+func (g *Gateway) authenticate(r *http.Request) (*Claims, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyHS256(token, g.auth, g.now())
+}
+
+func (g *Gateway) rewrite(pr *httputil.ProxyRequest) {
+	target := pr.In.Context().Value(targetKey{}).(*upstreamTarget)
+
+	pr.Out.URL.Scheme = target.url.Scheme
+	pr.Out.URL.Host = target.url.Host
+	pr.Out.URL.Path = strings.TrimSuffix(target.url.Path, "/") + target.path
+	pr.Out.URL.RawPath = ""
+	pr.Out.Host = ""
+	pr.SetXForwarded()
+
+	pr.Out.Header.Del(SubjectHeader)
+	if claims, ok := ClaimsFromContext(pr.In.Context()); ok {
+		pr.Out.Header.Set(SubjectHeader, claims.Subject)
+	}
+}
+
+func (g *Gateway) proxyError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	g.logger.Printf("gateway: upstream error for %s %s: %v", r.Method, r.URL.Path, err)
+	writeError(w, http.StatusBadGateway, "upstream unavailable")
+}
+
+// cleanPath seperti path.Clean tapi mempertahankan slash di akhir, karena
+// sebagian upstream membedakan "/users" dan "/users/".
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+
+	return cleaned
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
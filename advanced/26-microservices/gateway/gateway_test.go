@@ -0,0 +1,393 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-journey/advanced/26-microservices/registry"
+)
+
+const testSecret = "rahasia"
+
+func signHS256(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payloadJSON, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(payloadJSON)
+
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(header + "." + payload))
+
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// echoUpstream mengembalikan path dan header yang diterima upstream.
+func echoUpstream(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"upstream": name,
+			"path":     r.URL.Path,
+			"query":    r.URL.RawQuery,
+			"subject":  r.Header.Get(SubjectHeader),
+			"xff":      r.Header.Get("X-Forwarded-For"),
+		})
+	}))
+}
+
+func newTestGateway(t *testing.T, cfg *Config, opts ...Option) (*Gateway, *registry.Registry) {
+	t.Helper()
+
+	reg := registry.New(time.Minute)
+	gw, err := New(cfg, reg, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gw, reg
+}
+
+func do(gw http.Handler, method, path string, header http.Header) (*httptest.ResponseRecorder, map[string]string) {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	gw.ServeHTTP(rec, req)
+
+	var body map[string]string
+	json.Unmarshal(rec.Body.Bytes(), &body)
+
+	return rec, body
+}
+
+func TestRoutingAndPathRewrite(t *testing.T) {
+	users, orders := echoUpstream("users"), echoUpstream("orders")
+	defer users.Close()
+	defer orders.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes: []Route{
+			{Prefix: "/api", Service: "orders", Rewrite: "/legacy"},
+			{Prefix: "/api/users", Service: "users", StripPrefix: "/api", Rewrite: "/v1"},
+		},
+		Services: map[string][]string{"users": {users.URL}, "orders": {orders.URL}},
+	})
+
+	rec, body := do(gw, http.MethodGet, "/api/users/42?expand=roles", nil)
+	if rec.Code != http.StatusOK || body["upstream"] != "users" || body["path"] != "/v1/users/42" || body["query"] != "expand=roles" {
+		t.Fatalf("longest prefix route: %d %v", rec.Code, body)
+	}
+	if body["xff"] == "" {
+		t.Error("X-Forwarded-For must be set")
+	}
+
+	_, body = do(gw, http.MethodGet, "/api/usersx", nil)
+	if body["upstream"] != "orders" || body["path"] != "/legacy/api/usersx" {
+		t.Fatalf("prefix must match on segment boundary: %v", body)
+	}
+
+	if rec, _ := do(gw, http.MethodGet, "/other", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestResolvesThroughRegistry(t *testing.T) {
+	a, b := echoUpstream("a"), echoUpstream("b")
+	defer a.Close()
+	defer b.Close()
+
+	gw, reg := newTestGateway(t, &Config{Routes: []Route{{Prefix: "/orders", Service: "orders"}}})
+
+	if rec, _ := do(gw, http.MethodGet, "/orders", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without instances, got %d", rec.Code)
+	}
+
+	reg.Register(registry.Instance{ID: "1", Service: "orders", Addr: a.URL})
+	reg.Register(registry.Instance{ID: "2", Service: "orders", Addr: b.URL})
+
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		_, body := do(gw, http.MethodGet, "/orders", nil)
+		seen[body["upstream"]] = true
+	}
+	if !seen["a"] || !seen["b"] {
+		t.Fatalf("expected round robin over both instances, saw %v", seen)
+	}
+}
+
+func TestJWTAuth(t *testing.T) {
+	users := echoUpstream("users")
+	defer users.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes:   []Route{{Prefix: "/users", Service: "users", Auth: true}},
+		Services: map[string][]string{"users": {users.URL}},
+		Auth:     AuthConfig{Secret: testSecret, Issuer: "go-journey", Audience: "gateway"},
+	})
+
+	now := time.Now().Unix()
+	valid := signHS256(t, map[string]any{"sub": "bisma", "iss": "go-journey", "aud": []string{"gateway"}, "exp": now + 60})
+
+	cases := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"valid", "Bearer " + valid, http.StatusOK},
+		{"expired", "Bearer " + signHS256(t, map[string]any{"sub": "x", "iss": "go-journey", "aud": "gateway", "exp": now - 1}), http.StatusUnauthorized},
+		{"wrong issuer", "Bearer " + signHS256(t, map[string]any{"sub": "x", "iss": "other", "aud": "gateway"}), http.StatusUnauthorized},
+		{"not yet valid", "Bearer " + signHS256(t, map[string]any{"sub": "x", "iss": "go-journey", "aud": "gateway", "nbf": now + 60}), http.StatusUnauthorized},
+		{"tampered", "Bearer " + valid[:len(valid)-2] + "xx", http.StatusUnauthorized},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			header := http.Header{SubjectHeader: {"spoofed"}}
+			if c.header != "" {
+				header.Set("Authorization", c.header)
+			}
+
+			rec, body := do(gw, http.MethodGet, "/users/me", header)
+			if rec.Code != c.code {
+				t.Fatalf("expected %d, got %d %v", c.code, rec.Code, body)
+			}
+			if c.code == http.StatusOK && body["subject"] != "bisma" {
+				t.Fatalf("upstream must receive subject from token, got %q", body["subject"])
+			}
+		})
+	}
+}
+
+func TestRateLimitPerClient(t *testing.T) {
+	users := echoUpstream("users")
+	defer users.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes:    []Route{{Prefix: "/", Service: "users"}},
+		Services:  map[string][]string{"users": {users.URL}},
+		RateLimit: RateLimitConfig{RequestsPerSecond: 1, Burst: 2},
+	})
+
+	clock := time.Unix(1000, 0)
+	gw.limiter.now = func() time.Time { return clock }
+
+	from := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if from("10.0.0.1") != 200 || from("10.0.0.1") != 200 {
+		t.Fatal("burst requests must pass")
+	}
+	if code := from("10.0.0.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", code)
+	}
+	if from("10.0.0.2") != 200 {
+		t.Fatal("other clients have their own bucket")
+	}
+
+	clock = clock.Add(time.Second)
+	if from("10.0.0.1") != 200 {
+		t.Fatal("token must refill after one second")
+	}
+}
+
+func TestRateLimitBeforeAuth(t *testing.T) {
+	users := echoUpstream("users")
+	defer users.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes:    []Route{{Prefix: "/users", Service: "users", Auth: true}},
+		Services:  map[string][]string{"users": {users.URL}},
+		Auth:      AuthConfig{Secret: testSecret},
+		RateLimit: RateLimitConfig{RequestsPerSecond: 1, Burst: 3},
+	})
+
+	clock := time.Unix(1000, 0)
+	gw.limiter.now = func() time.Time { return clock }
+
+	send := func(ip, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// tebakan token yang salah tetap menghabiskan bucket IP
+	for i := 0; i < 3; i++ {
+		if code := send("10.0.0.1", "guess"); code != http.StatusUnauthorized {
+			t.Fatalf("guess %d: %d", i, code)
+		}
+	}
+	if code := send("10.0.0.1", "guess"); code != http.StatusTooManyRequests {
+		t.Fatalf("failed auth must be rate limited, got %d", code)
+	}
+
+	// setelah lolos autentikasi, subject punya bucket sendiri lintas IP
+	valid := signHS256(t, map[string]any{"sub": "bisma"})
+	for i, ip := range []string{"10.0.1.1", "10.0.1.2", "10.0.1.3"} {
+		if code := send(ip, valid); code != http.StatusOK {
+			t.Fatalf("request %d: %d", i, code)
+		}
+	}
+	if code := send("10.0.1.4", valid); code != http.StatusTooManyRequests {
+		t.Fatalf("subject bucket must be shared across IPs, got %d", code)
+	}
+}
+
+func TestAggregatedHealth(t *testing.T) {
+	up := echoUpstream("users")
+	defer up.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes: []Route{
+			{Prefix: "/users", Service: "users"},
+			{Prefix: "/orders", Service: "orders"},
+		},
+		Services: map[string][]string{"users": {up.URL, down.URL}, "orders": {down.URL}},
+	})
+
+	rec := httptest.NewRecorder()
+	gw.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var report HealthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusServiceUnavailable || report.Status != statusDegraded {
+		t.Fatalf("expected degraded, got %d %+v", rec.Code, report)
+	}
+	if report.Services["users"].Status != statusUp || report.Services["orders"].Status != statusDown {
+		t.Fatalf("unexpected service status %+v", report.Services)
+	}
+	if len(report.Services["users"].Instances) != 2 {
+		t.Fatalf("expected both users instances, got %+v", report.Services["users"])
+	}
+}
+
+func TestPublicHealthIsAggregateAndCached(t *testing.T) {
+	var checks atomic.Int32
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer up.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes:   []Route{{Prefix: "/users", Service: "users"}},
+		Services: map[string][]string{"users": {up.URL}},
+	})
+	now := time.Now()
+	gw.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		rec, _ := do(gw, http.MethodGet, HealthEndpoint, nil)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", rec.Code)
+		}
+		if body := rec.Body.String(); strings.Contains(body, up.URL) || strings.Contains(body, "error") {
+			t.Fatalf("public health must not leak upstream details: %s", body)
+		}
+	}
+	if n := checks.Load(); n != 1 {
+		t.Fatalf("expected one cached health check, got %d", n)
+	}
+
+	now = now.Add(publicHealthTTL)
+	do(gw, http.MethodGet, HealthEndpoint, nil)
+	if n := checks.Load(); n != 2 {
+		t.Fatalf("expected a fresh check after the TTL, got %d", n)
+	}
+}
+
+func TestPathIsCleanedBeforeMatching(t *testing.T) {
+	users := echoUpstream("users")
+	defer users.Close()
+	web := echoUpstream("web")
+	defer web.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes: []Route{
+			{Prefix: "/api/users", Service: "users", Auth: true},
+			{Prefix: "/public", Service: "web"},
+		},
+		Services: map[string][]string{"users": {users.URL}, "web": {web.URL}},
+		Auth:     AuthConfig{Secret: testSecret},
+	})
+
+	if rec, _ := do(gw, http.MethodGet, "/public/../api/users/1", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("dot segments must not bypass auth, got %d", rec.Code)
+	}
+
+	rec, body := do(gw, http.MethodGet, "/public//a/./b/", nil)
+	if rec.Code != http.StatusOK || body["path"] != "/public/a/b/" {
+		t.Fatalf("unexpected upstream path %d %v", rec.Code, body)
+	}
+}
+
+func TestUpstreamDownReturnsBadGateway(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	gw, _ := newTestGateway(t, &Config{
+		Routes:   []Route{{Prefix: "/", Service: "dead"}},
+		Services: map[string][]string{"dead": {dead.URL}},
+	}, WithLogger(log.New(io.Discard, "", 0)))
+
+	if rec, _ := do(gw, http.MethodGet, "/", nil); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", rec.Code)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("..", "cmd", "gateway", "routes.json"))
+	if err != nil {
+		t.Fatalf("example routes file must be valid: %v", err)
+	}
+	if len(cfg.Routes) == 0 || time.Duration(cfg.HealthTimeout) != 2*time.Second {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	path := filepath.Join(t.TempDir(), "routes.json")
+	os.WriteFile(path, []byte(`{"routes":[{"prefix":"users","service":""},{"prefix":"/a","service":"a","auth":true}]}`), 0644)
+
+	_, err = LoadConfig(path)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"must start with /", "service is required", "auth.secret is empty"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q must mention %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go-journey/advanced/26-microservices/registry"
+)
+
+type InstanceHealth struct {
+	ID      string `json:"id"`
+	Addr    string `json:"addr"`
+	Status  string `json:"status"`
+	Latency string `json:"latency,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ServiceHealth struct {
+	Status    string           `json:"status"`
+	Instances []InstanceHealth `json:"instances"`
+}
+
+type HealthReport struct {
+	Status   string                   `json:"status"`
+	Services map[string]ServiceHealth `json:"services"`
+}
+
+const (
+	statusUp       = "up"
+	statusDown     = "down"
+	statusDegraded = "degraded"
+)
+
+// Health mengecek semua instance dari setiap service yang dipakai route
+// maupun yang terdaftar di registry. Sebuah service dianggap up kalau minimal
+// satu instance sehat.
+func (g *Gateway) Health(ctx context.Context) HealthReport {
+	services := make(map[string]bool)
+	for _, route := range g.routes {
+		services[route.Service] = true
+	}
+	for _, name := range g.registry.Services() {
+		services[name] = true
+	}
+
+	results := make(map[string][]InstanceHealth, len(services))
+
+	var wg sync.WaitGroup
+	for name := range services {
+		instances := g.registry.Instances(name)
+		checks := make([]InstanceHealth, len(instances))
+		results[name] = checks
+
+		for i, inst := range instances {
+			wg.Add(1)
+			go func(i int, inst registry.Instance) {
+				defer wg.Done()
+				checks[i] = g.checkInstance(ctx, inst)
+			}(i, inst)
+		}
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: statusUp, Services: make(map[string]ServiceHealth, len(results))}
+	for name, checks := range results {
+		status := serviceStatus(checks)
+		if status != statusUp {
+			report.Status = statusDegraded
+		}
+		report.Services[name] = ServiceHealth{Status: status, Instances: checks}
+	}
+
+	return report
+}
+
+func (g *Gateway) checkInstance(ctx context.Context, inst registry.Instance) InstanceHealth {
+	result := InstanceHealth{ID: inst.ID, Addr: inst.Addr, Status: statusDown}
+
+	ctx, cancel := context.WithTimeout(ctx, g.healthTimeout)
+	defer cancel()
+
+	url := strings.TrimSuffix(inst.Addr, "/") + g.healthPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	resp, err := g.transport.RoundTrip(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	resp.Body.Close()
+
+	result.Latency = time.Since(start).Round(time.Millisecond).String()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		result.Status = statusUp
+	} else {
+		result.Error = fmt.Sprintf("health check returned %d", resp.StatusCode)
+	}
+
+	return result
+}
+
+func serviceStatus(instances []InstanceHealth) string {
+	for _, inst := range instances {
+		if inst.Status == statusUp {
+			return statusUp
+		}
+	}
+
+	return statusDown
+}
+
+// publicHealthTTL adalah berapa lama status agregat endpoint publik
+// di-cache, supaya request tanpa autentikasi tidak bisa memicu health check
+// ke semua upstream di setiap hit.
+const publicHealthTTL = 5 * time.Second
+
+// HealthHandler menyajikan laporan lengkap per instance, termasuk alamat
+// upstream dan pesan error. Pasang hanya di listener admin.
+func (g *Gateway) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := g.Health(r.Context())
+		writeHealth(w, report.Status, report)
+	})
+}
+
+// servePublicHealth hanya mengembalikan status agregat yang di-cache selama
+// publicHealthTTL, tanpa alamat maupun error dari upstream.
+func (g *Gateway) servePublicHealth(w http.ResponseWriter, r *http.Request) {
+	g.healthMu.Lock()
+	if g.healthStatus == "" || g.now().Sub(g.healthAt) >= publicHealthTTL {
+		// context request tidak dipakai langsung: client yang memutus
+		// koneksi tidak boleh membuat status "down" ikut ter-cache
+		g.healthStatus = g.Health(context.WithoutCancel(r.Context())).Status
+		g.healthAt = g.now()
+	}
+	status := g.healthStatus
+	g.healthMu.Unlock()
+
+	writeHealth(w, status, map[string]string{"status": status})
+}
+
+func writeHealth(w http.ResponseWriter, status string, body any) {
+	code := http.StatusOK
+	if status != statusUp {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter token bucket per client. Setiap client punya burst token dan
+// token bertambah sebanyak rate per detik.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	calls   int
+
+	now func() time.Time
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	if cfg.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(cfg.Burst)
+	if burst < 1 {
+		burst = math.Max(1, cfg.RequestsPerSecond)
+	}
+
+	return &rateLimiter{
+		rate:    cfg.RequestsPerSecond,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow mengambil satu token. Kalau habis, retryAfter berisi waktu tunggu
+// sampai token berikutnya tersedia.
+func (l *rateLimiter) allow(key string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.calls++
+	if l.calls%1024 == 0 {
+		l.pruneLocked(now)
+	}
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / l.rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// pruneLocked membuang bucket yang sudah penuh lagi, karena hasilnya sama
+// saja dengan client baru.
+func (l *rateLimiter) pruneLocked(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
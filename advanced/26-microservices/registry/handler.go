@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// OwnerHeader berisi kunci rahasia yang dipilih service saat register dan
+// wajib dikirim lagi untuk heartbeat, register ulang dan deregister.
+const OwnerHeader = "X-Registry-Owner"
+
+// Handler menyediakan HTTP API supaya service bisa mendaftarkan diri:
+//
+//	GET    /                         daftar semua instance yang hidup
+//	PUT    /{service}/{id}           register, body {"addr": "http://..."}
+//	POST   /{service}/{id}/heartbeat perpanjang TTL
+//	DELETE /{service}/{id}           deregister
+//
+// Kalau adminToken tidak kosong, setiap request harus membawa
+// "Authorization: Bearer <adminToken>". Siapa pun yang bisa register bisa
+// mengarahkan traffic gateway ke host-nya, jadi handler ini sebaiknya hanya
+// dipasang di listener admin, bukan di listener publik gateway.
+func (r *Registry) Handler(adminToken string) http.Handler {
+	return RequireToken(adminToken, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		if parts[0] == "" {
+			parts = nil
+		}
+		owner := req.Header.Get(OwnerHeader)
+		if len(parts) > 0 && owner == "" {
+			http.Error(w, "registry: missing "+OwnerHeader+" header", http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case len(parts) == 0 && req.Method == http.MethodGet:
+			all := make(map[string][]Instance)
+			for _, service := range r.Services() {
+				all[service] = r.Instances(service)
+			}
+			writeJSON(w, http.StatusOK, all)
+
+		case len(parts) == 2 && req.Method == http.MethodPut:
+			var body struct {
+				Addr     string            `json:"addr"`
+				Metadata map[string]string `json:"metadata"`
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				http.Error(w, "invalid json body", http.StatusBadRequest)
+				return
+			}
+
+			inst := Instance{Service: parts[0], ID: parts[1], Addr: body.Addr, Metadata: body.Metadata, Owner: owner}
+			writeResult(w, r.Register(inst))
+
+		case len(parts) == 3 && parts[2] == "heartbeat" && req.Method == http.MethodPost:
+			writeResult(w, r.Heartbeat(parts[0], parts[1], owner))
+
+		case len(parts) == 2 && req.Method == http.MethodDelete:
+			writeResult(w, r.Deregister(parts[0], parts[1], owner))
+
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+}
+
+// RequireToken mewajibkan "Authorization: Bearer <adminToken>" sebelum
+// memanggil next. Token kosong berarti tanpa autentikasi.
+func RequireToken(adminToken string, next http.Handler) http.Handler {
+	if adminToken == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="registry"`)
+			http.Error(w, "registry: unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func writeResult(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrConflict):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,212 @@
+package registry
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoInstances = errors.New("registry: no healthy instance")
+	ErrNotFound    = errors.New("registry: instance not found")
+	ErrConflict    = errors.New("registry: instance owned by someone else")
+)
+
+type Instance struct {
+	ID       string            `json:"id"`
+	Service  string            `json:"service"`
+	Addr     string            `json:"addr"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Static instance tidak pernah expired, dipakai untuk upstream yang
+	// didaftarkan dari file konfigurasi.
+	Static bool `json:"static"`
+
+	// Owner adalah kunci rahasia pendaftar. Instance yang sudah ada hanya
+	// bisa diganti, di-heartbeat atau dihapus oleh owner yang sama.
+	Owner string `json:"-"`
+
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+}
+
+// Registry menyimpan instance per service. Instance yang tidak mengirim
+// heartbeat lebih lama dari TTL dianggap mati dan tidak di-resolve.
+type Registry struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	services map[string]map[string]*Instance
+	next     map[string]int
+
+	now func() time.Time
+}
+
+func New(ttl time.Duration) *Registry {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+
+	return &Registry{
+		ttl:      ttl,
+		services: make(map[string]map[string]*Instance),
+		next:     make(map[string]int),
+		now:      time.Now,
+	}
+}
+
+func (r *Registry) Register(inst Instance) error {
+	if inst.Service == "" || inst.ID == "" {
+		return errors.New("registry: service and id are required")
+	}
+	if u, err := url.Parse(inst.Addr); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("registry: invalid addr %q", inst.Addr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// instance yang sudah expired dianggap tidak ada, jadi service yang
+	// restart dengan owner baru tetap bisa memakai id lamanya
+	r.pruneLocked()
+
+	instances, ok := r.services[inst.Service]
+	if !ok {
+		instances = make(map[string]*Instance)
+		r.services[inst.Service] = instances
+	}
+	if existing, ok := instances[inst.ID]; ok {
+		if err := existing.checkOwner(inst.Owner); err != nil {
+			return err
+		}
+	}
+
+	inst.LastHeartbeat = r.now()
+	instances[inst.ID] = &inst
+
+	return nil
+}
+
+// Deregister menghapus instance dinamis milik owner. Instance statis dari
+// konfigurasi tidak bisa dihapus lewat sini.
+func (r *Registry) Deregister(service, id, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances := r.services[service]
+	inst, ok := instances[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := inst.checkOwner(owner); err != nil {
+		return err
+	}
+	delete(instances, id)
+	if len(instances) == 0 {
+		delete(r.services, service)
+	}
+
+	return nil
+}
+
+func (r *Registry) Heartbeat(service, id, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inst, ok := r.services[service][id]
+	if !ok || r.expiredLocked(inst) {
+		return ErrNotFound
+	}
+	if err := inst.checkOwner(owner); err != nil {
+		return err
+	}
+	inst.LastHeartbeat = r.now()
+
+	return nil
+}
+
+func (inst *Instance) checkOwner(owner string) error {
+	if inst.Static {
+		return fmt.Errorf("%w: %s is statically configured", ErrConflict, inst.ID)
+	}
+	if subtle.ConstantTimeCompare([]byte(inst.Owner), []byte(owner)) != 1 {
+		return fmt.Errorf("%w: %s", ErrConflict, inst.ID)
+	}
+
+	return nil
+}
+
+// Instances mengembalikan instance yang masih hidup, urut berdasarkan id.
+func (r *Registry) Instances(service string) []Instance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.aliveLocked(service)
+}
+
+// Services mengembalikan nama service yang masih punya instance hidup.
+func (r *Registry) Services() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+
+	names := make([]string, 0, len(r.services))
+	for name := range r.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolve memilih satu instance dengan round-robin.
+func (r *Registry) Resolve(service string) (Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alive := r.aliveLocked(service)
+	if len(alive) == 0 {
+		return Instance{}, fmt.Errorf("%w for service %q", ErrNoInstances, service)
+	}
+
+	i := r.next[service] % len(alive)
+	r.next[service] = i + 1
+
+	return alive[i], nil
+}
+
+func (r *Registry) aliveLocked(service string) []Instance {
+	alive := make([]Instance, 0, len(r.services[service]))
+	for _, inst := range r.services[service] {
+		if !r.expiredLocked(inst) {
+			alive = append(alive, *inst)
+		}
+	}
+	sort.Slice(alive, func(i, j int) bool { return alive[i].ID < alive[j].ID })
+
+	return alive
+}
+
+func (r *Registry) expiredLocked(inst *Instance) bool {
+	return !inst.Static && r.now().Sub(inst.LastHeartbeat) > r.ttl
+}
+
+// pruneLocked menghapus instance dinamis yang sudah expired beserta service
+// yang tidak punya instance lagi, supaya map tidak tumbuh terus.
+func (r *Registry) pruneLocked() {
+	for service, instances := range r.services {
+		for id, inst := range instances {
+			if r.expiredLocked(inst) {
+				delete(instances, id)
+			}
+		}
+		if len(instances) == 0 {
+			delete(r.services, service)
+			delete(r.next, service)
+		}
+	}
+}
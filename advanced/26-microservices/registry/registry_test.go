@@ -0,0 +1,178 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveRoundRobinAndTTL(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	reg := New(10 * time.Second)
+	reg.now = func() time.Time { return clock }
+
+	reg.Register(Instance{ID: "a", Service: "users", Addr: "http://10.0.0.1:8080"})
+	reg.Register(Instance{ID: "b", Service: "users", Addr: "http://10.0.0.2:8080"})
+	reg.Register(Instance{ID: "static", Service: "web", Addr: "http://10.0.0.3:8080", Static: true})
+
+	first, _ := reg.Resolve("users")
+	second, _ := reg.Resolve("users")
+	if first.ID == second.ID {
+		t.Fatalf("expected round robin, got %s twice", first.ID)
+	}
+
+	clock = clock.Add(6 * time.Second)
+	reg.Heartbeat("users", "b", "")
+	clock = clock.Add(6 * time.Second)
+
+	alive := reg.Instances("users")
+	if len(alive) != 1 || alive[0].ID != "b" {
+		t.Fatalf("instance a must expire, got %+v", alive)
+	}
+	if _, err := reg.Resolve("web"); err != nil {
+		t.Fatal("static instance never expires")
+	}
+	if _, err := reg.Resolve("payments"); !errors.Is(err, ErrNoInstances) {
+		t.Fatalf("expected ErrNoInstances, got %v", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	reg := New(time.Minute)
+
+	if err := reg.Register(Instance{ID: "a", Service: "users", Addr: "10.0.0.1:8080"}); err == nil {
+		t.Fatal("addr without scheme must be rejected")
+	}
+	if err := reg.Deregister("users", "a", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRegisterRespectsOwnerAndStatic(t *testing.T) {
+	reg := New(time.Minute)
+	reg.Register(Instance{ID: "users-static-1", Service: "users", Addr: "http://10.0.0.1:8080", Static: true})
+	reg.Register(Instance{ID: "u1", Service: "users", Addr: "http://10.0.0.2:8080", Owner: "secret"})
+
+	hijack := []Instance{
+		{ID: "users-static-1", Service: "users", Addr: "http://evil.example"},
+		{ID: "users-static-1", Service: "users", Addr: "http://evil.example", Static: true},
+		{ID: "u1", Service: "users", Addr: "http://evil.example", Owner: "guess"},
+		{ID: "u1", Service: "users", Addr: "http://evil.example"},
+	}
+	for _, inst := range hijack {
+		if err := reg.Register(inst); !errors.Is(err, ErrConflict) {
+			t.Fatalf("register %+v: expected ErrConflict, got %v", inst, err)
+		}
+	}
+	if err := reg.Deregister("users", "users-static-1", ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("static instance must not be deregistered, got %v", err)
+	}
+	if err := reg.Heartbeat("users", "u1", "guess"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("heartbeat with wrong owner: %v", err)
+	}
+
+	// owner yang sama boleh register ulang, misalnya setelah restart
+	if err := reg.Register(Instance{ID: "u1", Service: "users", Addr: "http://10.0.0.9:8080", Owner: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, inst := range reg.Instances("users") {
+		if strings.Contains(inst.Addr, "evil") {
+			t.Fatalf("hijacked instance %+v", inst)
+		}
+	}
+}
+
+func TestExpiredInstancesArePruned(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	reg := New(10 * time.Second)
+	reg.now = func() time.Time { return clock }
+
+	reg.Register(Instance{ID: "u1", Service: "users", Addr: "http://10.0.0.1:8080", Owner: "old"})
+	reg.Register(Instance{ID: "o1", Service: "orders", Addr: "http://10.0.0.2:8080", Owner: "old"})
+	clock = clock.Add(11 * time.Second)
+
+	if err := reg.Heartbeat("users", "u1", "old"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("heartbeat after expiry must report not found, got %v", err)
+	}
+
+	// service restart dengan owner token baru
+	if err := reg.Register(Instance{ID: "u1", Service: "users", Addr: "http://10.0.0.9:8080", Owner: "new"}); err != nil {
+		t.Fatalf("re-register after TTL with a new owner: %v", err)
+	}
+	if got := reg.Services(); len(got) != 1 || got[0] != "users" {
+		t.Fatalf("expired service must be dropped, got %v", got)
+	}
+	if _, ok := reg.services["orders"]; ok {
+		t.Fatal("expired service must be removed from the map")
+	}
+	if err := reg.Heartbeat("users", "u1", "old"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("old owner must not control the new instance, got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := New(time.Minute)
+	h := reg.Handler("admin-token")
+
+	send := func(method, path, owner, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-token")
+		if owner != "" {
+			req.Header.Set(OwnerHeader, owner)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send(http.MethodPut, "/users/u1", "", `{"addr":"http://127.0.0.1:9000"}`); code != http.StatusBadRequest {
+		t.Fatalf("register without owner: %d", code)
+	}
+	if code := send(http.MethodPut, "/users/u1", "k1", `{"addr":"http://127.0.0.1:9000"}`); code != http.StatusNoContent {
+		t.Fatalf("register: %d", code)
+	}
+	if code := send(http.MethodPut, "/users/u1", "k2", `{"addr":"http://127.0.0.1:6666"}`); code != http.StatusForbidden {
+		t.Fatalf("overwrite by another owner: %d", code)
+	}
+	if code := send(http.MethodPost, "/users/u1/heartbeat", "k1", ""); code != http.StatusNoContent {
+		t.Fatalf("heartbeat: %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	h.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `"addr":"http://127.0.0.1:9000"`) {
+		t.Fatalf("list: %s", rec.Body)
+	}
+
+	if code := send(http.MethodDelete, "/users/u1", "k2", ""); code != http.StatusForbidden {
+		t.Fatalf("deregister by another owner: %d", code)
+	}
+	if code := send(http.MethodDelete, "/users/u1", "k1", ""); code != http.StatusNoContent {
+		t.Fatalf("deregister: %d", code)
+	}
+	if code := send(http.MethodPost, "/users/u1/heartbeat", "k1", ""); code != http.StatusNotFound {
+		t.Fatalf("heartbeat after deregister: %d", code)
+	}
+}
+
+func TestHandlerRequiresAdminToken(t *testing.T) {
+	h := New(time.Minute).Handler("admin-token")
+
+	for _, auth := range []string{"", "Bearer wrong", "admin-token"} {
+		req := httptest.NewRequest(http.MethodPut, "/users/u1", strings.NewReader(`{"addr":"http://127.0.0.1:9000"}`))
+		req.Header.Set(OwnerHeader, "k1")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("auth %q: got %d", auth, rec.Code)
+		}
+	}
+}
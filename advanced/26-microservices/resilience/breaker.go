@@ -0,0 +1,279 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCircuitOpen     = errors.New("resilience: circuit breaker is open")
+	ErrTooManyRequests = errors.New("resilience: too many requests in half-open state")
+
+	// errPanicked dilaporkan ke breaker kalau fn di Execute panic. Selalu
+	// dihitung gagal, apa pun isi IsFailure.
+	errPanicked = errors.New("resilience: call panicked")
+)
+
+type BreakerConfig struct {
+	Name string
+
+	// Window adalah lebar rolling window untuk menghitung failure rate,
+	// dibagi menjadi Buckets potongan waktu.
+	Window  time.Duration
+	Buckets int
+
+	// MinRequests jumlah minimal call di dalam window sebelum failure rate
+	// dihitung, supaya 1 error dari 1 request tidak langsung membuka breaker.
+	MinRequests int
+	FailureRate float64
+
+	// OpenTimeout lama breaker di state open sebelum mencoba half-open.
+	OpenTimeout time.Duration
+
+	// HalfOpenMaxCalls jumlah call percobaan di state half-open. Kalau semua
+	// sukses breaker kembali closed, satu saja gagal breaker open lagi.
+	HalfOpenMaxCalls int
+
+	// IsFailure menentukan error mana yang dihitung sebagai kegagalan.
+	// Default: semua error kecuali context.Canceled.
+	IsFailure func(error) bool
+
+	OnStateChange func(StateChange)
+
+	now func() time.Time
+}
+
+type bucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+type Breaker struct {
+	cfg BreakerConfig
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	openedAt   time.Time
+	buckets    []bucket
+
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+
+	pending []StateChange
+}
+
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	if cfg.Buckets <= 0 {
+		cfg.Buckets = 10
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 10
+	}
+	if cfg.FailureRate <= 0 || cfg.FailureRate > 1 {
+		cfg.FailureRate = 0.5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(err error) bool {
+			return err != nil && !errors.Is(err, context.Canceled)
+		}
+	}
+	if cfg.now == nil {
+		cfg.now = time.Now
+	}
+
+	return &Breaker{
+		cfg:     cfg,
+		buckets: make([]bucket, cfg.Buckets),
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.refreshLocked(b.cfg.now())
+	return b.state
+}
+
+// Allow meminta izin untuk melakukan satu call. Kalau diizinkan, done wajib
+// dipanggil dengan hasil call tersebut.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := b.cfg.now()
+	b.refreshLocked(now)
+
+	switch b.state {
+	case StateOpen:
+		return nil, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.halfOpenInFlight+b.halfOpenSuccesses >= b.cfg.HalfOpenMaxCalls {
+			return nil, ErrTooManyRequests
+		}
+		b.halfOpenInFlight++
+	}
+
+	generation := b.generation
+	var once sync.Once
+
+	return func(err error) {
+		once.Do(func() { b.record(generation, err) })
+	}, nil
+}
+
+// Execute menjalankan fn kalau breaker mengizinkan. Kalau fn panic, call
+// dicatat sebagai kegagalan lalu panic diteruskan ke caller; tanpa itu slot
+// percobaan half-open tidak pernah dikembalikan dan breaker macet di
+// half-open.
+func (b *Breaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	panicked := true
+	defer func() {
+		if panicked {
+			done(errPanicked)
+		}
+	}()
+
+	err = fn(ctx)
+	panicked = false
+	done(err)
+
+	return err
+}
+
+func (b *Breaker) record(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := b.cfg.now()
+	b.refreshLocked(now)
+
+	// hasil dari call yang dimulai di state sebelumnya diabaikan
+	if generation != b.generation {
+		return
+	}
+
+	failed := err == errPanicked || b.cfg.IsFailure(err)
+
+	switch b.state {
+	case StateClosed:
+		bk := b.currentBucketLocked(now)
+		if failed {
+			bk.failures++
+		} else {
+			bk.successes++
+		}
+
+		total, failures := b.countsLocked(now)
+		if total >= b.cfg.MinRequests && float64(failures)/float64(total) >= b.cfg.FailureRate {
+			b.setStateLocked(StateOpen, now)
+		}
+	case StateHalfOpen:
+		b.halfOpenInFlight--
+		if failed {
+			b.setStateLocked(StateOpen, now)
+			return
+		}
+
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.cfg.HalfOpenMaxCalls {
+			b.setStateLocked(StateClosed, now)
+		}
+	}
+}
+
+func (b *Breaker) refreshLocked(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.setStateLocked(StateHalfOpen, now)
+	}
+}
+
+func (b *Breaker) setStateLocked(to State, now time.Time) {
+	from := b.state
+	if from == to {
+		return
+	}
+
+	b.state = to
+	b.generation++
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccesses = 0
+
+	switch to {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		for i := range b.buckets {
+			b.buckets[i] = bucket{}
+		}
+	}
+
+	if b.cfg.OnStateChange != nil {
+		b.pending = append(b.pending, StateChange{Name: b.cfg.Name, From: from, To: to, At: now})
+	}
+}
+
+// unlock melepas lock lalu mengirim event yang tertunda, jadi handler
+// OnStateChange tidak pernah dipanggil sambil memegang lock.
+func (b *Breaker) unlock() {
+	events := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	for _, ev := range events {
+		b.cfg.OnStateChange(ev)
+	}
+}
+
+func (b *Breaker) bucketSize() time.Duration {
+	size := b.cfg.Window / time.Duration(b.cfg.Buckets)
+	if size <= 0 {
+		size = time.Nanosecond
+	}
+
+	return size
+}
+
+func (b *Breaker) currentBucketLocked(now time.Time) *bucket {
+	size := b.bucketSize()
+	slot := now.UnixNano() / int64(size)
+	start := time.Unix(0, slot*int64(size))
+	idx := int(slot % int64(len(b.buckets)))
+
+	bk := &b.buckets[idx]
+	if !bk.start.Equal(start) {
+		*bk = bucket{start: start}
+	}
+
+	return bk
+}
+
+func (b *Breaker) countsLocked(now time.Time) (total, failures int) {
+	for _, bk := range b.buckets {
+		if bk.start.IsZero() || now.Sub(bk.start) >= b.cfg.Window {
+			continue
+		}
+		total += bk.successes + bk.failures
+		failures += bk.failures
+	}
+
+	return total, failures
+}
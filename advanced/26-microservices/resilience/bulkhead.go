@@ -0,0 +1,66 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrBulkheadFull = errors.New("resilience: bulkhead is full")
+
+// Bulkhead membatasi jumlah call yang berjalan bersamaan ke satu dependency,
+// supaya downstream yang lambat tidak menghabiskan semua goroutine caller.
+type Bulkhead struct {
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+// NewBulkhead membuat bulkhead dengan maxConcurrent slot. maxWait adalah lama
+// maksimal menunggu slot kosong; 0 berarti langsung ditolak kalau penuh.
+func NewBulkhead(maxConcurrent int, maxWait time.Duration) *Bulkhead {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	return &Bulkhead{
+		slots:   make(chan struct{}, maxConcurrent),
+		maxWait: maxWait,
+	}
+}
+
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+func (b *Bulkhead) Execute(ctx context.Context, fn func(context.Context) error) error {
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+	defer func() { <-b.slots }()
+
+	return fn(ctx)
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if b.maxWait <= 0 {
+		return ErrBulkheadFull
+	}
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrBulkheadFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,47 @@
+package resilience
+
+import (
+	"log"
+	"time"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChange dikirim setiap kali breaker berpindah state.
+type StateChange struct {
+	Name string
+	From State
+	To   State
+	At   time.Time
+}
+
+// LogStateChanges menghasilkan handler OnStateChange yang menulis ke logger.
+// Kalau logger nil dipakai log.Default().
+func LogStateChanges(logger *log.Logger) func(StateChange) {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return func(ev StateChange) {
+		logger.Printf("circuit breaker %q: %s -> %s", ev.Name, ev.From, ev.To)
+	}
+}
@@ -0,0 +1,62 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+)
+
+// Policy membungkus eksekusi sebuah call. Breaker, Retrier dan Bulkhead
+// semuanya mengimplementasikan interface ini sehingga bisa disusun.
+type Policy interface {
+	Execute(ctx context.Context, fn func(context.Context) error) error
+}
+
+// PolicyFunc adapter supaya function biasa bisa dipakai sebagai Policy.
+type PolicyFunc func(ctx context.Context, fn func(context.Context) error) error
+
+func (f PolicyFunc) Execute(ctx context.Context, fn func(context.Context) error) error {
+	return f(ctx, fn)
+}
+
+// Pipeline menjalankan policy dari luar ke dalam, policy pertama adalah
+// yang paling luar. Urutan yang umum: Retry -> Breaker -> Bulkhead, jadi
+// setiap percobaan ulang tetap dicek oleh breaker dan dibatasi bulkhead.
+type Pipeline []Policy
+
+func Wrap(policies ...Policy) Pipeline {
+	return Pipeline(policies)
+}
+
+func (p Pipeline) Execute(ctx context.Context, fn func(context.Context) error) error {
+	call := fn
+	for i := len(p) - 1; i >= 0; i-- {
+		policy, next := p[i], call
+		call = func(ctx context.Context) error {
+			return policy.Execute(ctx, next)
+		}
+	}
+
+	return call(ctx)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent menandai error yang tidak boleh di-retry, misalnya request
+// yang body-nya tidak bisa dibaca ulang.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,403 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errBoom = errors.New("boom")
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func fail(context.Context) error    { return errBoom }
+func succeed(context.Context) error { return nil }
+
+func TestBreakerOpensOnFailureRate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+
+	var events []StateChange
+	b := NewBreaker(BreakerConfig{
+		Name:        "users",
+		Window:      10 * time.Second,
+		MinRequests: 4,
+		FailureRate: 0.5,
+		OpenTimeout: 5 * time.Second,
+		OnStateChange: func(ev StateChange) {
+			events = append(events, ev)
+		},
+		now: clock.Now,
+	})
+
+	ctx := context.Background()
+	b.Execute(ctx, succeed)
+	b.Execute(ctx, succeed)
+	b.Execute(ctx, fail)
+
+	if b.State() != StateClosed {
+		t.Fatalf("breaker must stay closed below MinRequests, got %s", b.State())
+	}
+
+	b.Execute(ctx, fail)
+
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after 2/4 failures, got %s", b.State())
+	}
+
+	if err := b.Execute(ctx, succeed); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	clock.Advance(5 * time.Second)
+
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half-open after timeout, got %s", b.State())
+	}
+
+	if err := b.Execute(ctx, succeed); err != nil {
+		t.Fatalf("probe call failed: %v", err)
+	}
+
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after successful probe, got %s", b.State())
+	}
+
+	want := []State{StateOpen, StateHalfOpen, StateClosed}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, ev := range events {
+		if ev.To != want[i] || ev.Name != "users" {
+			t.Errorf("event %d = %+v, want To=%s", i, ev, want[i])
+		}
+	}
+}
+
+func TestBreakerFailuresOutsideWindowAreForgotten(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	b := NewBreaker(BreakerConfig{Window: 10 * time.Second, MinRequests: 2, FailureRate: 0.5, now: clock.Now})
+
+	ctx := context.Background()
+	b.Execute(ctx, fail)
+	clock.Advance(11 * time.Second)
+	b.Execute(ctx, succeed)
+	b.Execute(ctx, succeed)
+
+	if b.State() != StateClosed {
+		t.Fatalf("old failure must not count, got %s", b.State())
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	b := NewBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Second, HalfOpenMaxCalls: 2, now: clock.Now})
+
+	ctx := context.Background()
+	b.Execute(ctx, fail)
+	clock.Advance(time.Second)
+
+	done1, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done2, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("third probe must be rejected, got %v", err)
+	}
+
+	done1(nil)
+	done2(errBoom)
+
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after failed probe, got %s", b.State())
+	}
+}
+
+func TestBreakerPanicReleasesHalfOpenProbe(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	// IsFailure yang mengabaikan semua error tidak boleh membuat panic
+	// dihitung sukses
+	b := NewBreaker(BreakerConfig{MinRequests: 1, OpenTimeout: time.Second, now: clock.Now, IsFailure: func(err error) bool { return errors.Is(err, errBoom) }})
+
+	ctx := context.Background()
+	b.Execute(ctx, fail)
+	clock.Advance(time.Second)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("panic must reach the caller, got %v", r)
+			}
+		}()
+		b.Execute(ctx, func(context.Context) error { panic("boom") })
+	}()
+
+	if b.State() != StateOpen {
+		t.Fatalf("panicking probe must reopen the breaker, got %s", b.State())
+	}
+
+	clock.Advance(time.Second)
+	if err := b.Execute(ctx, func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("probe slot must be free again: %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after successful probe, got %s", b.State())
+	}
+}
+
+func TestRetrierBackoffAndAttempts(t *testing.T) {
+	r := NewRetrier(RetryConfig{MaxAttempts: 4, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond})
+
+	var waits []time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	calls := 0
+	err := r.Execute(context.Background(), func(context.Context) error {
+		calls++
+		return errBoom
+	})
+
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected last error, got %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 attempts, got %d", calls)
+	}
+
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("wait %d = %s, want %s", i, waits[i], want[i])
+		}
+	}
+}
+
+func TestRetrierJitterStaysInRange(t *testing.T) {
+	r := NewRetrier(RetryConfig{InitialBackoff: 100 * time.Millisecond, Jitter: 0.2})
+
+	for i := 0; i < 100; i++ {
+		d := r.Backoff(1)
+		if d < 80*time.Millisecond || d > 120*time.Millisecond {
+			t.Fatalf("backoff %s out of jitter range", d)
+		}
+	}
+}
+
+func TestRetrierStopsBeforeDeadline(t *testing.T) {
+	r := NewRetrier(RetryConfig{MaxAttempts: 10, InitialBackoff: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := r.Execute(ctx, func(context.Context) error {
+		calls++
+		return errBoom
+	})
+
+	if !errors.Is(err, errBoom) || calls != 1 {
+		t.Fatalf("expected single attempt with errBoom, got calls=%d err=%v", calls, err)
+	}
+	if time.Since(start) > 40*time.Millisecond {
+		t.Fatal("retrier must not sleep past the deadline")
+	}
+}
+
+func TestRetrierDoesNotRetryPermanentOrOpenCircuit(t *testing.T) {
+	r := NewRetrier(RetryConfig{MaxAttempts: 5, InitialBackoff: time.Millisecond})
+
+	for _, target := range []error{Permanent(errBoom), ErrCircuitOpen} {
+		calls := 0
+		r.Execute(context.Background(), func(context.Context) error {
+			calls++
+			return target
+		})
+		if calls != 1 {
+			t.Errorf("%v retried %d times", target, calls)
+		}
+	}
+}
+
+func TestBulkheadLimitsConcurrency(t *testing.T) {
+	b := NewBulkhead(2, 0)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Execute(context.Background(), func(context.Context) error {
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+		}()
+	}
+	<-started
+	<-started
+
+	if err := b.Execute(context.Background(), succeed); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if b.InFlight() != 0 {
+		t.Fatalf("slots leaked: %d", b.InFlight())
+	}
+}
+
+func TestBulkheadWaitsForSlot(t *testing.T) {
+	b := NewBulkhead(1, time.Second)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go b.Execute(context.Background(), func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+
+	if err := b.Execute(context.Background(), succeed); err != nil {
+		t.Fatalf("expected slot after wait, got %v", err)
+	}
+}
+
+func TestTransportRetriesServerErrors(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&hits, 1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	retrier := NewRetrier(RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	client := &http.Client{Transport: NewTransport(nil, retrier, NewBreaker(BreakerConfig{}))}
+
+	// POST boleh di-retry karena membawa Idempotency-Key
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("halo"))
+	req.Header.Set("Idempotency-Key", "order-1")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "halo" {
+		t.Fatalf("got %d %q", resp.StatusCode, body)
+	}
+	if hits != 3 {
+		t.Fatalf("expected 3 hits, got %d", hits)
+	}
+}
+
+func TestTransportDoesNotRetryNonIdempotentRequests(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	retrier := NewRetrier(RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	client := &http.Client{Transport: NewTransport(nil, retrier)}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("halo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || hits != 1 {
+		t.Fatalf("POST must be attempted once, got %d after %d hits", resp.StatusCode, hits)
+	}
+}
+
+func TestTransportReturnsLastFailedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	retrier := NewRetrier(RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	client := &http.Client{Transport: NewTransport(nil, retrier)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 to reach caller, got %d", resp.StatusCode)
+	}
+}
+
+type fakeRPC struct {
+	calls int
+	fails int
+}
+
+func (f *fakeRPC) Call(serviceMethod string, args any, reply any) error {
+	f.calls++
+	if f.calls <= f.fails {
+		return rpc.ErrShutdown
+	}
+	*reply.(*string) = "pong"
+	return nil
+}
+
+func TestWrapRPC(t *testing.T) {
+	caller := &fakeRPC{fails: 1}
+	client := WrapRPC(caller, NewRetrier(RetryConfig{InitialBackoff: time.Millisecond}))
+
+	var reply string
+	if err := client.Call(context.Background(), "Ping.Ping", "ping", &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply != "pong" || caller.calls != 2 {
+		t.Fatalf("reply=%q calls=%d", reply, caller.calls)
+	}
+}
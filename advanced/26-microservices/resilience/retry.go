@@ -0,0 +1,138 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type RetryConfig struct {
+	// MaxAttempts termasuk percobaan pertama, jadi 3 berarti 1 call + 2 retry.
+	MaxAttempts int
+
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter 0..1, porsi backoff yang diacak. 0.2 artinya wait berada di
+	// rentang backoff ±20%.
+	Jitter float64
+
+	// Retryable menentukan error mana yang layak dicoba lagi. Error dari
+	// Permanent, ErrCircuitOpen dan error context tidak pernah di-retry.
+	Retryable func(error) bool
+
+	OnRetry func(attempt int, err error, wait time.Duration)
+}
+
+type Retrier struct {
+	cfg RetryConfig
+
+	sleep func(ctx context.Context, d time.Duration) error
+	rand  func() float64
+}
+
+func NewRetrier(cfg RetryConfig) *Retrier {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Second
+	}
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = 2
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.Jitter > 1 {
+		cfg.Jitter = 1
+	}
+
+	return &Retrier{
+		cfg:   cfg,
+		sleep: sleepContext,
+		rand:  rand.Float64,
+	}
+}
+
+// Backoff menghitung lama tunggu sebelum percobaan ke-(attempt+1).
+// attempt dimulai dari 1.
+func (r *Retrier) Backoff(attempt int) time.Duration {
+	base := float64(r.cfg.InitialBackoff) * math.Pow(r.cfg.Multiplier, float64(attempt-1))
+	if base > float64(r.cfg.MaxBackoff) {
+		base = float64(r.cfg.MaxBackoff)
+	}
+
+	if r.cfg.Jitter > 0 {
+		delta := base * r.cfg.Jitter
+		base = base - delta + r.rand()*2*delta
+	}
+
+	return time.Duration(base)
+}
+
+func (r *Retrier) Execute(ctx context.Context, fn func(context.Context) error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if err != nil {
+				return err
+			}
+			return ctxErr
+		}
+
+		err = fn(ctx)
+		if err == nil || attempt >= r.cfg.MaxAttempts || !r.shouldRetry(err) {
+			return err
+		}
+
+		wait := r.Backoff(attempt)
+
+		// tidak ada gunanya menunggu kalau deadline habis sebelum retry berikutnya
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		if r.cfg.OnRetry != nil {
+			r.cfg.OnRetry(attempt, err, wait)
+		}
+
+		if sleepErr := r.sleep(ctx, wait); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+func (r *Retrier) shouldRetry(err error) bool {
+	if isPermanent(err) ||
+		errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if r.cfg.Retryable != nil {
+		return r.cfg.Retryable(err)
+	}
+
+	return true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
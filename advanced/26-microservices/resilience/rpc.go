@@ -0,0 +1,31 @@
+package resilience
+
+import "context"
+
+// RPCCaller adalah bentuk minimal client RPC. *rpc.Client dari net/rpc
+// sudah memenuhi interface ini.
+type RPCCaller interface {
+	Call(serviceMethod string, args any, reply any) error
+}
+
+type RPCClient struct {
+	caller RPCCaller
+	policy Policy
+}
+
+func WrapRPC(caller RPCCaller, policies ...Policy) *RPCClient {
+	return &RPCClient{caller: caller, policy: Wrap(policies...)}
+}
+
+// Call menjalankan call lewat policy. Context dicek sebelum setiap percobaan
+// dan saat menunggu (backoff, slot bulkhead); call yang sudah jalan tidak
+// bisa dibatalkan karena net/rpc tidak mendukung context.
+func (c *RPCClient) Call(ctx context.Context, serviceMethod string, args any, reply any) error {
+	return c.policy.Execute(ctx, func(ctx context.Context) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return c.caller.Call(serviceMethod, args, reply)
+	})
+}
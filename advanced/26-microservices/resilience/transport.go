@@ -0,0 +1,137 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var errBodyNotRewindable = errors.New("resilience: request body cannot be replayed (GetBody is nil)")
+
+// StatusError dipakai di dalam policy untuk menandai response yang dianggap
+// gagal (default: 5xx dan 429) supaya breaker dan retrier bisa menghitungnya.
+// Caller tetap menerima *http.Response aslinya, bukan error ini.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("resilience: upstream responded %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type Transport struct {
+	Base   http.RoundTripper
+	Policy Policy
+
+	// IsFailureStatus default: status >= 500 atau 429.
+	IsFailureStatus func(code int) bool
+}
+
+// NewTransport membungkus base (default http.DefaultTransport) dengan policy
+// yang disusun lewat Wrap, contoh:
+//
+//	client := &http.Client{Transport: resilience.NewTransport(nil, retrier, breaker, bulkhead)}
+func NewTransport(base http.RoundTripper, policies ...Policy) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Base: base, Policy: Wrap(policies...)}
+}
+
+// RoundTrip menjalankan request lewat policy. Hanya request idempotent
+// (GET, HEAD, OPTIONS, TRACE, PUT, DELETE atau yang membawa header
+// Idempotency-Key) yang boleh di-retry; POST dan PATCH biasa gagal
+// permanen setelah percobaan pertama karena upstream mungkin sudah
+// memprosesnya.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		resp    *http.Response
+		attempt int
+	)
+	retryable := isIdempotent(req)
+
+	err := t.Policy.Execute(req.Context(), func(ctx context.Context) error {
+		attempt++
+
+		// response dari percobaan sebelumnya tidak dipakai lagi
+		if resp != nil {
+			drainAndClose(resp.Body)
+			resp = nil
+		}
+
+		r := req.Clone(ctx)
+		if attempt > 1 && req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return Permanent(errBodyNotRewindable)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return Permanent(err)
+			}
+			r.Body = body
+		}
+
+		res, err := t.Base.RoundTrip(r)
+		if err != nil {
+			return failure(err, retryable)
+		}
+
+		resp = res
+		if t.isFailureStatus(res.StatusCode) {
+			return failure(&StatusError{StatusCode: res.StatusCode}, retryable)
+		}
+
+		return nil
+	})
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && resp != nil {
+		return resp, nil
+	}
+	if err != nil {
+		if resp != nil {
+			drainAndClose(resp.Body)
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func failure(err error, retryable bool) error {
+	if !retryable {
+		return Permanent(err)
+	}
+
+	return err
+}
+
+// isIdempotent memakai aturan yang sama dengan canRetry di
+// go-stdlib-learning/05-networking/http/client.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func (t *Transport) isFailureStatus(code int) bool {
+	if t.IsFailureStatus != nil {
+		return t.IsFailureStatus(code)
+	}
+
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+func drainAndClose(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	body.Close()
+}
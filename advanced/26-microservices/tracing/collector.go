@@ -0,0 +1,270 @@
+package tracing
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Collector menyimpan span di memory (maksimal maxTraces trace terbaru) dan
+// menyediakan UI sederhana. Pasang di server dengan:
+//
+//	mux.Handle("/tracing/", http.StripPrefix("/tracing", collector))
+type Collector struct {
+	mu        sync.Mutex
+	maxTraces int
+	traces    map[TraceID][]SpanData
+	order     []TraceID
+}
+
+type TraceSummary struct {
+	TraceID   TraceID
+	Root      string
+	Service   string
+	Start     time.Time
+	Duration  time.Duration
+	SpanCount int
+	HasError  bool
+}
+
+func NewCollector(maxTraces int) *Collector {
+	if maxTraces <= 0 {
+		maxTraces = 100
+	}
+
+	return &Collector{
+		maxTraces: maxTraces,
+		traces:    make(map[TraceID][]SpanData),
+	}
+}
+
+func (c *Collector) ExportSpan(data SpanData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.traces[data.TraceID]; !ok {
+		c.order = append(c.order, data.TraceID)
+		if len(c.order) > c.maxTraces {
+			oldest := c.order[0]
+			c.order = c.order[1:]
+			delete(c.traces, oldest)
+		}
+	}
+
+	c.traces[data.TraceID] = append(c.traces[data.TraceID], data)
+}
+
+// Trace mengembalikan semua span dalam satu trace, diurutkan dari start time.
+func (c *Collector) Trace(id TraceID) []SpanData {
+	c.mu.Lock()
+	spans := append([]SpanData(nil), c.traces[id]...)
+	c.mu.Unlock()
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+
+	return spans
+}
+
+// Traces mengembalikan ringkasan semua trace, yang terbaru lebih dulu.
+func (c *Collector) Traces() []TraceSummary {
+	c.mu.Lock()
+	ids := append([]TraceID(nil), c.order...)
+	c.mu.Unlock()
+
+	summaries := make([]TraceSummary, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		spans := c.Trace(ids[i])
+		if len(spans) == 0 {
+			continue
+		}
+		summaries = append(summaries, summarize(ids[i], spans))
+	}
+
+	return summaries
+}
+
+func summarize(id TraceID, spans []SpanData) TraceSummary {
+	s := TraceSummary{TraceID: id, SpanCount: len(spans)}
+
+	start, end := traceBounds(spans)
+	s.Start = start
+	s.Duration = end.Sub(start)
+
+	s.Root, s.Service = spans[0].Name, spans[0].Service
+	for _, span := range spans {
+		if !span.ParentSpanID.IsValid() {
+			s.Root, s.Service = span.Name, span.Service
+		}
+		if span.Error != "" {
+			s.HasError = true
+		}
+	}
+
+	return s
+}
+
+func traceBounds(spans []SpanData) (start, end time.Time) {
+	for i, span := range spans {
+		if i == 0 || span.Start.Before(start) {
+			start = span.Start
+		}
+		if span.End.After(end) {
+			end = span.End
+		}
+	}
+
+	return start, end
+}
+
+type waterfallRow struct {
+	Span   SpanData
+	Depth  int
+	Offset float64
+	Width  float64
+}
+
+// waterfall menyusun span sebagai tree (child di bawah parent) lalu menghitung
+// posisi bar dalam persen terhadap durasi trace.
+func waterfall(spans []SpanData) []waterfallRow {
+	start, end := traceBounds(spans)
+	total := end.Sub(start)
+	if total <= 0 {
+		total = 1
+	}
+
+	known := make(map[SpanID]bool, len(spans))
+	for _, span := range spans {
+		known[span.SpanID] = true
+	}
+
+	children := make(map[SpanID][]SpanData)
+	var roots []SpanData
+	for _, span := range spans {
+		// parent yang tidak ada di collector (misal di service lain) dianggap root
+		if span.ParentSpanID.IsValid() && known[span.ParentSpanID] {
+			children[span.ParentSpanID] = append(children[span.ParentSpanID], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	rows := make([]waterfallRow, 0, len(spans))
+	var walk func(span SpanData, depth int)
+	walk = func(span SpanData, depth int) {
+		width := float64(span.Duration()) / float64(total) * 100
+		if width < 0.5 {
+			width = 0.5
+		}
+
+		rows = append(rows, waterfallRow{
+			Span:   span,
+			Depth:  depth,
+			Offset: float64(span.Start.Sub(start)) / float64(total) * 100,
+			Width:  width,
+		})
+		for _, child := range children[span.SpanID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return rows
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+
+	switch {
+	case path == "":
+		c.serveList(w)
+	case strings.HasPrefix(path, "api/traces/"):
+		c.serveTrace(w, strings.TrimPrefix(path, "api/traces/"), true)
+	case strings.HasPrefix(path, "traces/"):
+		c.serveTrace(w, strings.TrimPrefix(path, "traces/"), false)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (c *Collector) serveList(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	listTemplate.Execute(w, c.Traces())
+}
+
+func (c *Collector) serveTrace(w http.ResponseWriter, rawID string, asJSON bool) {
+	id, err := ParseTraceID(rawID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spans := c.Trace(id)
+	if len(spans) == 0 {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+
+	if asJSON {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(spans)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	traceTemplate.Execute(w, map[string]any{
+		"Summary": summarize(id, spans),
+		"Rows":    waterfall(spans),
+	})
+}
+
+var templateFuncs = template.FuncMap{
+	"indent": func(depth int) int { return depth * 16 },
+	"ms": func(d time.Duration) string {
+		return d.Round(time.Microsecond).String()
+	},
+}
+
+const pageStyle = `<style>
+body { font-family: sans-serif; margin: 24px; }
+table { border-collapse: collapse; width: 100%; }
+td, th { padding: 4px 8px; border-bottom: 1px solid #eee; text-align: left; font-size: 14px; }
+.track { position: relative; height: 14px; background: #f5f5f5; }
+.bar { position: absolute; height: 14px; background: #4a90d9; }
+.bar.error, tr.error td.name { background: #d9534f; color: #fff; }
+</style>`
+
+var listTemplate = template.Must(template.New("list").Funcs(templateFuncs).Parse(`<!doctype html>
+<html><head><title>Traces</title>` + pageStyle + `</head><body>
+<h1>Traces</h1>
+<table>
+<tr><th>Root span</th><th>Service</th><th>Spans</th><th>Duration</th><th>Start</th></tr>
+{{range .}}<tr{{if .HasError}} class="error"{{end}}>
+<td class="name"><a href="traces/{{.TraceID}}">{{.Root}}</a></td>
+<td>{{.Service}}</td><td>{{.SpanCount}}</td><td>{{ms .Duration}}</td><td>{{.Start.Format "15:04:05.000"}}</td>
+</tr>{{else}}<tr><td colspan="5">Belum ada trace.</td></tr>{{end}}
+</table>
+</body></html>`))
+
+var traceTemplate = template.Must(template.New("trace").Funcs(templateFuncs).Parse(`<!doctype html>
+<html><head><title>Trace {{.Summary.TraceID}}</title>` + pageStyle + `</head><body>
+<p><a href="../">&larr; semua trace</a></p>
+<h1>{{.Summary.Root}}</h1>
+<p>trace {{.Summary.TraceID}} &middot; {{.Summary.SpanCount}} spans &middot; {{ms .Summary.Duration}}</p>
+<table>
+<tr><th style="width:35%">Span</th><th>Service</th><th>Duration</th><th style="width:40%"></th></tr>
+{{range .Rows}}<tr{{if .Span.Error}} class="error" title="{{.Span.Error}}"{{end}}>
+<td class="name" style="padding-left:{{indent .Depth}}px">{{.Span.Name}} <small>({{.Span.Kind}})</small></td>
+<td>{{.Span.Service}}</td>
+<td>{{ms .Span.Duration}}</td>
+<td><div class="track"><div class="bar{{if .Span.Error}} error{{end}}" style="left:{{printf "%.2f" .Offset}}%;width:{{printf "%.2f" .Width}}%"></div></div></td>
+</tr>{{end}}
+</table>
+</body></html>`))
@@ -0,0 +1,30 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// JSONExporter menulis setiap span sebagai satu baris JSON.
+type JSONExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{enc: json.NewEncoder(w)}
+}
+
+func NewStdoutExporter() *JSONExporter {
+	return NewJSONExporter(os.Stdout)
+}
+
+func (e *JSONExporter) ExportSpan(data SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// error encode diabaikan, tracing tidak boleh mengganggu request
+	e.enc.Encode(data)
+}
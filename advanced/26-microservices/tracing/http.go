@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Middleware membuat server span untuk setiap request, melanjutkan trace dari
+// header traceparent kalau ada. Handler bisa mengambil span lewat
+// SpanFromContext(r.Context()).
+func Middleware(tracer *Tracer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := Extract(r.Context(), r.Header)
+		ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path,
+			WithKind(KindServer),
+			WithAttributes(map[string]string{
+				"http.method": r.Method,
+				"http.target": r.URL.EscapedPath(),
+			}),
+		)
+		defer span.End()
+
+		// response juga membawa traceparent supaya caller bisa mencari trace-nya
+		w.Header().Set(TraceparentHeader, FormatTraceparent(span.SpanContext()))
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		span.SetAttribute("http.status_code", strconv.Itoa(rec.status))
+		if rec.status >= 500 {
+			span.RecordError(errorStatus(rec.status))
+		}
+	})
+}
+
+// Transport membuat client span untuk setiap request keluar dan menyisipkan
+// header traceparent.
+type Transport struct {
+	Base   http.RoundTripper
+	Tracer *Tracer
+}
+
+func NewTransport(tracer *Tracer, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Base: base, Tracer: tracer}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.Tracer.Start(req.Context(), "HTTP "+req.Method,
+		WithKind(KindClient),
+		WithAttributes(map[string]string{
+			"http.method": req.Method,
+			"http.url":    spanURL(req.URL),
+		}),
+	)
+	defer span.End()
+
+	out := req.Clone(ctx)
+	Inject(ctx, out.Header)
+
+	resp, err := t.Base.RoundTrip(out)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttribute("http.status_code", strconv.Itoa(resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.RecordError(errorStatus(resp.StatusCode))
+	}
+
+	return resp, nil
+}
+
+// spanURL hanya menyimpan scheme, host dan path. Query, fragment dan
+// userinfo sering berisi token atau signature, dan span bisa dibaca siapa
+// pun yang punya akses ke collector.
+func spanURL(u *url.URL) string {
+	clean := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, RawPath: u.RawPath}
+	return clean.String()
+}
+
+type errorStatus int
+
+func (e errorStatus) Error() string {
+	return "HTTP " + strconv.Itoa(int(e)) + " " + http.StatusText(int(e))
+}
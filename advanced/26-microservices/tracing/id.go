@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+type TraceID [16]byte
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (t TraceID) IsValid() bool  { return t != TraceID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+
+// MarshalText dipakai encoding/json sehingga id ditulis sebagai hex.
+// Span id kosong (root span tidak punya parent) ditulis sebagai string kosong.
+func (t TraceID) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+func (s SpanID) MarshalText() ([]byte, error) {
+	if !s.IsValid() {
+		return []byte{}, nil
+	}
+	return []byte(s.String()), nil
+}
+
+func ParseTraceID(s string) (TraceID, error) {
+	var id TraceID
+	if err := decodeHex(s, id[:]); err != nil {
+		return TraceID{}, err
+	}
+	if !id.IsValid() {
+		return TraceID{}, errors.New("tracing: trace id is all zeros")
+	}
+
+	return id, nil
+}
+
+func ParseSpanID(s string) (SpanID, error) {
+	var id SpanID
+	if err := decodeHex(s, id[:]); err != nil {
+		return SpanID{}, err
+	}
+	if !id.IsValid() {
+		return SpanID{}, errors.New("tracing: span id is all zeros")
+	}
+
+	return id, nil
+}
+
+// decodeHex hanya menerima huruf kecil sesuai spesifikasi W3C trace context.
+func decodeHex(s string, dst []byte) error {
+	if len(s) != len(dst)*2 {
+		return errors.New("tracing: invalid id length")
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return errors.New("tracing: id must be lowercase hex")
+		}
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader sesuai https://www.w3.org/TR/trace-context/
+// format: {version}-{trace-id}-{parent-id}-{trace-flags}
+const TraceparentHeader = "traceparent"
+
+const flagSampled = 0x01
+
+func FormatTraceparent(sc SpanContext) string {
+	flags := 0
+	if sc.Sampled {
+		flags = flagSampled
+	}
+
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+func ParseTraceparent(value string) (SpanContext, error) {
+	value = strings.TrimSpace(value)
+
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 {
+		return SpanContext{}, errors.New("tracing: malformed traceparent")
+	}
+
+	version := parts[0]
+	if len(version) != 2 || version == "ff" {
+		return SpanContext{}, errors.New("tracing: invalid traceparent version")
+	}
+	// versi 00 harus tepat 4 bagian, versi lebih baru boleh punya tambahan
+	if version == "00" && len(parts) != 4 {
+		return SpanContext{}, errors.New("tracing: malformed traceparent")
+	}
+	if _, err := parseHexByte(version); err != nil {
+		return SpanContext{}, err
+	}
+
+	traceID, err := ParseTraceID(parts[1])
+	if err != nil {
+		return SpanContext{}, err
+	}
+	spanID, err := ParseSpanID(parts[2])
+	if err != nil {
+		return SpanContext{}, err
+	}
+	flags, err := parseHexByte(parts[3])
+	if err != nil {
+		return SpanContext{}, err
+	}
+
+	return SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: flags&flagSampled != 0,
+		Remote:  true,
+	}, nil
+}
+
+func parseHexByte(s string) (byte, error) {
+	var b [1]byte
+	if err := decodeHex(s, b[:]); err != nil {
+		return 0, errors.New("tracing: invalid traceparent field " + s)
+	}
+
+	return b[0], nil
+}
+
+// Inject menulis span aktif di ctx ke header traceparent.
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	header.Set(TraceparentHeader, FormatTraceparent(sc))
+}
+
+// Extract membaca traceparent dari header. Header yang tidak valid diabaikan
+// sehingga request tetap diproses sebagai trace baru.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	value := header.Get(TraceparentHeader)
+	if value == "" {
+		return ctx
+	}
+
+	sc, err := ParseTraceparent(value)
+	if err != nil {
+		return ctx
+	}
+
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
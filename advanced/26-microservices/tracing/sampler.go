@@ -0,0 +1,58 @@
+package tracing
+
+import "encoding/binary"
+
+type SamplingParams struct {
+	TraceID TraceID
+	Name    string
+
+	// Parent kosong (IsValid false) untuk root span.
+	Parent SpanContext
+}
+
+type Sampler interface {
+	ShouldSample(p SamplingParams) bool
+}
+
+type SamplerFunc func(p SamplingParams) bool
+
+func (f SamplerFunc) ShouldSample(p SamplingParams) bool { return f(p) }
+
+func AlwaysSample() Sampler {
+	return SamplerFunc(func(SamplingParams) bool { return true })
+}
+
+func NeverSample() Sampler {
+	return SamplerFunc(func(SamplingParams) bool { return false })
+}
+
+// TraceIDRatio men-sample sebagian trace berdasarkan trace id, jadi semua
+// service yang memakai ratio sama akan mengambil keputusan yang sama.
+func TraceIDRatio(fraction float64) Sampler {
+	if fraction >= 1 {
+		return AlwaysSample()
+	}
+	if fraction <= 0 {
+		return NeverSample()
+	}
+
+	bound := uint64(fraction * (1 << 63))
+
+	return SamplerFunc(func(p SamplingParams) bool {
+		// pakai 8 byte terakhir, bit teratas dibuang supaya muat di range bound
+		x := binary.BigEndian.Uint64(p.TraceID[8:16]) >> 1
+		return x < bound
+	})
+}
+
+// ParentBased mengikuti keputusan parent kalau ada, dan memakai root untuk
+// trace baru. Ini sampler default Tracer.
+func ParentBased(root Sampler) Sampler {
+	return SamplerFunc(func(p SamplingParams) bool {
+		if p.Parent.IsValid() {
+			return p.Parent.Sampled
+		}
+
+		return root.ShouldSample(p)
+	})
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	KindInternal SpanKind = iota
+	KindServer
+	KindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+func (k SpanKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// SpanContext adalah identitas span yang ikut berpindah antar service.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	Remote  bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanData snapshot span yang sudah selesai, ini yang diterima exporter.
+type SpanData struct {
+	TraceID      TraceID           `json:"trace_id"`
+	SpanID       SpanID            `json:"span_id"`
+	ParentSpanID SpanID            `json:"parent_span_id"`
+	Service      string            `json:"service"`
+	Name         string            `json:"name"`
+	Kind         SpanKind          `json:"kind"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	return s.sc
+}
+
+func (s *Span) IsRecording() bool {
+	return s.sc.Sampled
+}
+
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = value
+}
+
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.data.Error = err.Error()
+	}
+}
+
+// End menutup span dan mengirimnya ke exporter kalau span di-sample.
+// Memanggil End lebih dari sekali tidak berpengaruh.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.sc.Sampled {
+		s.tracer.export(data)
+	}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext menyimpan parent dari service lain (hasil
+// Extract) supaya span berikutnya menjadi child-nya.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	ctx = context.WithValue(ctx, spanKey{}, (*Span)(nil))
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext mengembalikan span aktif, atau parent remote kalau
+// belum ada span lokal.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"time"
+)
+
+type Exporter interface {
+	ExportSpan(data SpanData)
+}
+
+type Option func(*Tracer)
+
+func WithSampler(s Sampler) Option {
+	return func(t *Tracer) { t.sampler = s }
+}
+
+func WithExporter(e Exporter) Option {
+	return func(t *Tracer) { t.exporters = append(t.exporters, e) }
+}
+
+type Tracer struct {
+	service   string
+	sampler   Sampler
+	exporters []Exporter
+
+	now func() time.Time
+}
+
+func NewTracer(service string, opts ...Option) *Tracer {
+	t := &Tracer{
+		service: service,
+		sampler: ParentBased(AlwaysSample()),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+type StartOption func(*startConfig)
+
+type startConfig struct {
+	kind       SpanKind
+	attributes map[string]string
+}
+
+func WithKind(kind SpanKind) StartOption {
+	return func(c *startConfig) { c.kind = kind }
+}
+
+func WithAttributes(kv map[string]string) StartOption {
+	return func(c *startConfig) {
+		if c.attributes == nil {
+			c.attributes = make(map[string]string, len(kv))
+		}
+		for k, v := range kv {
+			c.attributes[k] = v
+		}
+	}
+}
+
+// Start membuat span baru. Kalau ctx sudah berisi span (lokal atau remote),
+// span baru menjadi child-nya dan memakai trace id yang sama.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	var cfg startConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	parent := SpanContextFromContext(ctx)
+
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+	} else {
+		sc.TraceID = newTraceID()
+	}
+	sc.Sampled = t.sampler.ShouldSample(SamplingParams{TraceID: sc.TraceID, Name: name, Parent: parent})
+
+	span := &Span{
+		tracer: t,
+		sc:     sc,
+		data: SpanData{
+			TraceID:    sc.TraceID,
+			SpanID:     sc.SpanID,
+			Service:    t.service,
+			Name:       name,
+			Kind:       cfg.kind,
+			Start:      t.now(),
+			Attributes: cfg.attributes,
+		},
+	}
+	if parent.IsValid() {
+		span.data.ParentSpanID = parent.SpanID
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+func (t *Tracer) export(data SpanData) {
+	for _, e := range t.exporters {
+		e.ExportSpan(data)
+	}
+}
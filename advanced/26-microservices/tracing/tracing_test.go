@@ -0,0 +1,226 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+
+	header := FormatTraceparent(sc)
+	parsed, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.TraceID != sc.TraceID || parsed.SpanID != sc.SpanID || !parsed.Sampled || !parsed.Remote {
+		t.Fatalf("round trip mismatch: %s -> %+v", header, parsed)
+	}
+}
+
+func TestParseTraceparentRejectsInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+
+	for _, c := range cases {
+		if _, err := ParseTraceparent(c); err == nil {
+			t.Errorf("expected error for %q", c)
+		}
+	}
+
+	// versi yang lebih baru boleh punya field tambahan
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"); err != nil {
+		t.Errorf("future version should be accepted: %v", err)
+	}
+}
+
+func TestChildSpanSharesTrace(t *testing.T) {
+	collector := NewCollector(10)
+	tracer := NewTracer("users", WithExporter(collector))
+
+	ctx, parent := tracer.Start(context.Background(), "create user")
+	_, child := tracer.Start(ctx, "insert row")
+	child.SetAttribute("db.table", "users")
+	child.End()
+	parent.End()
+	parent.End()
+
+	spans := collector.Trace(parent.SpanContext().TraceID)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	got := spans[1]
+	if got.Name != "insert row" || got.ParentSpanID != parent.SpanContext().SpanID || got.Attributes["db.table"] != "users" {
+		t.Fatalf("unexpected child span %+v", got)
+	}
+}
+
+func TestSamplers(t *testing.T) {
+	collector := NewCollector(10)
+	tracer := NewTracer("users", WithSampler(ParentBased(NeverSample())), WithExporter(collector))
+
+	_, span := tracer.Start(context.Background(), "dropped")
+	span.End()
+	if span.IsRecording() || len(collector.Traces()) != 0 {
+		t.Fatal("root span must not be sampled")
+	}
+
+	// parent remote yang sampled tetap diikuti
+	remote := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	_, span = tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "kept")
+	span.End()
+	if !span.IsRecording() || len(collector.Trace(remote.TraceID)) != 1 {
+		t.Fatal("child of sampled remote parent must be sampled")
+	}
+
+	ratio := TraceIDRatio(0.25)
+	sampled := 0
+	for i := 0; i < 4000; i++ {
+		if ratio.ShouldSample(SamplingParams{TraceID: newTraceID()}) {
+			sampled++
+		}
+	}
+	if sampled < 800 || sampled > 1200 {
+		t.Fatalf("ratio sampler sampled %d of 4000", sampled)
+	}
+}
+
+func TestHTTPPropagation(t *testing.T) {
+	collector := NewCollector(10)
+
+	backendTracer := NewTracer("backend", WithExporter(collector))
+	backend := httptest.NewServer(Middleware(backendTracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := backendTracer.Start(r.Context(), "query db")
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	})))
+	defer backend.Close()
+
+	frontendTracer := NewTracer("frontend", WithExporter(collector))
+	client := &http.Client{Transport: NewTransport(frontendTracer, nil)}
+
+	ctx, root := frontendTracer.Start(context.Background(), "checkout")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, backend.URL+"/orders?token=rahasia", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	root.End()
+
+	spans := collector.Trace(root.SpanContext().TraceID)
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans in one trace, got %d: %+v", len(spans), spans)
+	}
+
+	byName := make(map[string]SpanData)
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+
+	clientSpan, serverSpan := byName["HTTP GET"], byName["GET /orders"]
+	if clientSpan.ParentSpanID != root.SpanContext().SpanID {
+		t.Error("client span must be child of root")
+	}
+	if serverSpan.ParentSpanID != clientSpan.SpanID || serverSpan.Kind != KindServer {
+		t.Errorf("server span must be child of client span: %+v", serverSpan)
+	}
+	if byName["query db"].ParentSpanID != serverSpan.SpanID {
+		t.Error("db span must be child of server span")
+	}
+	if serverSpan.Attributes["http.status_code"] != "500" || serverSpan.Error == "" {
+		t.Errorf("server span must record 500: %+v", serverSpan)
+	}
+	if clientSpan.Attributes["http.url"] != backend.URL+"/orders" || serverSpan.Attributes["http.target"] != "/orders" {
+		t.Errorf("query string must not be recorded: %v %v", clientSpan.Attributes, serverSpan.Attributes)
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer("users", WithExporter(NewJSONExporter(&buf)))
+
+	_, span := tracer.Start(context.Background(), "login")
+	span.RecordError(errors.New("wrong password"))
+	span.End()
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+
+	if got["trace_id"] != span.SpanContext().TraceID.String() || got["parent_span_id"] != "" ||
+		got["error"] != "wrong password" || got["kind"] != "internal" {
+		t.Fatalf("unexpected json %v", got)
+	}
+}
+
+func TestCollectorUI(t *testing.T) {
+	collector := NewCollector(1)
+	tracer := NewTracer("users", WithExporter(collector))
+
+	_, old := tracer.Start(context.Background(), "evicted")
+	old.End()
+
+	ctx, root := tracer.Start(context.Background(), "GET /users/1")
+	_, child := tracer.Start(ctx, "select <user>")
+	child.End()
+	root.End()
+
+	if len(collector.Traces()) != 1 {
+		t.Fatal("collector must keep only maxTraces traces")
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/tracing", collector))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	id := root.SpanContext().TraceID.String()
+
+	if code, body := get("/tracing/"); code != 200 || !strings.Contains(body, "traces/"+id) {
+		t.Fatalf("list page: %d %s", code, body)
+	}
+
+	code, body := get("/tracing/traces/" + id)
+	if code != 200 || !strings.Contains(body, "select &lt;user&gt;") || !strings.Contains(body, "padding-left:16px") {
+		t.Fatalf("waterfall page: %d %s", code, body)
+	}
+
+	if code, _ := get("/tracing/traces/" + old.SpanContext().TraceID.String()); code != http.StatusNotFound {
+		t.Fatalf("evicted trace must be gone, got %d", code)
+	}
+
+	code, body = get("/tracing/api/traces/" + id)
+	var spans []map[string]any
+	if err := json.Unmarshal([]byte(body), &spans); err != nil || code != 200 {
+		t.Fatalf("api response: %d %s", code, body)
+	}
+	if len(spans) != 2 || spans[1]["name"] != "select <user>" {
+		t.Fatalf("unexpected api spans %v", spans)
+	}
+}
@@ -0,0 +1,409 @@
+package designpatterns
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Factory pattern berbasis konfigurasi: setiap implementasi sebuah interface
+// mendaftar dengan nama dan skema config, lalu Build memilih implementasi
+// dari key "type" di map config. Map itu bisa berasal dari yaml.Unmarshal
+// atau json.Unmarshal ke map[string]any:
+//
+//	notifiers:
+//	  - type: email
+//	    host: smtp.example.com
+//	    port: 587
+//	  - type: webhook
+//	    url: https://hooks.example.com/ops
+//
+// Semua kesalahan config dilaporkan sekaligus, masing-masing menyebut key
+// yang salah.
+
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	FieldInt
+	FieldFloat
+	FieldBool
+	FieldDuration
+	FieldStringList
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldString:
+		return "string"
+	case FieldInt:
+		return "integer"
+	case FieldFloat:
+		return "number"
+	case FieldBool:
+		return "boolean"
+	case FieldDuration:
+		return "duration"
+	case FieldStringList:
+		return "list of strings"
+	}
+	return fmt.Sprintf("FieldType(%d)", int(t))
+}
+
+type Field struct {
+	Name     string
+	Type     FieldType
+	Required bool
+	Default  any      // dipakai kalau key tidak ada; harus sesuai Type
+	Enum     []string // nilai yang diizinkan untuk String
+}
+
+type Schema []Field
+
+// FieldError menunjuk satu key yang salah. Key memakai path lengkap,
+// misalnya "notifiers[1].port".
+type FieldError struct {
+	Type string // nama implementasi, kosong kalau "type" sendiri yang salah
+	Key  string
+	Msg  string
+}
+
+func (e *FieldError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("designpatterns: config key %q: %s", e.Key, e.Msg)
+	}
+	return fmt.Sprintf("designpatterns: %s config key %q: %s", e.Type, e.Key, e.Msg)
+}
+
+// FactoryConfig adalah map yang sudah divalidasi terhadap Schema dan diisi
+// default, sehingga getter-nya tidak perlu mengembalikan error.
+type FactoryConfig struct {
+	values map[string]any
+}
+
+func (c FactoryConfig) String(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c FactoryConfig) Int(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c FactoryConfig) Float(key string) float64 {
+	v, _ := c.values[key].(float64)
+	return v
+}
+
+func (c FactoryConfig) Bool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c FactoryConfig) Duration(key string) time.Duration {
+	v, _ := c.values[key].(time.Duration)
+	return v
+}
+
+func (c FactoryConfig) Strings(key string) []string {
+	v, _ := c.values[key].([]string)
+	return append([]string(nil), v...)
+}
+
+// Has membedakan key opsional yang tidak diisi dari nilai nol.
+func (c FactoryConfig) Has(key string) bool {
+	_, ok := c.values[key]
+	return ok
+}
+
+type factoryEntry[T any] struct {
+	schema Schema
+	build  func(FactoryConfig) (T, error)
+}
+
+// FactoryRegistry menyimpan constructor untuk implementasi interface T.
+type FactoryRegistry[T any] struct {
+	mu        sync.RWMutex
+	factories map[string]factoryEntry[T]
+}
+
+func NewFactoryRegistry[T any]() *FactoryRegistry[T] {
+	return &FactoryRegistry[T]{factories: make(map[string]factoryEntry[T])}
+}
+
+func (r *FactoryRegistry[T]) Register(name string, schema Schema, build func(FactoryConfig) (T, error)) error {
+	seen := make(map[string]bool)
+	for _, f := range schema {
+		if f.Name == "" || f.Name == "type" || seen[f.Name] {
+			return fmt.Errorf("designpatterns: %s schema has invalid or duplicate field %q", name, f.Name)
+		}
+		seen[f.Name] = true
+		if f.Default != nil {
+			if _, err := convert(f, f.Default); err != nil {
+				return fmt.Errorf("designpatterns: %s schema default for %q: %s", name, f.Name, err)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, dup := r.factories[name]; dup {
+		return fmt.Errorf("designpatterns: factory %q is already registered", name)
+	}
+	r.factories[name] = factoryEntry[T]{schema: schema, build: build}
+	return nil
+}
+
+// MustRegister untuk dipanggil dari init().
+func (r *FactoryRegistry[T]) MustRegister(name string, schema Schema, build func(FactoryConfig) (T, error)) {
+	if err := r.Register(name, schema, build); err != nil {
+		panic(err)
+	}
+}
+
+func (r *FactoryRegistry[T]) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build membuat instance dari satu map config.
+func (r *FactoryRegistry[T]) Build(raw map[string]any) (T, error) {
+	return r.build("", raw)
+}
+
+// BuildAll membuat instance untuk setiap elemen list. Error dari semua
+// elemen digabung dan key-nya diberi prefix path, misalnya "notifiers[2].url".
+func (r *FactoryRegistry[T]) BuildAll(path string, raws []map[string]any) ([]T, error) {
+	out := make([]T, 0, len(raws))
+	var errs []error
+	for i, raw := range raws {
+		v, err := r.build(fmt.Sprintf("%s[%d].", path, i), raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, v)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return out, nil
+}
+
+func (r *FactoryRegistry[T]) build(prefix string, raw map[string]any) (T, error) {
+	var zero T
+
+	typeName, ok := raw["type"].(string)
+	if !ok || typeName == "" {
+		return zero, &FieldError{Key: prefix + "type", Msg: "is required and must be a string"}
+	}
+
+	r.mu.RLock()
+	entry, ok := r.factories[typeName]
+	r.mu.RUnlock()
+	if !ok {
+		return zero, &FieldError{
+			Key: prefix + "type",
+			Msg: fmt.Sprintf("unknown type %q (available: %s)", typeName, strings.Join(r.Types(), ", ")),
+		}
+	}
+
+	values, errs := validate(typeName, prefix, entry.schema, raw)
+	if len(errs) > 0 {
+		return zero, errors.Join(errs...)
+	}
+
+	v, err := entry.build(FactoryConfig{values: values})
+	if err != nil {
+		return zero, fmt.Errorf("designpatterns: build %s%s: %w", prefix, typeName, err)
+	}
+	return v, nil
+}
+
+func validate(typeName, prefix string, schema Schema, raw map[string]any) (map[string]any, []error) {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, &FieldError{Type: typeName, Key: prefix + key, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	known := make(map[string]bool, len(schema))
+	values := make(map[string]any, len(schema))
+	for _, f := range schema {
+		known[f.Name] = true
+
+		v, ok := raw[f.Name]
+		if !ok || v == nil {
+			switch {
+			case f.Required:
+				fail(f.Name, "is required")
+			case f.Default != nil:
+				values[f.Name], _ = convert(f, f.Default)
+			}
+			continue
+		}
+
+		converted, err := convert(f, v)
+		if err != nil {
+			fail(f.Name, "%s", err)
+			continue
+		}
+		values[f.Name] = converted
+	}
+
+	// key yang tidak dikenal hampir selalu typo, lebih baik gagal keras
+	// daripada diam-diam memakai default
+	var unknown []string
+	for key := range raw {
+		if key != "type" && !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		if guess := closestField(key, schema); guess != "" {
+			fail(key, "unknown key, did you mean %q?", guess)
+		} else {
+			fail(key, "unknown key")
+		}
+	}
+
+	return values, errs
+}
+
+func convert(f Field, v any) (any, error) {
+	switch f.Type {
+	case FieldString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, typeError(f, v)
+		}
+		if len(f.Enum) > 0 && !contains(f.Enum, s) {
+			return nil, fmt.Errorf("must be one of %s, got %q", strings.Join(f.Enum, ", "), s)
+		}
+		return s, nil
+
+	case FieldInt:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int64:
+			return int(n), nil
+		case float64:
+			// json.Unmarshal membaca semua angka sebagai float64
+			if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32 {
+				return int(n), nil
+			}
+			return nil, fmt.Errorf("must be a whole number, got %v", n)
+		}
+		return nil, typeError(f, v)
+
+	case FieldFloat:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		}
+		return nil, typeError(f, v)
+
+	case FieldBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, typeError(f, v)
+		}
+		return b, nil
+
+	case FieldDuration:
+		switch d := v.(type) {
+		case time.Duration:
+			return d, nil
+		case string:
+			parsed, err := time.ParseDuration(d)
+			if err != nil {
+				return nil, fmt.Errorf("must be a duration like \"5s\" or \"1m30s\", got %q", d)
+			}
+			return parsed, nil
+		}
+		return nil, typeError(f, v)
+
+	case FieldStringList:
+		switch list := v.(type) {
+		case []string:
+			return append([]string(nil), list...), nil
+		case []any:
+			out := make([]string, len(list))
+			for i, item := range list {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("item %d must be a string, got %T", i, item)
+				}
+				out[i] = s
+			}
+			return out, nil
+		}
+		return nil, typeError(f, v)
+	}
+
+	return nil, fmt.Errorf("unsupported field type %s", f.Type)
+}
+
+func typeError(f Field, v any) error {
+	return fmt.Errorf("must be of type %s, got %T", f.Type, v)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// closestField mencari nama field dengan jarak edit <= 2 untuk saran typo.
+func closestField(key string, schema Schema) string {
+	best, bestDist := "", 3
+	for _, f := range schema {
+		if d := editDistance(key, f.Name); d < bestDist {
+			best, bestDist = f.Name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,174 @@
+package designpatterns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Notifier interface {
+	Notify(msg string) string
+}
+
+type emailNotifier struct {
+	host string
+	port int
+	tls  bool
+	to   []string
+}
+
+func (e emailNotifier) Notify(msg string) string {
+	return fmt.Sprintf("email %s:%d %v -> %s", e.host, e.port, e.to, msg)
+}
+
+type webhookNotifier struct {
+	url     string
+	timeout time.Duration
+	method  string
+}
+
+func (w webhookNotifier) Notify(msg string) string {
+	return fmt.Sprintf("%s %s (%s) -> %s", w.method, w.url, w.timeout, msg)
+}
+
+func newNotifierFactories(t *testing.T) *FactoryRegistry[Notifier] {
+	t.Helper()
+
+	r := NewFactoryRegistry[Notifier]()
+	r.MustRegister("email", Schema{
+		{Name: "host", Type: FieldString, Required: true},
+		{Name: "port", Type: FieldInt, Default: 587},
+		{Name: "tls", Type: FieldBool, Default: true},
+		{Name: "to", Type: FieldStringList, Required: true},
+	}, func(cfg FactoryConfig) (Notifier, error) {
+		if cfg.Int("port") <= 0 || cfg.Int("port") > 65535 {
+			return nil, fmt.Errorf("port %d out of range", cfg.Int("port"))
+		}
+		return emailNotifier{host: cfg.String("host"), port: cfg.Int("port"), tls: cfg.Bool("tls"), to: cfg.Strings("to")}, nil
+	})
+	r.MustRegister("webhook", Schema{
+		{Name: "url", Type: FieldString, Required: true},
+		{Name: "timeout", Type: FieldDuration, Default: "5s"},
+		{Name: "method", Type: FieldString, Default: "POST", Enum: []string{"POST", "PUT"}},
+	}, func(cfg FactoryConfig) (Notifier, error) {
+		return webhookNotifier{url: cfg.String("url"), timeout: cfg.Duration("timeout"), method: cfg.String("method")}, nil
+	})
+	return r
+}
+
+func TestFactoryBuildWithDefaults(t *testing.T) {
+	r := newNotifierFactories(t)
+
+	n, err := r.Build(map[string]any{"type": "email", "host": "smtp.example.com", "to": []any{"ops@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := n.(emailNotifier)
+	if email.port != 587 || !email.tls || email.to[0] != "ops@example.com" {
+		t.Fatalf("defaults not applied: %+v", email)
+	}
+
+	n, err = r.Build(map[string]any{"type": "webhook", "url": "https://hooks.example.com", "timeout": "1m30s", "method": "PUT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.Notify("deploy"); got != "PUT https://hooks.example.com (1m30s) -> deploy" {
+		t.Fatalf("got %q", got)
+	}
+
+	if types := strings.Join(r.Types(), ","); types != "email,webhook" {
+		t.Fatalf("types %s", types)
+	}
+}
+
+func TestFactoryValidationNamesKeys(t *testing.T) {
+	r := newNotifierFactories(t)
+
+	_, err := r.Build(map[string]any{"type": "email", "hots": "smtp", "port": "25", "to": []any{"a", 1}})
+	if err == nil {
+		t.Fatal("invalid config must fail")
+	}
+	for _, want := range []string{
+		`email config key "host": is required`,
+		`email config key "port": must be of type integer, got string`,
+		`email config key "to": item 1 must be a string`,
+		`email config key "hots": unknown key, did you mean "host"?`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error must contain %q:\n%v", want, err)
+		}
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Type != "email" || fe.Key != "host" {
+		t.Fatalf("errors.As must find the first FieldError, got %+v", fe)
+	}
+
+	cases := map[string]map[string]any{
+		`config key "type": is required`:                                    {"host": "x"},
+		`config key "type": unknown type "sms" (available: email, webhook)`: {"type": "sms"},
+		`webhook config key "method": must be one of POST, PUT, got "GET"`:  {"type": "webhook", "url": "u", "method": "GET"},
+		`webhook config key "timeout": must be a duration like "5s"`:        {"type": "webhook", "url": "u", "timeout": "soon"},
+		`build email: port 70000 out of range`:                              {"type": "email", "host": "h", "to": []any{}, "port": 70000},
+	}
+	for want, raw := range cases {
+		if _, err := r.Build(raw); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%v: expected %q, got %v", raw, want, err)
+		}
+	}
+}
+
+func TestFactoryBuildAllFromJSON(t *testing.T) {
+	r := newNotifierFactories(t)
+
+	// json.Unmarshal menghasilkan float64 untuk angka, sama bentuknya dengan
+	// map dari yaml kecuali tipe angkanya
+	var doc struct {
+		Notifiers []map[string]any `json:"notifiers"`
+	}
+	data := `{"notifiers": [
+		{"type": "email", "host": "smtp.example.com", "port": 465, "to": ["ops@example.com"]},
+		{"type": "webhook", "url": "https://hooks.example.com"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	notifiers, err := r.BuildAll("notifiers", doc.Notifiers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifiers) != 2 || notifiers[0].(emailNotifier).port != 465 {
+		t.Fatalf("got %+v", notifiers)
+	}
+
+	doc.Notifiers[0]["port"] = 25.5
+	delete(doc.Notifiers[1], "url")
+	_, err = r.BuildAll("notifiers", doc.Notifiers)
+	for _, want := range []string{`"notifiers[0].port": must be a whole number`, `"notifiers[1].url": is required`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error must contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestFactoryRegisterRejectsBadSchemas(t *testing.T) {
+	r := NewFactoryRegistry[Notifier]()
+	build := func(FactoryConfig) (Notifier, error) { return nil, nil }
+
+	if err := r.Register("a", Schema{{Name: "port", Type: FieldInt, Default: "x"}}, build); err == nil {
+		t.Error("default with the wrong type must be rejected")
+	}
+	if err := r.Register("b", Schema{{Name: "type", Type: FieldString}}, build); err == nil {
+		t.Error(`"type" is reserved`)
+	}
+	if err := r.Register("c", nil, build); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("c", nil, build); err == nil {
+		t.Error("duplicate name must be rejected")
+	}
+}
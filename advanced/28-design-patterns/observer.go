@@ -0,0 +1,306 @@
+package designpatterns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Observer pattern: publisher tidak tahu siapa saja yang mendengarkan event-nya.
+// Dispatcher menyimpan handler per tipe event; Subscribe dan Publish adalah
+// fungsi generic karena method Go tidak boleh punya type parameter.
+//
+//	d := NewDispatcher(DispatcherConfig{})
+//	Subscribe(d, func(ctx context.Context, e UserCreated) error { ... })
+//	Publish(ctx, d, UserCreated{Username: "bisma"})
+//
+// Tipe event dicocokkan persis dengan tipe statis saat Publish, bukan lewat
+// interface yang diimplementasikan event tersebut.
+
+var ErrDispatcherClosed = errors.New("designpatterns: dispatcher is closed")
+
+type DispatcherConfig struct {
+	Workers   int // jumlah worker untuk handler async, default 4
+	QueueSize int // kapasitas antrean async, default 64; Publish menunggu kalau penuh
+
+	// OnError menerima error dari handler async (termasuk panic), karena
+	// error itu tidak bisa dikembalikan ke Publish. Default: slog.Default().
+	OnError func(err error)
+}
+
+type Dispatcher struct {
+	cfg DispatcherConfig
+
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]*subscription
+	nextID   uint64
+	closed   bool
+
+	queue   chan job
+	quit    chan struct{}
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+type subscription struct {
+	id        uint64
+	name      string
+	eventType reflect.Type
+	priority  int
+	async     bool
+	active    atomic.Bool
+	call      func(ctx context.Context, event any) error
+}
+
+type job struct {
+	ctx   context.Context
+	sub   *subscription
+	event any
+}
+
+func NewDispatcher(cfg DispatcherConfig) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 64
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			slog.Default().Error("async event handler failed", "error", err)
+		}
+	}
+
+	d := &Dispatcher{
+		cfg:      cfg,
+		handlers: make(map[reflect.Type][]*subscription),
+		queue:    make(chan job, cfg.QueueSize),
+		quit:     make(chan struct{}),
+	}
+
+	d.workers.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+
+	for {
+		select {
+		case j := <-d.queue:
+			if err := d.run(j.ctx, j.sub, j.event); err != nil {
+				d.cfg.OnError(err)
+			}
+			d.pending.Done()
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+// HandlerError membungkus error dari satu handler beserta nama handler dan
+// tipe event-nya.
+type HandlerError struct {
+	Handler string
+	Event   string
+	Err     error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler %s for %s: %v", e.Handler, e.Event, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError adalah panic dari handler yang sudah di-recover, jadi satu
+// handler yang panic tidak menghentikan handler lain.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func (d *Dispatcher) run(ctx context.Context, sub *subscription, event any) (err error) {
+	// handler yang sudah unsubscribe tidak dijalankan walau event-nya
+	// sudah telanjur masuk antrean
+	if !sub.active.Load() {
+		return nil
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+		if err != nil {
+			err = &HandlerError{Handler: sub.name, Event: sub.eventType.String(), Err: err}
+		}
+	}()
+
+	return sub.call(ctx, event)
+}
+
+type SubscribeOption func(*subscription)
+
+// WithPriority: handler dengan prioritas lebih tinggi dipanggil lebih dulu.
+// Prioritas sama dipanggil sesuai urutan Subscribe. Default 0.
+func WithPriority(p int) SubscribeOption {
+	return func(s *subscription) { s.priority = p }
+}
+
+// Async menjalankan handler di worker pool. Publish tidak menunggu handler
+// selesai dan error-nya dikirim ke DispatcherConfig.OnError. Context yang
+// diterima handler membawa value dari Publish tapi tidak ikut dibatalkan,
+// karena request asalnya biasanya sudah selesai duluan.
+func Async() SubscribeOption {
+	return func(s *subscription) { s.async = true }
+}
+
+// Named memberi nama handler untuk pesan error. Default "#<id>".
+func Named(name string) SubscribeOption {
+	return func(s *subscription) { s.name = name }
+}
+
+// Subscription adalah token untuk berhenti berlangganan.
+type Subscription struct {
+	d   *Dispatcher
+	sub *subscription
+}
+
+// Unsubscribe aman dipanggil berkali-kali.
+func (s Subscription) Unsubscribe() {
+	if s.sub == nil || !s.sub.active.CompareAndSwap(true, false) {
+		return
+	}
+
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	subs := s.d.handlers[s.sub.eventType]
+	for i, sub := range subs {
+		if sub == s.sub {
+			// slice baru supaya snapshot yang sedang dipakai Publish tidak berubah
+			next := make([]*subscription, 0, len(subs)-1)
+			next = append(next, subs[:i]...)
+			s.d.handlers[s.sub.eventType] = append(next, subs[i+1:]...)
+			break
+		}
+	}
+}
+
+func eventType[E any]() reflect.Type {
+	return reflect.TypeOf((*E)(nil)).Elem()
+}
+
+func Subscribe[E any](d *Dispatcher, handler func(ctx context.Context, event E) error, opts ...SubscribeOption) Subscription {
+	sub := &subscription{
+		eventType: eventType[E](),
+		call: func(ctx context.Context, event any) error {
+			return handler(ctx, event.(E))
+		},
+	}
+	for _, opt := range opts {
+		opt(sub)
+	}
+	sub.active.Store(true)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nextID++
+	sub.id = d.nextID
+	if sub.name == "" {
+		sub.name = fmt.Sprintf("#%d", sub.id)
+	}
+
+	subs := append(append([]*subscription(nil), d.handlers[sub.eventType]...), sub)
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].priority > subs[j].priority })
+	d.handlers[sub.eventType] = subs
+
+	return Subscription{d: d, sub: sub}
+}
+
+// Publish mengirim event ke semua handler tipe E sesuai urutan prioritas.
+// Handler sync dijalankan langsung dan error-nya digabung dengan
+// errors.Join; handler async dimasukkan ke antrean. Kalau antrean penuh,
+// Publish menunggu (backpressure) sampai ada tempat atau ctx selesai.
+func Publish[E any](ctx context.Context, d *Dispatcher, event E) error {
+	d.mu.RLock()
+	subs := d.handlers[eventType[E]()]
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if sub.async {
+			if err := d.enqueue(ctx, job{ctx: context.WithoutCancel(ctx), sub: sub, event: event}); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := d.run(ctx, sub, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, j job) error {
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return ErrDispatcherClosed
+	}
+	d.pending.Add(1)
+	d.mu.RUnlock()
+
+	select {
+	case d.queue <- j:
+		return nil
+	case <-ctx.Done():
+		d.pending.Done()
+		return ctx.Err()
+	}
+}
+
+// Close menolak event async baru lalu menunggu antrean habis diproses.
+// Kalau ctx selesai lebih dulu, Close mengembalikan ctx.Err() dan sisa
+// antrean tetap diproses di background.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(d.quit)
+		d.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
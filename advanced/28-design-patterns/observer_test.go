@@ -0,0 +1,193 @@
+package designpatterns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type UserCreated struct {
+	Username string
+}
+
+type OrderPaid struct {
+	OrderID string
+}
+
+func TestDispatcherPriorityAndTypes(t *testing.T) {
+	d := NewDispatcher(DispatcherConfig{})
+	defer d.Close(context.Background())
+
+	var calls []string
+	record := func(name string) func(context.Context, UserCreated) error {
+		return func(_ context.Context, e UserCreated) error {
+			calls = append(calls, name+":"+e.Username)
+			return nil
+		}
+	}
+
+	Subscribe(d, record("default-1"))
+	Subscribe(d, record("audit"), WithPriority(10))
+	Subscribe(d, record("default-2"))
+	Subscribe(d, record("metrics"), WithPriority(-1))
+	Subscribe(d, func(context.Context, OrderPaid) error {
+		t.Error("handler for another event type must not be called")
+		return nil
+	})
+
+	if err := Publish(context.Background(), d, UserCreated{Username: "bisma"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "audit:bisma default-1:bisma default-2:bisma metrics:bisma"
+	if got := strings.Join(calls, " "); got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestDispatcherErrorsAndPanicIsolation(t *testing.T) {
+	d := NewDispatcher(DispatcherConfig{})
+	defer d.Close(context.Background())
+
+	errMail := errors.New("smtp down")
+	reached := false
+
+	Subscribe(d, func(context.Context, UserCreated) error { panic("boom") }, Named("audit"), WithPriority(1))
+	Subscribe(d, func(context.Context, UserCreated) error { return errMail }, Named("mail"))
+	Subscribe(d, func(context.Context, UserCreated) error { reached = true; return nil })
+
+	err := Publish(context.Background(), d, UserCreated{})
+	if !reached {
+		t.Fatal("handlers after a panic must still run")
+	}
+	if !errors.Is(err, errMail) {
+		t.Fatalf("expected joined handler error, got %v", err)
+	}
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("expected PanicError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "handler audit for designpatterns.UserCreated") {
+		t.Fatalf("error must name handler and event: %v", err)
+	}
+}
+
+func TestDispatcherUnsubscribe(t *testing.T) {
+	d := NewDispatcher(DispatcherConfig{})
+	defer d.Close(context.Background())
+
+	count := 0
+	sub := Subscribe(d, func(context.Context, UserCreated) error { count++; return nil })
+
+	Publish(context.Background(), d, UserCreated{})
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	Publish(context.Background(), d, UserCreated{})
+
+	if count != 1 {
+		t.Fatalf("expected 1 call, got %d", count)
+	}
+}
+
+func TestDispatcherAsyncDrainOnClose(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	d := NewDispatcher(DispatcherConfig{
+		Workers: 3,
+		OnError: func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	})
+
+	var delivered sync.Map
+	Subscribe(d, func(_ context.Context, e UserCreated) error {
+		time.Sleep(time.Millisecond)
+		delivered.Store(e.Username, true)
+		if e.Username == "user-7" {
+			return errors.New("rejected")
+		}
+		return nil
+	}, Async())
+
+	for i := 0; i < 20; i++ {
+		if err := Publish(context.Background(), d, UserCreated{Username: fmt.Sprint("user-", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if _, ok := delivered.Load(fmt.Sprint("user-", i)); !ok {
+			t.Fatalf("user-%d not delivered before Close returned", i)
+		}
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "rejected") {
+		t.Fatalf("expected async error via OnError, got %v", errs)
+	}
+
+	if err := Publish(context.Background(), d, UserCreated{}); !errors.Is(err, ErrDispatcherClosed) {
+		t.Fatalf("publish after close: %v", err)
+	}
+}
+
+func TestDispatcherBackpressure(t *testing.T) {
+	d := NewDispatcher(DispatcherConfig{Workers: 1, QueueSize: 1})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	Subscribe(d, func(context.Context, OrderPaid) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, Async())
+
+	ctx := context.Background()
+	Publish(ctx, d, OrderPaid{OrderID: "1"}) // diproses worker
+	<-started
+	Publish(ctx, d, OrderPaid{OrderID: "2"}) // mengisi antrean
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := Publish(timeout, d, OrderPaid{OrderID: "3"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("full queue must block until ctx is done, got %v", err)
+	}
+
+	close(release)
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Contoh fan-out saat user dibuat: audit log dijalankan sync supaya gagal
+// kalau audit gagal, notifikasi dijalankan async.
+func ExamplePublish() {
+	d := NewDispatcher(DispatcherConfig{})
+
+	Subscribe(d, func(_ context.Context, e UserCreated) error {
+		fmt.Println("audit: user created", e.Username)
+		return nil
+	}, Named("audit"), WithPriority(100))
+
+	Subscribe(d, func(_ context.Context, e UserCreated) error {
+		fmt.Println("notify: welcome email to", e.Username)
+		return nil
+	}, Named("welcome-email"), Async())
+
+	if err := Publish(context.Background(), d, UserCreated{Username: "bisma"}); err != nil {
+		fmt.Println("error:", err)
+	}
+	d.Close(context.Background())
+
+	// Output:
+	// audit: user created bisma
+	// notify: welcome email to bisma
+}
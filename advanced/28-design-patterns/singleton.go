@@ -0,0 +1,479 @@
+package designpatterns
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Lazy adalah singleton yang baru dibuat saat pertama kali dipakai. Beda
+// dengan sync.Once biasa, kalau init gagal error-nya tidak di-cache:
+// pemanggilan Get berikutnya mencoba lagi.
+type Lazy[T any] struct {
+	mu    sync.Mutex
+	done  atomic.Bool
+	init  func() (T, error)
+	value T
+}
+
+func NewLazy[T any](init func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{init: init}
+}
+
+func (l *Lazy[T]) Get() (T, error) {
+	if l.done.Load() {
+		return l.value, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.done.Load() {
+		return l.value, nil
+	}
+	v, err := l.init()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	l.value = v
+	l.done.Store(true)
+
+	return v, nil
+}
+
+// Container adalah DI container kecil: constructor didaftarkan per tipe,
+// lalu Resolve membuat objek beserta dependensinya.
+//
+//	c := NewContainer()
+//	Provide(c, Singleton, func(r Resolver) (*sql.DB, error) { return database.GetConnection(), nil })
+//	Provide(c, Transient, func(r Resolver) (*UserRepository, error) {
+//		db, err := Resolve[*sql.DB](r)
+//		...
+//	})
+//	defer c.Close()
+//
+// Semua singleton dan scoped yang mengimplementasikan io.Closer (atau punya
+// method Close() tanpa error) ditutup oleh Close dengan urutan terbalik dari
+// urutan pembuatannya, jadi dependensi ditutup paling akhir. Transient yang
+// dibuat di dalam Scope ditutup bersama scope-nya; transient yang di-resolve
+// langsung dari Container (termasuk yang dipakai constructor singleton)
+// menjadi milik pemanggil dan harus ditutup sendiri, supaya container tidak
+// menumpuk referensi ke setiap objek yang pernah dibuat.
+//
+// Container tidak dikunci selama constructor berjalan: singleton dan scoped
+// punya status in-flight sendiri per tipe, jadi constructor yang lambat
+// hanya menahan Resolve untuk tipe yang sama. Constructor harus me-resolve
+// dependensi lewat Resolver yang diterimanya, bukan lewat *Container atau
+// *Scope. Hanya Resolver yang membawa rantai dependensi untuk mendeteksi
+// siklus; me-resolve singleton yang sedang dibuat lewat *Container dari
+// constructor-nya sendiri akan menunggu selamanya, sama seperti sync.Once.
+
+type Lifetime int
+
+const (
+	// Singleton dibuat sekali per container.
+	Singleton Lifetime = iota
+	// Transient dibuat baru setiap kali di-resolve.
+	Transient
+	// Scoped dibuat sekali per Scope, misalnya per request HTTP.
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	}
+	return fmt.Sprintf("Lifetime(%d)", int(l))
+}
+
+var (
+	ErrNotRegistered   = errors.New("designpatterns: type is not registered")
+	ErrScopeRequired   = errors.New("designpatterns: scoped type resolved outside a scope")
+	ErrContainerClosed = errors.New("designpatterns: container is closed")
+)
+
+// CycleError berisi rantai dependensi yang melingkar, misalnya
+// *Service -> *Repository -> *Service.
+type CycleError struct {
+	Path []reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	names := make([]string, len(e.Path))
+	for i, t := range e.Path {
+		names[i] = t.String()
+	}
+	return "designpatterns: dependency cycle: " + strings.Join(names, " -> ")
+}
+
+// Resolver diterima constructor untuk mengambil dependensinya lewat
+// Resolve. Resolver dari constructor hanya valid selama constructor itu
+// berjalan, jangan disimpan.
+type Resolver interface {
+	resolve(t reflect.Type) (any, error)
+}
+
+type provider struct {
+	lifetime Lifetime
+	ctor     func(r Resolver) (any, error)
+	// value untuk ProvideValue; objek ini bukan buatan container jadi
+	// tidak ikut ditutup.
+	value    any
+	hasValue bool
+}
+
+// instances menyimpan objek yang dibuat di container (singleton) atau di
+// satu scope (scoped). created hanya berisi objek yang perlu ditutup, urut
+// sesuai pembuatannya untuk Close.
+type instances struct {
+	mu      sync.Mutex
+	entries map[reflect.Type]*entry
+	created []any
+	closed  bool
+}
+
+// entry adalah satu singleton atau scoped. done ditutup setelah constructor
+// selesai; owner adalah rantai resolve yang sedang membuatnya.
+type entry struct {
+	done  chan struct{}
+	owner *chain
+	value any
+	err   error
+}
+
+// chain adalah satu Resolve dari luar beserta semua resolve bersarang di
+// dalam constructor-nya. waiting berisi entry milik rantai lain yang sedang
+// ditunggu, dipakai untuk mendeteksi siklus antar goroutine.
+type chain struct {
+	waiting *entry
+}
+
+func newInstances() *instances {
+	return &instances{entries: make(map[reflect.Type]*entry)}
+}
+
+// track mencatat v untuk Close. Kalau instances sudah ditutup selagi
+// constructor berjalan, v langsung ditutup.
+func (in *instances) track(v any) error {
+	switch v.(type) {
+	case io.Closer, interface{ Close() }:
+	default:
+		return nil
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if in.closed {
+		closeValue(v)
+		return ErrContainerClosed
+	}
+	in.created = append(in.created, v)
+	return nil
+}
+
+func (in *instances) close() error {
+	in.mu.Lock()
+	if in.closed {
+		in.mu.Unlock()
+		return nil
+	}
+	in.closed = true
+	created := in.created
+	in.created = nil
+	in.mu.Unlock()
+
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := closeValue(created[i]); err != nil {
+			errs = append(errs, fmt.Errorf("close %T: %w", created[i], err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func closeValue(v any) error {
+	switch v := v.(type) {
+	case io.Closer:
+		return v.Close()
+	case interface{ Close() }:
+		v.Close()
+	}
+	return nil
+}
+
+func (in *instances) isClosed() bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.closed
+}
+
+type Container struct {
+	// mu hanya menjaga providers, tidak pernah dipegang selama constructor
+	// berjalan
+	mu        sync.RWMutex
+	providers map[reflect.Type]*provider
+	root      *instances
+
+	// waitMu menjaga chain.waiting dan penutupan entry.done supaya
+	// pengecekan siklus melihat graf tunggu yang konsisten
+	waitMu sync.Mutex
+}
+
+func NewContainer() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+		root:      newInstances(),
+	}
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (c *Container) register(t reflect.Type, p *provider) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.root.isClosed() {
+		return ErrContainerClosed
+	}
+	if _, ok := c.providers[t]; ok {
+		return fmt.Errorf("designpatterns: %s is already registered", t)
+	}
+	c.providers[t] = p
+	return nil
+}
+
+func (c *Container) provider(t reflect.Type) (*provider, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, ok := c.providers[t]
+	return p, ok
+}
+
+// Provide mendaftarkan constructor untuk tipe T.
+func Provide[T any](c *Container, lifetime Lifetime, ctor func(r Resolver) (T, error)) error {
+	if lifetime < Singleton || lifetime > Scoped {
+		return fmt.Errorf("designpatterns: invalid lifetime %s", lifetime)
+	}
+
+	return c.register(typeOf[T](), &provider{
+		lifetime: lifetime,
+		ctor: func(r Resolver) (any, error) {
+			return ctor(r)
+		},
+	})
+}
+
+// ProvideValue mendaftarkan objek yang sudah jadi, misalnya config, sebagai
+// singleton. Objek ini tidak ditutup oleh container.
+func ProvideValue[T any](c *Container, value T) error {
+	return c.register(typeOf[T](), &provider{lifetime: Singleton, value: value, hasValue: true})
+}
+
+func Resolve[T any](r Resolver) (T, error) {
+	v, err := r.resolve(typeOf[T]())
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// MustResolve untuk wiring di main, panic kalau gagal.
+func MustResolve[T any](r Resolver) T {
+	v, err := Resolve[T](r)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (c *Container) resolve(t reflect.Type) (any, error) {
+	if c.root.isClosed() {
+		return nil, ErrContainerClosed
+	}
+	return (&resolution{c: c, chain: &chain{}}).resolve(t)
+}
+
+// NewScope membuat scope baru. Tutup dengan Scope.Close setelah selesai.
+func (c *Container) NewScope() *Scope {
+	return &Scope{c: c, inst: newInstances()}
+}
+
+// Close menutup semua singleton. Transient dari container milik pemanggil,
+// dan scope yang masih terbuka harus ditutup sendiri.
+func (c *Container) Close() error {
+	return c.root.close()
+}
+
+type Scope struct {
+	c    *Container
+	inst *instances
+}
+
+func (s *Scope) resolve(t reflect.Type) (any, error) {
+	if s.c.root.isClosed() {
+		return nil, ErrContainerClosed
+	}
+	if s.inst.isClosed() {
+		return nil, errors.New("designpatterns: scope is closed")
+	}
+	return (&resolution{c: s.c, chain: &chain{}, scope: s.inst}).resolve(t)
+}
+
+// Close menutup objek scoped dan transient yang dibuat di scope ini.
+func (s *Scope) Close() error {
+	return s.inst.close()
+}
+
+// resolution adalah Resolver yang diberikan ke constructor. Ia membawa path
+// tipe yang sedang dibuat untuk mendeteksi dependensi melingkar.
+type resolution struct {
+	c     *Container
+	chain *chain
+	scope *instances // nil kalau sedang membuat singleton
+	path  []reflect.Type
+}
+
+func (r *resolution) resolve(t reflect.Type) (any, error) {
+	for i, seen := range r.path {
+		if seen == t {
+			cycle := append(append([]reflect.Type(nil), r.path[i:]...), t)
+			return nil, &CycleError{Path: cycle}
+		}
+	}
+
+	p, ok := r.c.provider(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegistered, t)
+	}
+	if p.hasValue {
+		return p.value, nil
+	}
+
+	switch p.lifetime {
+	case Singleton:
+		// singleton hanya boleh bergantung pada singleton/transient, jadi
+		// dependensinya di-resolve tanpa scope
+		return r.shared(t, p, r.c.root, nil)
+
+	case Scoped:
+		if r.scope == nil {
+			return nil, r.scopeError(t)
+		}
+		return r.shared(t, p, r.scope, r.scope)
+
+	default:
+		// tanpa scope, transient milik pemanggil dan tidak dicatat
+		return r.construct(t, p, r.scope, r.scope)
+	}
+}
+
+// shared membuat singleton atau scoped sekali per instances. Resolve lain
+// untuk tipe yang sama menunggu entry-nya; constructor yang gagal tidak
+// di-cache sehingga resolve berikutnya mencoba lagi.
+func (r *resolution) shared(t reflect.Type, p *provider, in *instances, scope *instances) (any, error) {
+	in.mu.Lock()
+	if e, ok := in.entries[t]; ok {
+		in.mu.Unlock()
+		return r.wait(t, e)
+	}
+	if in.closed {
+		in.mu.Unlock()
+		return nil, ErrContainerClosed
+	}
+	e := &entry{done: make(chan struct{}), owner: r.chain}
+	in.entries[t] = e
+	in.mu.Unlock()
+
+	v, err := r.construct(t, p, scope, in)
+	if err != nil {
+		in.mu.Lock()
+		delete(in.entries, t)
+		in.mu.Unlock()
+	}
+
+	r.c.waitMu.Lock()
+	e.value, e.err = v, err
+	close(e.done)
+	r.c.waitMu.Unlock()
+
+	return v, err
+}
+
+// wait menunggu entry yang sedang dibuat rantai lain. Kalau rantai itu
+// (langsung atau lewat rantai lain) sedang menunggu rantai ini, keduanya
+// tidak akan pernah selesai: itu siklus dependensi antar goroutine.
+func (r *resolution) wait(t reflect.Type, e *entry) (any, error) {
+	r.c.waitMu.Lock()
+	select {
+	case <-e.done:
+		r.c.waitMu.Unlock()
+		return e.value, e.err
+	default:
+	}
+	for owner := e.owner; owner != nil; {
+		if owner == r.chain {
+			r.c.waitMu.Unlock()
+			cycle := append(append([]reflect.Type(nil), r.path...), t)
+			return nil, &CycleError{Path: cycle}
+		}
+		if owner.waiting == nil {
+			break
+		}
+		owner = owner.waiting.owner
+	}
+	r.chain.waiting = e
+	r.c.waitMu.Unlock()
+
+	<-e.done
+
+	r.c.waitMu.Lock()
+	r.chain.waiting = nil
+	r.c.waitMu.Unlock()
+
+	return e.value, e.err
+}
+
+func (r *resolution) construct(t reflect.Type, p *provider, scope, owner *instances) (any, error) {
+	child := &resolution{c: r.c, chain: r.chain, scope: scope, path: append(append([]reflect.Type(nil), r.path...), t)}
+
+	v, err := p.ctor(child)
+	if err != nil {
+		var cycle *CycleError
+		if errors.As(err, &cycle) || errors.Is(err, ErrScopeRequired) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("designpatterns: construct %s: %w", t, err)
+	}
+
+	if owner != nil {
+		if err := owner.track(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (r *resolution) scopeError(t reflect.Type) error {
+	if len(r.path) == 0 {
+		return fmt.Errorf("%w: %s", ErrScopeRequired, t)
+	}
+	// singleton yang memegang objek scoped akan memakai objek itu selamanya
+	return fmt.Errorf("%w: %s needed by %s", ErrScopeRequired, t, r.path[len(r.path)-1])
+}
@@ -0,0 +1,358 @@
+package designpatterns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLazyRetriesAfterError(t *testing.T) {
+	var calls atomic.Int32
+	lazy := NewLazy(func() (string, error) {
+		if calls.Add(1) == 1 {
+			return "", errors.New("db not ready")
+		}
+		return "connected", nil
+	})
+
+	if _, err := lazy.Get(); err == nil {
+		t.Fatal("first call must return the init error")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := lazy.Get(); v != "connected" || err != nil {
+				t.Errorf("got %q %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 2 {
+		t.Fatalf("init must run once after success, ran %d times", calls.Load())
+	}
+}
+
+type Config struct{ DSN string }
+
+type closeLog struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (l *closeLog) add(name string) {
+	l.mu.Lock()
+	l.order = append(l.order, name)
+	l.mu.Unlock()
+}
+
+type fakeDB struct {
+	dsn string
+	log *closeLog
+}
+
+func (db *fakeDB) Close() error {
+	db.log.add("db")
+	return nil
+}
+
+type UserRepo struct {
+	db  *fakeDB
+	log *closeLog
+}
+
+func (r *UserRepo) Close() { r.log.add("repo") }
+
+type RequestContext struct {
+	id  int
+	log *closeLog
+}
+
+func (rc *RequestContext) Close() error {
+	rc.log.add(fmt.Sprint("request-", rc.id))
+	return nil
+}
+
+type UserService struct {
+	repo *UserRepo
+	req  *RequestContext
+}
+
+func newTestContainer(t *testing.T, log *closeLog) *Container {
+	t.Helper()
+
+	c := NewContainer()
+	var requests atomic.Int32
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(ProvideValue(c, Config{DSN: "bisma:bisma@tcp(127.0.0.1:4000)/main_database"}))
+	must(Provide(c, Singleton, func(r Resolver) (*fakeDB, error) {
+		cfg, err := Resolve[Config](r)
+		if err != nil {
+			return nil, err
+		}
+		return &fakeDB{dsn: cfg.DSN, log: log}, nil
+	}))
+	must(Provide(c, Transient, func(r Resolver) (*UserRepo, error) {
+		db, err := Resolve[*fakeDB](r)
+		return &UserRepo{db: db, log: log}, err
+	}))
+	must(Provide(c, Scoped, func(r Resolver) (*RequestContext, error) {
+		return &RequestContext{id: int(requests.Add(1)), log: log}, nil
+	}))
+	must(Provide(c, Transient, func(r Resolver) (*UserService, error) {
+		repo, err := Resolve[*UserRepo](r)
+		if err != nil {
+			return nil, err
+		}
+		req, err := Resolve[*RequestContext](r)
+		return &UserService{repo: repo, req: req}, err
+	}))
+
+	return c
+}
+
+func TestContainerLifetimes(t *testing.T) {
+	c := newTestContainer(t, &closeLog{})
+
+	db1 := MustResolve[*fakeDB](c)
+	db2 := MustResolve[*fakeDB](c)
+	if db1 != db2 || db1.dsn == "" {
+		t.Fatal("singleton must be created once with its config")
+	}
+
+	if MustResolve[*UserRepo](c) == MustResolve[*UserRepo](c) {
+		t.Fatal("transient must be created on every resolve")
+	}
+
+	s1, s2 := c.NewScope(), c.NewScope()
+	a := MustResolve[*UserService](s1)
+	b := MustResolve[*UserService](s1)
+	other := MustResolve[*UserService](s2)
+	if a == b || a.req != b.req {
+		t.Fatal("scoped dependency must be shared inside one scope")
+	}
+	if a.req == other.req || a.repo.db != other.repo.db {
+		t.Fatal("scopes must not share scoped instances but share singletons")
+	}
+
+	if _, err := Resolve[*UserService](c); !errors.Is(err, ErrScopeRequired) {
+		t.Fatalf("scoped dependency from root: %v", err)
+	}
+	if _, err := Resolve[*strings.Builder](c); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("unregistered type: %v", err)
+	}
+	if err := Provide(c, Singleton, func(Resolver) (*fakeDB, error) { return nil, nil }); err == nil {
+		t.Fatal("duplicate registration must fail")
+	}
+}
+
+func TestContainerConcurrentSingleton(t *testing.T) {
+	c := NewContainer()
+	var created atomic.Int32
+	Provide(c, Singleton, func(Resolver) (*fakeDB, error) {
+		created.Add(1)
+		return &fakeDB{log: &closeLog{}}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			MustResolve[*fakeDB](c)
+		}()
+	}
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Fatalf("singleton created %d times", created.Load())
+	}
+}
+
+type ServiceA struct{}
+type ServiceB struct{}
+type ServiceC struct{}
+
+func TestContainerCycle(t *testing.T) {
+	c := NewContainer()
+	Provide(c, Singleton, func(r Resolver) (*ServiceA, error) {
+		_, err := Resolve[*ServiceB](r)
+		return &ServiceA{}, err
+	})
+	Provide(c, Transient, func(r Resolver) (*ServiceB, error) {
+		_, err := Resolve[*ServiceC](r)
+		return &ServiceB{}, err
+	})
+	Provide(c, Transient, func(r Resolver) (*ServiceC, error) {
+		_, err := Resolve[*ServiceB](r)
+		return &ServiceC{}, err
+	})
+
+	_, err := Resolve[*ServiceA](c)
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected CycleError, got %v", err)
+	}
+
+	want := "designpatterns: dependency cycle: *designpatterns.ServiceB -> *designpatterns.ServiceC -> *designpatterns.ServiceB"
+	if err.Error() != want {
+		t.Fatalf("got  %s\nwant %s", err, want)
+	}
+}
+
+func TestContainerConstructorError(t *testing.T) {
+	c := NewContainer()
+	Provide(c, Singleton, func(Resolver) (*fakeDB, error) { return nil, errors.New("connection refused") })
+	Provide(c, Transient, func(r Resolver) (*UserRepo, error) {
+		_, err := Resolve[*fakeDB](r)
+		return nil, err
+	})
+
+	_, err := Resolve[*UserRepo](c)
+	want := "designpatterns: construct *designpatterns.UserRepo: designpatterns: construct *designpatterns.fakeDB: connection refused"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestContainerCloseOrder(t *testing.T) {
+	log := &closeLog{}
+	c := newTestContainer(t, log)
+
+	scope := c.NewScope()
+	MustResolve[*UserService](scope)
+	if err := scope.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(log.order, ","); got != "request-1,repo" {
+		t.Fatalf("scope close order: %s", got)
+	}
+
+	// transient dari container milik pemanggil
+	MustResolve[*UserRepo](c).Close()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(log.order, ","); got != "request-1,repo,repo,db" {
+		t.Fatalf("container close order: %s", got)
+	}
+
+	if _, err := Resolve[*fakeDB](c); !errors.Is(err, ErrContainerClosed) {
+		t.Fatalf("resolve after close: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal("second Close must be a no-op")
+	}
+}
+
+func TestContainerDoesNotRetainRootTransients(t *testing.T) {
+	c := newTestContainer(t, &closeLog{})
+	for i := 0; i < 100; i++ {
+		MustResolve[*UserRepo](c)
+	}
+	MustResolve[*fakeDB](c)
+
+	// hanya singleton *fakeDB yang perlu ditutup container
+	if n := len(c.root.created); n != 1 {
+		t.Fatalf("container tracks %d objects, want 1", n)
+	}
+
+	scope := c.NewScope()
+	MustResolve[*UserService](scope)
+	MustResolve[*UserService](scope)
+	// dua *UserRepo transient dan satu *RequestContext, *UserService bukan closer
+	if n := len(scope.inst.created); n != 3 {
+		t.Fatalf("scope tracks %d objects, want 3", n)
+	}
+}
+
+func TestContainerSlowConstructorDoesNotBlockOthers(t *testing.T) {
+	c := NewContainer()
+	ProvideValue(c, Config{DSN: "x"})
+	started, release := make(chan struct{}), make(chan struct{})
+	Provide(c, Singleton, func(Resolver) (*fakeDB, error) {
+		// lewat *Container untuk tipe lain tetap boleh, container tidak
+		// dikunci selama constructor berjalan
+		cfg, err := Resolve[Config](c)
+		close(started)
+		<-release
+		return &fakeDB{dsn: cfg.DSN}, err
+	})
+
+	done := make(chan *fakeDB)
+	go func() { done <- MustResolve[*fakeDB](c) }()
+	<-started
+
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := Resolve[Config](c)
+		resolved <- err
+	}()
+	select {
+	case err := <-resolved:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a slow singleton constructor blocked an unrelated Resolve")
+	}
+
+	close(release)
+	if db := <-done; db.dsn != "x" {
+		t.Fatalf("db %+v", db)
+	}
+}
+
+func TestContainerCycleAcrossGoroutines(t *testing.T) {
+	c := NewContainer()
+	// kedua constructor sudah berjalan sebelum me-resolve dependensinya,
+	// jadi A menunggu B di satu goroutine dan B menunggu A di goroutine lain
+	var ready sync.WaitGroup
+	ready.Add(2)
+	Provide(c, Singleton, func(r Resolver) (*ServiceA, error) {
+		ready.Done()
+		ready.Wait()
+		_, err := Resolve[*ServiceB](r)
+		return &ServiceA{}, err
+	})
+	Provide(c, Singleton, func(r Resolver) (*ServiceB, error) {
+		ready.Done()
+		ready.Wait()
+		_, err := Resolve[*ServiceA](r)
+		return &ServiceB{}, err
+	})
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := Resolve[*ServiceA](c)
+		errs <- err
+	}()
+	go func() {
+		_, err := Resolve[*ServiceB](c)
+		errs <- err
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			var cycle *CycleError
+			if !errors.As(err, &cycle) {
+				t.Fatalf("expected CycleError, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("singletons depending on each other deadlocked")
+		}
+	}
+}
@@ -0,0 +1,689 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWT (RFC 7519) dengan HS256, RS256 dan ES256 memakai crypto dari stdlib,
+// plus login flow dengan access token pendek dan refresh token yang disimpan
+// di server supaya bisa dicabut.
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+var (
+	ErrTokenMalformed    = errors.New("security: malformed token")
+	ErrTokenSignature    = errors.New("security: invalid token signature")
+	ErrTokenExpired      = errors.New("security: token expired")
+	ErrTokenNotYetValid  = errors.New("security: token not valid yet")
+	ErrTokenIssuer       = errors.New("security: unexpected token issuer")
+	ErrTokenAudience     = errors.New("security: unexpected token audience")
+	ErrUnknownKey        = errors.New("security: unknown signing key")
+	ErrInvalidCredential = errors.New("security: invalid username or password")
+	ErrRefreshInvalid    = errors.New("security: invalid refresh token")
+	ErrRefreshReused     = errors.New("security: refresh token reused")
+)
+
+// Audience bisa berupa string tunggal atau array di JSON.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+
+	return nil
+}
+
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims berisi registered claims ditambah roles dan atribut untuk
+// otorisasi. Waktu disimpan dalam detik Unix (NumericDate).
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	// Attributes dipakai sebagai subject.x di kondisi policy.
+	Attributes map[string]any `json:"attrs,omitempty"`
+}
+
+// Key adalah kunci penanda tangan atau pemeriksa token. Key yang hanya punya
+// public key (NewVerificationKey) bisa memverifikasi tapi tidak bisa sign.
+type Key struct {
+	ID        string
+	Algorithm string
+
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// NewHMACKey membuat key HS256. Secret minimal 32 byte, sesuai panjang
+// output SHA-256.
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if len(secret) < 32 {
+		return Key{}, errors.New("security: HS256 secret must be at least 32 bytes")
+	}
+	return Key{ID: id, Algorithm: HS256, secret: append([]byte(nil), secret...)}, nil
+}
+
+func NewRSAKey(id string, priv *rsa.PrivateKey) (Key, error) {
+	if priv.N.BitLen() < 2048 {
+		return Key{}, errors.New("security: RS256 key must be at least 2048 bits")
+	}
+	return Key{ID: id, Algorithm: RS256, private: priv, public: &priv.PublicKey}, nil
+}
+
+func NewECDSAKey(id string, priv *ecdsa.PrivateKey) (Key, error) {
+	if priv.Curve != elliptic.P256() {
+		return Key{}, errors.New("security: ES256 requires a P-256 key")
+	}
+	return Key{ID: id, Algorithm: ES256, private: priv, public: &priv.PublicKey}, nil
+}
+
+// NewVerificationKey membuat key dari public key saja, misalnya key milik
+// service lain yang mengeluarkan token.
+func NewVerificationKey(id string, pub crypto.PublicKey) (Key, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return Key{ID: id, Algorithm: RS256, public: pub}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return Key{}, errors.New("security: ES256 requires a P-256 key")
+		}
+		return Key{ID: id, Algorithm: ES256, public: pub}, nil
+	default:
+		return Key{}, fmt.Errorf("security: unsupported public key %T", pub)
+	}
+}
+
+func (k Key) sign(input []byte) ([]byte, error) {
+	digest := sha256.Sum256(input)
+
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		if k.private == nil {
+			return nil, fmt.Errorf("security: key %q cannot sign", k.ID)
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k.private.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case ES256:
+		if k.private == nil {
+			return nil, fmt.Errorf("security: key %q cannot sign", k.ID)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k.private.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS memakai r||s dengan panjang tetap, bukan ASN.1 DER
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	}
+
+	return nil, fmt.Errorf("security: unsupported algorithm %q", k.Algorithm)
+}
+
+func (k Key) verify(input, sig []byte) bool {
+	digest := sha256.Sum256(input)
+
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case RS256:
+		pub, ok := k.public.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case ES256:
+		pub, ok := k.public.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	}
+
+	return false
+}
+
+// KeySet menyimpan key berdasarkan kid. Rotasi dilakukan dengan Add key
+// baru lalu SetCurrent; token lama tetap valid sampai key lamanya di-Remove.
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]Key
+	current string
+}
+
+// NewKeySet membuat key set. Key pertama menjadi key aktif untuk sign.
+func NewKeySet(keys ...Key) *KeySet {
+	ks := &KeySet{keys: make(map[string]Key)}
+	for _, k := range keys {
+		ks.Add(k)
+	}
+
+	return ks
+}
+
+func (ks *KeySet) Add(k Key) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.keys[k.ID] = k
+	if ks.current == "" && (k.secret != nil || k.private != nil) {
+		ks.current = k.ID
+	}
+}
+
+func (ks *KeySet) SetCurrent(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	k, ok := ks.keys[kid]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if k.secret == nil && k.private == nil {
+		return fmt.Errorf("security: key %q cannot sign", kid)
+	}
+	ks.current = kid
+
+	return nil
+}
+
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	delete(ks.keys, kid)
+	if ks.current == kid {
+		ks.current = ""
+	}
+}
+
+func (ks *KeySet) get(kid string) (Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	k, ok := ks.keys[kid]
+	return k, ok
+}
+
+func (ks *KeySet) signingKey() (Key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if ks.current == "" {
+		return Key{}, errors.New("security: key set has no signing key")
+	}
+	return ks.keys[ks.current], nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// SignToken menandatangani claims memakai key aktif di ks.
+func SignToken(ks *KeySet, claims Claims) (string, error) {
+	key, err := ks.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	header, _ := json.Marshal(jwtHeader{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sig, err := key.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+type ValidationOptions struct {
+	Issuer   string // kosong berarti tidak dicek
+	Audience string // kosong berarti tidak dicek
+	// Leeway toleransi selisih jam antar server untuk exp dan nbf.
+	Leeway time.Duration
+	Now    func() time.Time
+}
+
+// ParseToken memverifikasi tanda tangan lalu memvalidasi claims. Algoritma
+// diambil dari key (berdasarkan kid), bukan dari header token, jadi token
+// dengan alg "none" atau alg yang ditukar selalu ditolak.
+func ParseToken(ks *KeySet, token string, opts ValidationOptions) (Claims, error) {
+	var claims Claims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrTokenMalformed
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims, err
+	}
+
+	key, ok := ks.get(header.KeyID)
+	if !ok {
+		return claims, fmt.Errorf("%w: %q", ErrUnknownKey, header.KeyID)
+	}
+	if header.Algorithm != key.Algorithm {
+		return claims, ErrTokenSignature
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return claims, ErrTokenMalformed
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return claims, ErrTokenSignature
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return claims, err
+	}
+
+	return claims, validateClaims(claims, opts)
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := b64.DecodeString(seg)
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	}
+
+	return nil
+}
+
+func validateClaims(c Claims, opts ValidationOptions) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	leeway := int64(opts.Leeway / time.Second)
+
+	if c.ExpiresAt != 0 && now.Unix() > c.ExpiresAt+leeway {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != 0 && now.Unix() < c.NotBefore-leeway {
+		return ErrTokenNotYetValid
+	}
+	if opts.Issuer != "" && c.Issuer != opts.Issuer {
+		return ErrTokenIssuer
+	}
+	if opts.Audience != "" && !c.Audience.Contains(opts.Audience) {
+		return ErrTokenAudience
+	}
+
+	return nil
+}
+
+// Principal adalah identitas yang sudah terautentikasi.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Claims  Claims
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// RefreshToken adalah catatan refresh token di server. Token aslinya tidak
+// disimpan, hanya hash-nya (ID). Family mengikat semua token hasil rotasi
+// dari satu login.
+type RefreshToken struct {
+	ID        string
+	Family    string
+	Subject   string
+	Roles     []string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+}
+
+type RefreshStore interface {
+	Save(ctx context.Context, t RefreshToken) error
+	// Consume menandai token dicabut dan mengembalikan isinya sebelum
+	// dicabut, dalam satu langkah atomik supaya dua request refresh yang
+	// bersamaan tidak sama-sama lolos. ErrRefreshInvalid kalau tidak ada.
+	Consume(ctx context.Context, id string) (RefreshToken, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeFamily(ctx context.Context, family string) error
+	RevokeSubject(ctx context.Context, subject string) error
+}
+
+// MemoryRefreshStore cukup untuk satu instance; untuk banyak instance
+// implementasikan RefreshStore di atas database.
+type MemoryRefreshStore struct {
+	mu     sync.Mutex
+	tokens map[string]RefreshToken
+}
+
+func NewMemoryRefreshStore() *MemoryRefreshStore {
+	return &MemoryRefreshStore{tokens: make(map[string]RefreshToken)}
+}
+
+func (s *MemoryRefreshStore) Save(_ context.Context, t RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[t.ID] = t
+	return nil
+}
+
+func (s *MemoryRefreshStore) Consume(_ context.Context, id string) (RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[id]
+	if !ok {
+		return RefreshToken{}, ErrRefreshInvalid
+	}
+	revoked := t
+	revoked.Revoked = true
+	s.tokens[id] = revoked
+
+	return t, nil
+}
+
+func (s *MemoryRefreshStore) Revoke(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tokens[id]; ok {
+		t.Revoked = true
+		s.tokens[id] = t
+	}
+	return nil
+}
+
+func (s *MemoryRefreshStore) RevokeFamily(_ context.Context, family string) error {
+	return s.revokeWhere(func(t RefreshToken) bool { return t.Family == family })
+}
+
+func (s *MemoryRefreshStore) RevokeSubject(_ context.Context, subject string) error {
+	return s.revokeWhere(func(t RefreshToken) bool { return t.Subject == subject })
+}
+
+func (s *MemoryRefreshStore) revokeWhere(match func(RefreshToken) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, t := range s.tokens {
+		if match(t) {
+			t.Revoked = true
+			s.tokens[id] = t
+		}
+	}
+	return nil
+}
+
+// PasswordVerifier memeriksa username dan password, misalnya dengan mencari
+// entity.User di database lalu membandingkan hash password-nya. Kembalikan
+// ErrInvalidCredential kalau salah.
+type PasswordVerifier func(ctx context.Context, username, password string) (Principal, error)
+
+type AuthConfig struct {
+	Keys     *KeySet
+	Issuer   string
+	Audience string
+
+	AccessTTL  time.Duration // default 15 menit
+	RefreshTTL time.Duration // default 7 hari
+	Leeway     time.Duration // default 30 detik
+
+	Verify  PasswordVerifier
+	Refresh RefreshStore // default MemoryRefreshStore
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type Authenticator struct {
+	cfg AuthConfig
+	now func() time.Time
+}
+
+func NewAuthenticator(cfg AuthConfig) (*Authenticator, error) {
+	if cfg.Keys == nil {
+		return nil, errors.New("security: AuthConfig.Keys is required")
+	}
+	if cfg.Verify == nil {
+		return nil, errors.New("security: AuthConfig.Verify is required")
+	}
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = 15 * time.Minute
+	}
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = 7 * 24 * time.Hour
+	}
+	if cfg.Leeway == 0 {
+		cfg.Leeway = 30 * time.Second
+	}
+	if cfg.Refresh == nil {
+		cfg.Refresh = NewMemoryRefreshStore()
+	}
+
+	return &Authenticator{cfg: cfg, now: time.Now}, nil
+}
+
+// Login memeriksa kredensial lalu mengeluarkan access token dan refresh
+// token dari family baru.
+func (a *Authenticator) Login(ctx context.Context, username, password string) (TokenPair, error) {
+	p, err := a.cfg.Verify(ctx, username, password)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return a.issue(ctx, p.Subject, p.Roles, randomToken(16))
+}
+
+// Refresh menukar refresh token dengan pasangan token baru. Token lama
+// langsung dicabut (rotasi). Kalau token yang sudah dicabut dipakai lagi,
+// kemungkinan besar token itu dicuri, jadi seluruh family ikut dicabut.
+func (a *Authenticator) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	rt, err := a.cfg.Refresh.Consume(ctx, hashToken(refreshToken))
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if rt.Revoked {
+		if err := a.cfg.Refresh.RevokeFamily(ctx, rt.Family); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrRefreshReused
+	}
+	if a.now().After(rt.ExpiresAt) {
+		return TokenPair{}, ErrRefreshInvalid
+	}
+
+	return a.issue(ctx, rt.Subject, rt.Roles, rt.Family)
+}
+
+// Logout mencabut satu refresh token (satu sesi/perangkat).
+func (a *Authenticator) Logout(ctx context.Context, refreshToken string) error {
+	return a.cfg.Refresh.Revoke(ctx, hashToken(refreshToken))
+}
+
+// LogoutAll mencabut semua refresh token milik subject. Access token yang
+// sudah keluar tetap berlaku sampai exp, karena itu AccessTTL dibuat pendek.
+func (a *Authenticator) LogoutAll(ctx context.Context, subject string) error {
+	return a.cfg.Refresh.RevokeSubject(ctx, subject)
+}
+
+func (a *Authenticator) issue(ctx context.Context, subject string, roles []string, family string) (TokenPair, error) {
+	now := a.now()
+
+	claims := Claims{
+		Issuer:    a.cfg.Issuer,
+		Subject:   subject,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(a.cfg.AccessTTL).Unix(),
+		ID:        randomToken(16),
+		Roles:     roles,
+	}
+	if a.cfg.Audience != "" {
+		claims.Audience = Audience{a.cfg.Audience}
+	}
+
+	access, err := SignToken(a.cfg.Keys, claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refresh := randomToken(32)
+	err = a.cfg.Refresh.Save(ctx, RefreshToken{
+		ID:        hashToken(refresh),
+		Family:    family,
+		Subject:   subject,
+		Roles:     roles,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.cfg.RefreshTTL),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(a.cfg.AccessTTL / time.Second),
+	}, nil
+}
+
+// Authenticate memverifikasi access token dan mengembalikan principal.
+func (a *Authenticator) Authenticate(token string) (*Principal, error) {
+	claims, err := ParseToken(a.cfg.Keys, token, ValidationOptions{
+		Issuer:   a.cfg.Issuer,
+		Audience: a.cfg.Audience,
+		Leeway:   a.cfg.Leeway,
+		Now:      a.now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{Subject: claims.Subject, Roles: claims.Roles, Claims: claims}, nil
+}
+
+// Middleware mewajibkan header "Authorization: Bearer <token>" dan menaruh
+// principal di context request.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		p, err := a.Authenticate(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithPrincipal(r.Context(), p)))
+	})
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b64.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
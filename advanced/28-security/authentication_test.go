@@ -0,0 +1,278 @@
+package security
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func mustKey(t *testing.T) func(Key, error) Key {
+	return func(k Key, err error) Key {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+}
+
+func TestSignAndParseAllAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	keys := []Key{
+		mustKey(t)(NewHMACKey("hmac", testSecret)),
+		mustKey(t)(NewRSAKey("rsa", rsaKey)),
+		mustKey(t)(NewECDSAKey("ec", ecKey)),
+	}
+
+	for _, key := range keys {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			ks := NewKeySet(key)
+			token, err := SignToken(ks, Claims{Subject: "bisma", Issuer: "go-journey", Audience: Audience{"api"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := ParseToken(ks, token, ValidationOptions{Issuer: "go-journey", Audience: "api"})
+			if err != nil || claims.Subject != "bisma" {
+				t.Fatalf("parse: %+v %v", claims, err)
+			}
+
+			tampered := token[:len(token)-4] + "AAAA"
+			if _, err := ParseToken(ks, tampered, ValidationOptions{}); !errors.Is(err, ErrTokenSignature) {
+				t.Fatalf("tampered signature: %v", err)
+			}
+		})
+	}
+
+	// verifikasi hanya dengan public key
+	verifier := NewKeySet(mustKey(t)(NewVerificationKey("rsa", &rsaKey.PublicKey)))
+	token, _ := SignToken(NewKeySet(keys[1]), Claims{Subject: "svc"})
+	if _, err := ParseToken(verifier, token, ValidationOptions{}); err != nil {
+		t.Fatalf("public key must verify: %v", err)
+	}
+	if _, err := SignToken(verifier, Claims{}); err == nil {
+		t.Fatal("public-only key set must not sign")
+	}
+}
+
+func TestRejectsAlgorithmConfusion(t *testing.T) {
+	ks := NewKeySet(mustKey(t)(NewHMACKey("k1", testSecret)))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"k1"}`)) + "." + payload + "."
+	if _, err := ParseToken(ks, none, ValidationOptions{}); !errors.Is(err, ErrTokenSignature) {
+		t.Fatalf("alg none must be rejected, got %v", err)
+	}
+
+	if _, err := ParseToken(ks, "not-a-token", ValidationOptions{}); !errors.Is(err, ErrTokenMalformed) {
+		t.Fatalf("expected malformed, got %v", err)
+	}
+}
+
+func TestClaimsValidation(t *testing.T) {
+	ks := NewKeySet(mustKey(t)(NewHMACKey("k1", testSecret)))
+	now := time.Unix(1_700_000_000, 0)
+	opts := ValidationOptions{Issuer: "go-journey", Audience: "api", Leeway: 30 * time.Second, Now: func() time.Time { return now }}
+
+	cases := []struct {
+		name   string
+		claims Claims
+		want   error
+	}{
+		{"valid", Claims{Issuer: "go-journey", Audience: Audience{"web", "api"}, ExpiresAt: now.Unix() + 60}, nil},
+		{"expired within leeway", Claims{Issuer: "go-journey", Audience: Audience{"api"}, ExpiresAt: now.Unix() - 10}, nil},
+		{"expired", Claims{Issuer: "go-journey", Audience: Audience{"api"}, ExpiresAt: now.Unix() - 31}, ErrTokenExpired},
+		{"not yet valid", Claims{Issuer: "go-journey", Audience: Audience{"api"}, NotBefore: now.Unix() + 60}, ErrTokenNotYetValid},
+		{"wrong issuer", Claims{Issuer: "other", Audience: Audience{"api"}}, ErrTokenIssuer},
+		{"wrong audience", Claims{Issuer: "go-journey", Audience: Audience{"web"}}, ErrTokenAudience},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			token, _ := SignToken(ks, c.claims)
+			if _, err := ParseToken(ks, token, opts); !errors.Is(err, c.want) {
+				t.Fatalf("expected %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	ks := NewKeySet(mustKey(t)(NewHMACKey("2024-01", testSecret)))
+	oldToken, _ := SignToken(ks, Claims{Subject: "a"})
+
+	ks.Add(mustKey(t)(NewHMACKey("2024-02", []byte("fedcba9876543210fedcba9876543210"))))
+	if err := ks.SetCurrent("2024-02"); err != nil {
+		t.Fatal(err)
+	}
+	newToken, _ := SignToken(ks, Claims{Subject: "b"})
+
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := ParseToken(ks, token, ValidationOptions{}); err != nil {
+			t.Fatalf("both keys must verify during rotation: %v", err)
+		}
+	}
+
+	ks.Remove("2024-01")
+	if _, err := ParseToken(ks, oldToken, ValidationOptions{}); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("token of removed key must fail, got %v", err)
+	}
+}
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+
+	a, err := NewAuthenticator(AuthConfig{
+		Keys:     NewKeySet(mustKey(t)(NewHMACKey("k1", testSecret))),
+		Issuer:   "go-journey",
+		Audience: "api",
+		Verify: func(ctx context.Context, username, password string) (Principal, error) {
+			if username != "bisma" || password != "rahasia" {
+				return Principal{}, ErrInvalidCredential
+			}
+			return Principal{Subject: username, Roles: []string{"admin"}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestLoginRefreshAndReuseDetection(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator(t)
+
+	if _, err := a.Login(ctx, "bisma", "salah"); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("expected invalid credential, got %v", err)
+	}
+
+	pair, err := a.Login(ctx, "bisma", "rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := a.Authenticate(pair.AccessToken)
+	if err != nil || p.Subject != "bisma" || !p.HasRole("admin") {
+		t.Fatalf("access token: %+v %v", p, err)
+	}
+
+	rotated, err := a.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.RefreshToken == pair.RefreshToken {
+		t.Fatal("refresh token must rotate")
+	}
+
+	// token lama dipakai lagi: seluruh family dicabut, termasuk hasil rotasi
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("expected reuse detection, got %v", err)
+	}
+	if _, err := a.Refresh(ctx, rotated.RefreshToken); !errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("rotated token must be revoked with its family, got %v", err)
+	}
+
+	if _, err := a.Refresh(ctx, "unknown"); !errors.Is(err, ErrRefreshInvalid) {
+		t.Fatalf("expected invalid refresh token, got %v", err)
+	}
+}
+
+func TestLogoutAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator(t)
+
+	first, _ := a.Login(ctx, "bisma", "rahasia")
+	second, _ := a.Login(ctx, "bisma", "rahasia")
+
+	if err := a.Logout(ctx, first.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Refresh(ctx, first.RefreshToken); err == nil {
+		t.Fatal("logged out token must not refresh")
+	}
+	if _, err := a.Refresh(ctx, second.RefreshToken); err != nil {
+		t.Fatalf("other session must keep working: %v", err)
+	}
+
+	third, _ := a.Login(ctx, "bisma", "rahasia")
+	a.now = func() time.Time { return time.Now().Add(8 * 24 * time.Hour) }
+	if _, err := a.Refresh(ctx, third.RefreshToken); !errors.Is(err, ErrRefreshInvalid) {
+		t.Fatalf("expired refresh token, got %v", err)
+	}
+	if _, err := a.Authenticate(third.AccessToken); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expired access token, got %v", err)
+	}
+}
+
+func TestConcurrentRefreshOnlyOneWins(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator(t)
+	pair, _ := a.Login(ctx, "bisma", "rahasia")
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		wins int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := a.Refresh(ctx, pair.RefreshToken); err == nil {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if wins != 1 {
+		t.Fatalf("exactly one refresh must succeed, got %d", wins)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	a := newTestAuthenticator(t)
+	pair, _ := a.Login(context.Background(), "bisma", "rahasia")
+
+	h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := PrincipalFromContext(r.Context())
+		w.Write([]byte(p.Subject))
+	}))
+
+	send := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send("Bearer " + pair.AccessToken); rec.Code != http.StatusOK || rec.Body.String() != "bisma" {
+		t.Fatalf("valid token: %d %q", rec.Code, rec.Body)
+	}
+	if rec := send(""); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("missing token: %d %v", rec.Code, rec.Header())
+	}
+	if rec := send("Bearer " + pair.AccessToken + "x"); !strings.Contains(rec.Header().Get("WWW-Authenticate"), "invalid_token") {
+		t.Fatalf("invalid token: %d %v", rec.Code, rec.Header())
+	}
+}
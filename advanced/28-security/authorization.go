@@ -0,0 +1,617 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy engine gabungan RBAC dan ABAC. Role memberi permission atas
+// resource/action, bisa mewarisi role lain, dan tiap permission boleh punya
+// kondisi atribut. Rule deny dicek lebih dulu dan selalu menang.
+//
+//	roles:
+//	  viewer:
+//	    permissions:
+//	      - resource: document
+//	        actions: [read]
+//	  editor:
+//	    inherits: [viewer]
+//	    permissions:
+//	      - resource: document
+//	        actions: [update]
+//	        when: resource.owner == subject.id
+//	deny:
+//	  - name: archived-is-readonly
+//	    resource: document
+//	    actions: [update, delete]
+//	    when: resource.status == "archived"
+//
+// Kondisi berupa perbandingan (==, !=, <, <=, >, >=, in) antara atribut
+// subject.x / resource.x dan literal, digabung dengan && dan ||. Atribut
+// yang tidak ada membuat perbandingan di permission bernilai false, tapi
+// di rule deny bernilai true: tanpa atribut engine tidak bisa membuktikan
+// bahwa rule deny tidak berlaku, jadi hasilnya fail closed.
+
+type Policy struct {
+	Roles map[string]RolePolicy `yaml:"roles"`
+	Deny  []DenyRule            `yaml:"deny"`
+}
+
+type RolePolicy struct {
+	Inherits    []string     `yaml:"inherits"`
+	Permissions []Permission `yaml:"permissions"`
+}
+
+type Permission struct {
+	Resource string   `yaml:"resource"` // tipe resource atau "*"
+	Actions  []string `yaml:"actions"`  // boleh berisi "*"
+	When     string   `yaml:"when"`
+}
+
+type DenyRule struct {
+	Name       string `yaml:"name"`
+	Permission `yaml:",inline"`
+}
+
+// Subject adalah pihak yang meminta akses.
+type Subject struct {
+	ID         string
+	Roles      []string
+	Attributes map[string]any
+}
+
+// SubjectFromPrincipal mengubah principal hasil autentikasi menjadi subject.
+// Atribut diambil dari claim "attrs" di token.
+func SubjectFromPrincipal(p *Principal) Subject {
+	return Subject{ID: p.Subject, Roles: p.Roles, Attributes: p.Claims.Attributes}
+}
+
+type Resource struct {
+	Type       string
+	ID         string
+	Attributes map[string]any
+}
+
+// Decision menjelaskan hasil Authorize, termasuk rule mana yang menentukan.
+type Decision struct {
+	Allowed bool
+	// Rule berisi "deny:<nama>" atau "role:<role>" yang cocok, kosong kalau
+	// tidak ada yang cocok (default deny).
+	Rule   string
+	Reason string
+}
+
+type Engine struct {
+	roles map[string][]grant
+	deny  []compiledRule
+}
+
+type compiledRule struct {
+	name     string
+	resource string
+	actions  map[string]bool
+	cond     *condition
+}
+
+func (r compiledRule) matches(action string, res Resource) bool {
+	return (r.resource == "*" || r.resource == res.Type) && (r.actions["*"] || r.actions[action])
+}
+
+func (r compiledRule) describe() string {
+	actions := make([]string, 0, len(r.actions))
+	for a := range r.actions {
+		actions = append(actions, a)
+	}
+	sort.Strings(actions)
+
+	s := r.resource + ":" + strings.Join(actions, ",")
+	if r.cond != nil {
+		s += " when " + r.cond.text
+	}
+	return s
+}
+
+// grant adalah permission milik sebuah role, termasuk hasil warisan.
+type grant struct {
+	role string // role yang mendefinisikan permission
+	compiledRule
+}
+
+// LoadPolicy membaca policy YAML dari file.
+func LoadPolicy(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePolicy(data)
+}
+
+func ParsePolicy(data []byte) (*Engine, error) {
+	var p Policy
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("security: parse policy: %w", err)
+	}
+
+	return NewEngine(p)
+}
+
+// NewEngine mengompilasi policy: kondisi di-parse dan warisan role
+// diratakan sekali di awal, jadi Authorize tidak perlu menelusuri ulang.
+func NewEngine(p Policy) (*Engine, error) {
+	e := &Engine{roles: make(map[string][]grant)}
+
+	var errs []error
+	own := make(map[string][]grant, len(p.Roles))
+	for name, role := range p.Roles {
+		for i, perm := range role.Permissions {
+			rule, err := compileRule(perm)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("roles.%s.permissions[%d]: %w", name, i, err))
+				continue
+			}
+			rule.name = name
+			own[name] = append(own[name], grant{role: name, compiledRule: rule})
+		}
+		for _, parent := range role.Inherits {
+			if _, ok := p.Roles[parent]; !ok {
+				errs = append(errs, fmt.Errorf("roles.%s: inherits unknown role %q", name, parent))
+			}
+		}
+	}
+
+	for i, d := range p.Deny {
+		rule, err := compileRule(d.Permission)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deny[%d]: %w", i, err))
+			continue
+		}
+		rule.name = d.Name
+		if rule.name == "" {
+			rule.name = strconv.Itoa(i)
+		}
+		e.deny = append(e.deny, rule)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("security: invalid policy: %w", errors.Join(errs...))
+	}
+
+	for name := range p.Roles {
+		grants, err := flatten(name, p.Roles, own, nil)
+		if err != nil {
+			return nil, fmt.Errorf("security: invalid policy: %w", err)
+		}
+		e.roles[name] = grants
+	}
+
+	return e, nil
+}
+
+func compileRule(p Permission) (compiledRule, error) {
+	if p.Resource == "" {
+		return compiledRule{}, errors.New("resource is required")
+	}
+	if len(p.Actions) == 0 {
+		return compiledRule{}, errors.New("actions is required")
+	}
+
+	rule := compiledRule{resource: p.Resource, actions: make(map[string]bool, len(p.Actions))}
+	for _, a := range p.Actions {
+		rule.actions[a] = true
+	}
+
+	if strings.TrimSpace(p.When) != "" {
+		cond, err := parseCondition(p.When)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		rule.cond = cond
+	}
+
+	return rule, nil
+}
+
+// flatten mengumpulkan permission role beserta semua leluhurnya. path
+// dipakai untuk mendeteksi siklus dan menampilkan jalurnya.
+func flatten(name string, roles map[string]RolePolicy, own map[string][]grant, path []string) ([]grant, error) {
+	for i, p := range path {
+		if p == name {
+			return nil, fmt.Errorf("role inheritance cycle: %s", strings.Join(append(path[i:], name), " -> "))
+		}
+	}
+	path = append(path, name)
+
+	grants := append([]grant(nil), own[name]...)
+	for _, parent := range roles[name].Inherits {
+		inherited, err := flatten(parent, roles, own, path)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, inherited...)
+	}
+
+	return grants, nil
+}
+
+// Authorize memutuskan apakah subject boleh melakukan action atas resource.
+// Error hanya dikembalikan kalau kondisi tidak bisa dievaluasi (misalnya
+// membandingkan string dengan <); hasilnya tetap deny.
+func (e *Engine) Authorize(ctx context.Context, subject Subject, action string, resource Resource) (Decision, error) {
+	if err := ctx.Err(); err != nil {
+		return Decision{Reason: "context done"}, err
+	}
+
+	for _, rule := range e.deny {
+		if !rule.matches(action, resource) {
+			continue
+		}
+		ok, err := rule.cond.eval(subject, resource, true)
+		if err != nil {
+			return Decision{Rule: "deny:" + rule.name, Reason: err.Error()}, err
+		}
+		if ok {
+			return Decision{Rule: "deny:" + rule.name, Reason: "denied by rule " + rule.name + ": " + rule.describe()}, nil
+		}
+	}
+
+	for _, role := range subject.Roles {
+		for _, g := range e.roles[role] {
+			if !g.matches(action, resource) {
+				continue
+			}
+			ok, err := g.cond.eval(subject, resource, false)
+			if err != nil {
+				return Decision{Rule: "role:" + g.role, Reason: err.Error()}, err
+			}
+			if !ok {
+				continue
+			}
+
+			reason := "allowed by role " + g.role
+			if g.role != role {
+				reason += " (inherited by " + role + ")"
+			}
+			return Decision{Allowed: true, Rule: "role:" + g.role, Reason: reason + ": " + g.describe()}, nil
+		}
+	}
+
+	return Decision{Reason: fmt.Sprintf("no permission grants %s on %s", action, resource.Type)}, nil
+}
+
+// ResourceFunc menentukan resource dari request, misalnya dengan mengambil
+// dokumen dari database berdasarkan id di path.
+type ResourceFunc func(r *http.Request) (Resource, error)
+
+// SubjectFunc menentukan subject dari principal, misalnya untuk mengisi
+// atribut seperti department dari database user.
+type SubjectFunc func(r *http.Request, p *Principal) (Subject, error)
+
+// Middleware memeriksa principal dari Authenticator.Middleware terhadap
+// action dan resource. Tanpa principal dijawab 401, ditolak dijawab 403.
+// Subject dibuat dengan SubjectFromPrincipal.
+func (e *Engine) Middleware(action string, resource ResourceFunc) func(http.Handler) http.Handler {
+	return e.MiddlewareWithSubject(action, resource, func(_ *http.Request, p *Principal) (Subject, error) {
+		return SubjectFromPrincipal(p), nil
+	})
+}
+
+// MiddlewareWithSubject sama dengan Middleware tapi subject dibuat oleh
+// subject. Error dari subject dijawab 500.
+func (e *Engine) MiddlewareWithSubject(action string, resource ResourceFunc, subject SubjectFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			res, err := resource(r)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+
+			sub, err := subject(r, p)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			decision, err := e.Authorize(r.Context(), sub, action, res)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if !decision.Allowed {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// condition adalah OR dari beberapa AND.
+type condition struct {
+	text  string
+	anyOf [][]comparison
+}
+
+type comparison struct {
+	left, right operand
+	op          string
+}
+
+type operand struct {
+	path    []string // ["resource", "owner"]
+	literal any
+}
+
+// eval mengevaluasi kondisi. missingMatches dipakai rule deny: perbandingan
+// yang salah satu atributnya tidak ada dianggap cocok.
+func (c *condition) eval(s Subject, r Resource, missingMatches bool) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+
+	for _, all := range c.anyOf {
+		ok := true
+		for _, cmp := range all {
+			matched, err := cmp.eval(s, r, missingMatches)
+			if err != nil {
+				return false, fmt.Errorf("security: condition %q: %w", c.text, err)
+			}
+			if !matched {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (o operand) value(s Subject, r Resource) any {
+	if o.path == nil {
+		return o.literal
+	}
+
+	switch o.path[0] {
+	case "subject":
+		switch o.path[1] {
+		case "id":
+			return s.ID
+		case "roles":
+			return s.Roles
+		}
+		return s.Attributes[o.path[1]]
+	default:
+		switch o.path[1] {
+		case "id":
+			return r.ID
+		case "type":
+			return r.Type
+		}
+		return r.Attributes[o.path[1]]
+	}
+}
+
+func (c comparison) eval(s Subject, r Resource, missingMatches bool) (bool, error) {
+	left, right := c.left.value(s, r), c.right.value(s, r)
+	if missingMatches && (left == nil || right == nil) {
+		return true, nil
+	}
+
+	switch c.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return left != nil && right != nil && !equal(left, right), nil
+	case "in":
+		switch list := right.(type) {
+		case []string:
+			for _, v := range list {
+				if equal(left, v) {
+					return true, nil
+				}
+			}
+		case []any:
+			for _, v := range list {
+				if equal(left, v) {
+					return true, nil
+				}
+			}
+		case nil:
+		default:
+			return false, fmt.Errorf("right side of in must be a list, got %T", right)
+		}
+		return false, nil
+	}
+
+	if left == nil || right == nil {
+		return false, nil
+	}
+	a, okA := toFloat(left)
+	b, okB := toFloat(right)
+	if !okA || !okB {
+		return false, fmt.Errorf("%s needs numbers, got %T and %T", c.op, left, right)
+	}
+
+	switch c.op {
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	default:
+		return a >= b, nil
+	}
+}
+
+// equal membandingkan angka tanpa peduli tipenya (int vs float64 dari YAML)
+// dan nilai lain secara langsung. nil tidak pernah sama dengan apa pun,
+// jadi atribut yang tidak ada tidak bisa lolos "owner == subject.id".
+func equal(a, b any) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+
+	switch a.(type) {
+	case string, bool:
+		return a == b
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func parseCondition(text string) (*condition, error) {
+	tokens, err := tokenizeCondition(text)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &condition{text: text}
+	var all []comparison
+	for i := 0; i < len(tokens); {
+		if i+3 > len(tokens) {
+			return nil, fmt.Errorf("condition %q: incomplete comparison", text)
+		}
+
+		left, err := parseOperand(tokens[i])
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: %w", text, err)
+		}
+		op := tokens[i+1]
+		switch op {
+		case "==", "!=", "<", "<=", ">", ">=", "in":
+		default:
+			return nil, fmt.Errorf("condition %q: unknown operator %q", text, op)
+		}
+		right, err := parseOperand(tokens[i+2])
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: %w", text, err)
+		}
+		all = append(all, comparison{left: left, right: right, op: op})
+		i += 3
+
+		if i == len(tokens) {
+			break
+		}
+		switch tokens[i] {
+		case "&&":
+		case "||":
+			c.anyOf = append(c.anyOf, all)
+			all = nil
+		default:
+			return nil, fmt.Errorf("condition %q: expected && or ||, got %q", text, tokens[i])
+		}
+		i++
+		if i == len(tokens) {
+			return nil, fmt.Errorf("condition %q: dangling operator", text)
+		}
+	}
+	c.anyOf = append(c.anyOf, all)
+
+	return c, nil
+}
+
+func parseOperand(tok string) (operand, error) {
+	switch {
+	case tok[0] == '"' || tok[0] == '\'':
+		return operand{literal: tok[1 : len(tok)-1]}, nil
+	case tok == "true" || tok == "false":
+		return operand{literal: tok == "true"}, nil
+	case strings.HasPrefix(tok, "subject.") || strings.HasPrefix(tok, "resource."):
+		path := strings.SplitN(tok, ".", 2)
+		if path[1] == "" || strings.Contains(path[1], ".") {
+			return operand{}, fmt.Errorf("invalid attribute %q", tok)
+		}
+		return operand{path: path}, nil
+	}
+
+	if n, err := strconv.ParseFloat(tok, 64); err == nil {
+		return operand{literal: n}, nil
+	}
+
+	return operand{}, fmt.Errorf("unknown operand %q (use subject.x, resource.x or a literal)", tok)
+}
+
+func tokenizeCondition(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("condition %q: unterminated string", s)
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			j := i + 1
+			for j < len(s) && strings.ContainsRune("=&|", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t=!<>&|\"'", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("condition %q is empty", s)
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,248 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func loadTestPolicy(t *testing.T) *Engine {
+	t.Helper()
+
+	e, err := LoadPolicy("testdata/policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestAuthorize(t *testing.T) {
+	e := loadTestPolicy(t)
+
+	doc := func(owner, status string, collaborators ...string) Resource {
+		attrs := map[string]any{"owner": owner, "status": status, "collaborators": collaborators}
+		return Resource{Type: "document", ID: "d1", Attributes: attrs}
+	}
+	invoice := Resource{Type: "invoice", Attributes: map[string]any{"amount": 2500, "department": "ops"}}
+
+	cases := []struct {
+		name    string
+		subject Subject
+		action  string
+		res     Resource
+		allowed bool
+		rule    string
+	}{
+		{"viewer reads", Subject{ID: "u1", Roles: []string{"viewer"}}, "read", doc("u2", "draft"), true, "role:viewer"},
+		{"viewer cannot update", Subject{ID: "u1", Roles: []string{"viewer"}}, "update", doc("u1", "draft"), false, ""},
+		{"editor reads via inheritance", Subject{ID: "u1", Roles: []string{"editor"}}, "read", doc("u2", "draft"), true, "role:viewer"},
+		{"owner updates", Subject{ID: "u1", Roles: []string{"editor"}}, "update", doc("u1", "draft"), true, "role:editor"},
+		{"collaborator updates", Subject{ID: "u3", Roles: []string{"editor"}}, "update", doc("u1", "draft", "u3"), true, "role:editor"},
+		{"non owner cannot update", Subject{ID: "u2", Roles: []string{"editor"}}, "update", doc("u1", "draft"), false, ""},
+		{"archived denies owner", Subject{ID: "u1", Roles: []string{"editor"}}, "update", doc("u1", "archived"), false, "deny:archived-is-readonly"},
+		{"deny beats admin", Subject{ID: "root", Roles: []string{"admin"}}, "delete", doc("u1", "archived"), false, "deny:archived-is-readonly"},
+		{"admin wildcard", Subject{ID: "root", Roles: []string{"admin"}}, "approve", invoice, true, "role:admin"},
+		{"finance within limit", Subject{ID: "f1", Roles: []string{"finance"}, Attributes: map[string]any{"department": "ops"}}, "approve", invoice, true, "role:finance"},
+		{"finance other department", Subject{ID: "f1", Roles: []string{"finance"}, Attributes: map[string]any{"department": "hr"}}, "approve", invoice, false, ""},
+		{"missing attribute never grants", Subject{ID: "u1", Roles: []string{"editor"}}, "update", Resource{Type: "document", Attributes: map[string]any{"status": "draft"}}, false, ""},
+		{"missing attribute fails closed in deny", Subject{ID: "u1", Roles: []string{"editor"}}, "update", Resource{Type: "document", Attributes: map[string]any{"owner": "u1"}}, false, "deny:archived-is-readonly"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d, err := e.Authorize(context.Background(), c.subject, c.action, c.res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Allowed != c.allowed || d.Rule != c.rule {
+				t.Fatalf("got %+v", d)
+			}
+			if d.Reason == "" {
+				t.Fatal("decision must explain itself")
+			}
+		})
+	}
+}
+
+func TestDecisionExplainsInheritance(t *testing.T) {
+	e := loadTestPolicy(t)
+
+	d, _ := e.Authorize(context.Background(), Subject{ID: "u1", Roles: []string{"editor"}}, "read", Resource{Type: "document"})
+	if d.Reason != "allowed by role viewer (inherited by editor): document:read" {
+		t.Fatalf("unexpected reason %q", d.Reason)
+	}
+}
+
+func TestConditionTypeError(t *testing.T) {
+	e := loadTestPolicy(t)
+
+	invoice := Resource{Type: "invoice", Attributes: map[string]any{"amount": "banyak", "department": "ops"}}
+	d, err := e.Authorize(context.Background(), Subject{Roles: []string{"finance"}}, "approve", invoice)
+	if err == nil || d.Allowed {
+		t.Fatalf("comparing string with <= must fail closed, got %+v %v", d, err)
+	}
+}
+
+func TestDenyNotEqualWithMissingAttribute(t *testing.T) {
+	e, err := ParsePolicy([]byte(`
+roles:
+  reader:
+    permissions:
+      - {resource: document, actions: [read]}
+deny:
+  - {name: cross-tenant, resource: "*", actions: ["*"], when: "subject.tenant != resource.tenant"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := Resource{Type: "document", Attributes: map[string]any{"tenant": "acme"}}
+	cases := []struct {
+		name    string
+		subject Subject
+		res     Resource
+		allowed bool
+	}{
+		{"same tenant", Subject{Roles: []string{"reader"}, Attributes: map[string]any{"tenant": "acme"}}, doc, true},
+		{"other tenant", Subject{Roles: []string{"reader"}, Attributes: map[string]any{"tenant": "evil"}}, doc, false},
+		{"subject without tenant", Subject{Roles: []string{"reader"}}, doc, false},
+		{"resource without tenant", Subject{Roles: []string{"reader"}, Attributes: map[string]any{"tenant": "acme"}}, Resource{Type: "document"}, false},
+	}
+
+	for _, c := range cases {
+		d, err := e.Authorize(context.Background(), c.subject, "read", c.res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Allowed != c.allowed {
+			t.Errorf("%s: got %+v", c.name, d)
+		}
+	}
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	cases := map[string]string{
+		"inherits unknown role": `
+roles:
+  editor: {inherits: [ghost]}`,
+		"role inheritance cycle": `
+roles:
+  a: {inherits: [b]}
+  b: {inherits: [a]}`,
+		"unknown operator": `
+roles:
+  a:
+    permissions:
+      - {resource: doc, actions: [read], when: "resource.x ~= 1"}`,
+		"unknown operand": `
+roles:
+  a:
+    permissions:
+      - {resource: doc, actions: [read], when: "owner == subject.id"}`,
+		"actions is required": `
+deny:
+  - {name: x, resource: doc}`,
+		"field rol not found": `
+rol: {}`,
+	}
+
+	for want, policy := range cases {
+		_, err := ParsePolicy([]byte(policy))
+		if err == nil || !strings.Contains(err.Error(), strings.TrimSpace(want)) {
+			t.Errorf("%s: got %v", want, err)
+		}
+	}
+}
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	e := loadTestPolicy(t)
+	docs := map[string]Resource{
+		"d1": {Type: "document", ID: "d1", Attributes: map[string]any{"owner": "bisma", "status": "draft"}},
+	}
+
+	h := e.Middleware("update", func(r *http.Request) (Resource, error) {
+		res, ok := docs[r.URL.Query().Get("id")]
+		if !ok {
+			return Resource{}, errors.New("not found")
+		}
+		return res, nil
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	send := func(p *Principal, id string) int {
+		req := httptest.NewRequest(http.MethodPut, "/docs?id="+id, nil)
+		if p != nil {
+			req = req.WithContext(ContextWithPrincipal(req.Context(), p))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	owner := &Principal{Subject: "bisma", Roles: []string{"editor"}}
+	other := &Principal{Subject: "budi", Roles: []string{"editor"}}
+
+	if code := send(nil, "d1"); code != http.StatusUnauthorized {
+		t.Fatalf("no principal: %d", code)
+	}
+	if code := send(owner, "d1"); code != http.StatusNoContent {
+		t.Fatalf("owner: %d", code)
+	}
+	if code := send(other, "d1"); code != http.StatusForbidden {
+		t.Fatalf("other editor: %d", code)
+	}
+	if code := send(owner, "nope"); code != http.StatusNotFound {
+		t.Fatalf("missing resource: %d", code)
+	}
+}
+
+func TestAuthorizationMiddlewareSubjectAttributes(t *testing.T) {
+	e := loadTestPolicy(t)
+	invoice := Resource{Type: "invoice", ID: "inv1", Attributes: map[string]any{"amount": 2500, "department": "ops"}}
+	resource := func(r *http.Request) (Resource, error) { return invoice, nil }
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	send := func(h http.Handler, p *Principal) int {
+		req := httptest.NewRequest(http.MethodPost, "/invoices/inv1/approve", nil)
+		req = req.WithContext(ContextWithPrincipal(req.Context(), p))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// atribut dari claim "attrs" di token
+	fromClaims := e.Middleware("approve", resource)(ok)
+	opsClaims := &Principal{Subject: "f1", Roles: []string{"finance"}, Claims: Claims{Attributes: map[string]any{"department": "ops"}}}
+	hrClaims := &Principal{Subject: "f2", Roles: []string{"finance"}, Claims: Claims{Attributes: map[string]any{"department": "hr"}}}
+	if code := send(fromClaims, opsClaims); code != http.StatusNoContent {
+		t.Fatalf("finance ops via claims: %d", code)
+	}
+	if code := send(fromClaims, hrClaims); code != http.StatusForbidden {
+		t.Fatalf("finance hr via claims: %d", code)
+	}
+
+	// atribut dari SubjectFunc, misalnya hasil lookup ke database user
+	departments := map[string]string{"f1": "ops", "f2": "hr"}
+	fromLookup := e.MiddlewareWithSubject("approve", resource, func(r *http.Request, p *Principal) (Subject, error) {
+		dept, found := departments[p.Subject]
+		if !found {
+			return Subject{}, errors.New("unknown user")
+		}
+		return Subject{ID: p.Subject, Roles: p.Roles, Attributes: map[string]any{"department": dept}}, nil
+	})(ok)
+	if code := send(fromLookup, &Principal{Subject: "f1", Roles: []string{"finance"}}); code != http.StatusNoContent {
+		t.Fatalf("finance ops via lookup: %d", code)
+	}
+	if code := send(fromLookup, &Principal{Subject: "f2", Roles: []string{"finance"}}); code != http.StatusForbidden {
+		t.Fatalf("finance hr via lookup: %d", code)
+	}
+	if code := send(fromLookup, &Principal{Subject: "ghost", Roles: []string{"finance"}}); code != http.StatusInternalServerError {
+		t.Fatalf("subject lookup error: %d", code)
+	}
+}
@@ -0,0 +1,276 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := NewKey()
+
+	ct, err := Encrypt(key, []byte("rahasia"), []byte("user:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct[0] != formatDirect {
+		t.Fatalf("expected version header 0x01, got 0x%02x", ct[0])
+	}
+
+	plain, err := Decrypt(key, ct, []byte("user:1"))
+	if err != nil || string(plain) != "rahasia" {
+		t.Fatalf("decrypt: %q %v", plain, err)
+	}
+
+	if _, err := Decrypt(key, ct, []byte("user:2")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("wrong aad must fail, got %v", err)
+	}
+	if _, err := Decrypt(NewKey(), ct, []byte("user:1")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("wrong key must fail, got %v", err)
+	}
+
+	ct[len(ct)-1] ^= 1
+	if _, err := Decrypt(key, ct, []byte("user:1")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("tampered ciphertext must fail, got %v", err)
+	}
+
+	ct[0] = 0x7f
+	if _, err := Decrypt(key, ct, nil); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("unknown version must be reported, got %v", err)
+	}
+}
+
+func newTestKeyring(t *testing.T) *Keyring {
+	t.Helper()
+
+	kr, err := CreateKeyring(filepath.Join(t.TempDir(), "keyring.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestKeyringKeepsStateWhenSaveFails(t *testing.T) {
+	kr := newTestKeyring(t)
+	rotated, err := kr.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary, ids := kr.Primary(), kr.IDs()
+
+	// direktori yang tidak ada: file sementara tidak bisa dibuat
+	kr.path = filepath.Join(t.TempDir(), "missing", "keyring.json")
+
+	if _, err := kr.Rotate(); err == nil {
+		t.Fatal("rotate must fail when the keyring cannot be written")
+	}
+	if err := kr.Retire(ids[0]); err == nil {
+		t.Fatal("retire must fail when the keyring cannot be written")
+	}
+	if kr.Primary() != primary || primary != rotated || !slices.Equal(kr.IDs(), ids) {
+		t.Fatalf("keyring changed after failed save: primary %s ids %v, want %s %v", kr.Primary(), kr.IDs(), primary, ids)
+	}
+	if _, err := kr.key(ids[0]); err != nil {
+		t.Fatalf("old key must still be usable: %v", err)
+	}
+}
+
+func TestKeyringFile(t *testing.T) {
+	kr := newTestKeyring(t)
+
+	info, _ := os.Stat(kr.path)
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("keyring must be 0600, got %v", info.Mode().Perm())
+	}
+	if _, err := CreateKeyring(kr.path); err == nil {
+		t.Fatal("existing keyring must not be overwritten")
+	}
+
+	newID, err := kr.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadKeyring(kr.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Primary() != newID || len(loaded.IDs()) != 2 {
+		t.Fatalf("reloaded keyring: primary %s ids %v", loaded.Primary(), loaded.IDs())
+	}
+
+	if err := loaded.Retire(newID); err == nil {
+		t.Fatal("primary key must not be retired")
+	}
+
+	os.Chmod(kr.path, 0o644)
+	if _, err := LoadKeyring(kr.path); err == nil {
+		t.Fatal("world-readable keyring must be rejected")
+	}
+}
+
+type memoryCiphertexts map[string][]byte
+
+func (m memoryCiphertexts) List(context.Context) ([]string, error) {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (m memoryCiphertexts) Get(_ context.Context, id string) ([]byte, error) {
+	return m[id], nil
+}
+
+func (m memoryCiphertexts) Put(_ context.Context, id string, ct []byte) error {
+	m[id] = ct
+	return nil
+}
+
+func TestEnvelopeRotation(t *testing.T) {
+	ctx := context.Background()
+	kr := newTestKeyring(t)
+	env := NewEnvelope(kr)
+	oldID := kr.Primary()
+
+	store := memoryCiphertexts{}
+	for _, id := range []string{"customer:1", "customer:2", "customer:3"} {
+		ct, err := env.Seal([]byte("nik-"+id), []byte(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store[id] = ct
+	}
+
+	if _, err := env.Open(store["customer:1"], []byte("customer:2")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("ciphertext moved to another row must fail, got %v", err)
+	}
+
+	newID, _ := kr.Rotate()
+
+	fresh, _ := env.Seal([]byte("baru"), []byte("customer:4"))
+	store["customer:4"] = fresh
+	if kid, _ := KeyID(fresh); kid != newID {
+		t.Fatalf("new values must use the new primary, got %s", kid)
+	}
+
+	report, err := env.RotateAll(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Scanned != 4 || report.Rewrapped != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	if err := kr.Retire(oldID); err != nil {
+		t.Fatal(err)
+	}
+	for id, ct := range store {
+		if kid, _ := KeyID(ct); kid != newID {
+			t.Errorf("%s still uses %s", id, kid)
+		}
+		if _, err := env.Open(ct, []byte(id)); err != nil {
+			t.Errorf("%s must open after retiring old key: %v", id, err)
+		}
+	}
+
+	again, _ := env.RotateAll(ctx, store)
+	if again.Rewrapped != 0 {
+		t.Fatalf("second rotation must be a no-op, got %+v", again)
+	}
+}
+
+func TestEnvelopeTamperedKeyID(t *testing.T) {
+	kr := newTestKeyring(t)
+	env := NewEnvelope(kr)
+	ct, _ := env.Seal([]byte("x"), nil)
+
+	oldID := kr.Primary()
+	newID, _ := kr.Rotate()
+	tampered := bytes.Replace(ct, []byte(oldID), []byte(newID), 1)
+
+	if _, err := env.Open(tampered, nil); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("swapped kid must fail, got %v", err)
+	}
+}
+
+func TestStreamEncryption(t *testing.T) {
+	env := NewEnvelope(newTestKeyring(t))
+
+	for _, size := range []int{0, 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 17} {
+		plain := bytes.Repeat([]byte("go-journey"), size/10+1)[:size]
+
+		var enc bytes.Buffer
+		if err := env.EncryptStream(&enc, bytes.NewReader(plain)); err != nil {
+			t.Fatal(err)
+		}
+
+		var dec bytes.Buffer
+		if err := env.DecryptStream(&dec, bytes.NewReader(enc.Bytes())); err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(dec.Bytes(), plain) {
+			t.Fatalf("size %d: roundtrip mismatch", size)
+		}
+	}
+}
+
+func TestStreamDetectsTruncation(t *testing.T) {
+	env := NewEnvelope(newTestKeyring(t))
+	plain := bytes.Repeat([]byte("a"), 2*streamChunkSize+100)
+
+	var enc bytes.Buffer
+	env.EncryptStream(&enc, bytes.NewReader(plain))
+
+	// potong tepat di batas chunk: chunk yang tersisa masih valid tapi chunk
+	// terakhir (yang membawa flag last) hilang
+	cut := enc.Len() - (4 + 100 + 16)
+
+	err := env.DecryptStream(io.Discard, bytes.NewReader(enc.Bytes()[:cut]))
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected ErrTruncated, got %v", err)
+	}
+
+	err = env.DecryptStream(io.Discard, bytes.NewReader(enc.Bytes()[:cut-10]))
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected ErrTruncated for partial chunk, got %v", err)
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	dir := t.TempDir()
+	env := NewEnvelope(newTestKeyring(t))
+
+	src := filepath.Join(dir, "report.csv")
+	plain := bytes.Repeat([]byte("id,name\n1,bisma\n"), 10000)
+	os.WriteFile(src, plain, 0o600)
+
+	if err := env.EncryptFile(filepath.Join(dir, "report.csv.enc"), src); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.DecryptFile(filepath.Join(dir, "report.out.csv"), filepath.Join(dir, "report.csv.enc")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(dir, "report.out.csv"))
+	if !bytes.Equal(got, plain) {
+		t.Fatal("file roundtrip mismatch")
+	}
+
+	enc, _ := os.ReadFile(filepath.Join(dir, "report.csv.enc"))
+	os.WriteFile(filepath.Join(dir, "broken.enc"), enc[:len(enc)-5], 0o600)
+	if err := env.DecryptFile(filepath.Join(dir, "broken.csv"), filepath.Join(dir, "broken.enc")); err == nil {
+		t.Fatal("truncated file must fail")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.csv")); !os.IsNotExist(err) {
+		t.Fatal("failed decryption must not leave an output file")
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/MrBista/go-journey/advanced/28-security
+
+go 1.21.4
+
+require gopkg.in/yaml.v3 v3.0.1
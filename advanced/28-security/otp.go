@@ -0,0 +1,280 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// One-time password untuk faktor kedua: HOTP (RFC 4226) berbasis counter
+// dan TOTP (RFC 6238) yang counternya diturunkan dari waktu. Semua fungsi
+// di sini tidak menyimpan state; counter terakhir yang dipakai dan hash
+// recovery code disimpan pemanggil bersama data user.
+
+var (
+	ErrOTPInvalid  = errors.New("security: invalid one-time password")
+	ErrOTPReplayed = errors.New("security: one-time password already used")
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateOTPSecret membuat secret 160 bit sesuai rekomendasi RFC 4226.
+func GenerateOTPSecret() []byte {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+// EncodeOTPSecret mengubah secret ke base32 tanpa padding, format yang
+// diketik manual ke aplikasi authenticator.
+func EncodeOTPSecret(secret []byte) string {
+	return b32.EncodeToString(secret)
+}
+
+func DecodeOTPSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	return b32.DecodeString(strings.TrimRight(s, "="))
+}
+
+// HOTP menghitung kode RFC 4226 dengan HMAC-SHA1. digits harus 6-8;
+// nilai lain adalah bug di caller sehingga HOTP panic.
+func HOTP(secret []byte, counter uint64, digits int) string {
+	if err := checkOTPDigits(digits); err != nil {
+		panic(err)
+	}
+	return hotp(sha1.New, secret, counter, digits)
+}
+
+// checkOTPDigits membatasi digits ke 6-8 sesuai RFC 4226. Di atas 9 digit
+// modulus uint32 overflow dan kodenya salah tanpa error.
+func checkOTPDigits(digits int) error {
+	if digits < 6 || digits > 8 {
+		return fmt.Errorf("security: OTP digits must be 6-8, got %d", digits)
+	}
+	return nil
+}
+
+func hotp(h func() hash.Hash, secret []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(h, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation: 4 byte mulai dari offset di nibble terakhir
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, code%mod)
+}
+
+// VerifyHOTP mencocokkan code dengan counter sampai counter+lookAhead untuk
+// menoleransi tombol token yang ditekan tanpa login. Kalau cocok, simpan
+// counter yang dikembalikan sebagai counter berikutnya.
+func VerifyHOTP(secret []byte, counter uint64, code string, digits, lookAhead int) (next uint64, err error) {
+	if err := checkOTPDigits(digits); err != nil {
+		return counter, err
+	}
+	for i := 0; i <= lookAhead; i++ {
+		if equalCode(HOTP(secret, counter+uint64(i), digits), code) {
+			return counter + uint64(i) + 1, nil
+		}
+	}
+	return counter, ErrOTPInvalid
+}
+
+type TOTPConfig struct {
+	Digits    int           // 6 (default), 7 atau 8
+	Period    time.Duration // default 30 detik
+	Algorithm string        // "SHA1" (default, didukung semua aplikasi), "SHA256", "SHA512"
+	// Skew adalah jumlah step sebelum dan sesudah waktu sekarang yang masih
+	// diterima untuk menoleransi jam HP yang meleset. Default 1, -1 berarti 0.
+	Skew int
+}
+
+type TOTP struct {
+	secret []byte
+	cfg    TOTPConfig
+	hash   func() hash.Hash
+}
+
+func NewTOTP(secret []byte, cfg TOTPConfig) (*TOTP, error) {
+	if len(secret) < 16 {
+		return nil, errors.New("security: OTP secret must be at least 128 bits")
+	}
+	if cfg.Digits == 0 {
+		cfg.Digits = 6
+	}
+	if err := checkOTPDigits(cfg.Digits); err != nil {
+		return nil, err
+	}
+	if cfg.Period <= 0 {
+		cfg.Period = 30 * time.Second
+	}
+	if cfg.Period%time.Second != 0 {
+		return nil, errors.New("security: OTP period must be whole seconds")
+	}
+	if cfg.Skew == 0 {
+		cfg.Skew = 1
+	}
+	if cfg.Skew < 0 {
+		cfg.Skew = 0
+	}
+
+	t := &TOTP{secret: secret, cfg: cfg}
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", "SHA1":
+		t.cfg.Algorithm, t.hash = "SHA1", sha1.New
+	case "SHA256":
+		t.cfg.Algorithm, t.hash = "SHA256", sha256.New
+	case "SHA512":
+		t.cfg.Algorithm, t.hash = "SHA512", sha512.New
+	default:
+		return nil, fmt.Errorf("security: unsupported OTP algorithm %q", cfg.Algorithm)
+	}
+
+	return t, nil
+}
+
+// Counter adalah nomor step waktu (T dalam RFC 6238).
+func (t *TOTP) Counter(at time.Time) uint64 {
+	return uint64(at.Unix()) / uint64(t.cfg.Period/time.Second)
+}
+
+func (t *TOTP) At(at time.Time) string {
+	return hotp(t.hash, t.secret, t.Counter(at), t.cfg.Digits)
+}
+
+// Validate memeriksa code di sekitar waktu at (± Skew step). lastUsed
+// adalah counter dari kode terakhir yang berhasil dipakai user ini; kode
+// dengan counter <= lastUsed ditolak dengan ErrOTPReplayed supaya kode yang
+// sama tidak bisa dipakai dua kali dalam satu window. Simpan counter yang
+// dikembalikan sebagai lastUsed berikutnya.
+func (t *TOTP) Validate(code string, at time.Time, lastUsed uint64) (uint64, error) {
+	if len(code) != t.cfg.Digits {
+		return 0, ErrOTPInvalid
+	}
+	if _, err := strconv.ParseUint(code, 10, 64); err != nil {
+		return 0, ErrOTPInvalid
+	}
+
+	current := t.Counter(at)
+	replayed := false
+	for i := -t.cfg.Skew; i <= t.cfg.Skew; i++ {
+		if i < 0 && uint64(-i) > current {
+			continue
+		}
+		counter := current + uint64(i)
+		if !equalCode(hotp(t.hash, t.secret, counter, t.cfg.Digits), code) {
+			continue
+		}
+		if counter <= lastUsed {
+			replayed = true
+			continue
+		}
+		return counter, nil
+	}
+
+	if replayed {
+		return 0, ErrOTPReplayed
+	}
+	return 0, ErrOTPInvalid
+}
+
+// URI membuat link otpauth:// untuk QR code saat enrollment. Format:
+// otpauth://totp/Issuer:account?secret=...&issuer=Issuer&algorithm=SHA1&digits=6&period=30
+func (t *TOTP) URI(issuer, account string) string {
+	q := url.Values{}
+	q.Set("secret", EncodeOTPSecret(t.secret))
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", t.cfg.Algorithm)
+	q.Set("digits", strconv.Itoa(t.cfg.Digits))
+	q.Set("period", strconv.Itoa(int(t.cfg.Period/time.Second)))
+
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + label,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+func equalCode(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// GenerateRecoveryCodes membuat n recovery code sekali pakai berformat
+// "xxxx-xxxx-xxxx-xxxx" (80 bit). codes ditampilkan sekali ke user, yang
+// disimpan hanya hashes.
+func GenerateRecoveryCodes(n int) (codes, hashes []string) {
+	for i := 0; i < n; i++ {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			panic(err)
+		}
+
+		enc := strings.ToLower(b32.EncodeToString(raw))
+		code := enc[0:4] + "-" + enc[4:8] + "-" + enc[8:12] + "-" + enc[12:16]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes
+}
+
+// HashRecoveryCode menormalkan code (tanpa spasi dan strip, huruf kecil)
+// lalu meng-hash-nya. SHA-256 cukup karena code acak 80 bit, bukan password
+// pilihan user.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseRecoveryCode mencari code di hashes. Kalau ketemu, hash itu dibuang
+// dari slice hasil sehingga code tidak bisa dipakai lagi; simpan remaining
+// menggantikan daftar lama.
+func UseRecoveryCode(hashes []string, code string) (remaining []string, err error) {
+	h := HashRecoveryCode(code)
+
+	found := -1
+	for i, stored := range hashes {
+		if equalCode(stored, h) {
+			found = i
+		}
+	}
+	if found < 0 {
+		return hashes, ErrOTPInvalid
+	}
+
+	remaining = make([]string, 0, len(hashes)-1)
+	remaining = append(remaining, hashes[:found]...)
+	return append(remaining, hashes[found+1:]...), nil
+}
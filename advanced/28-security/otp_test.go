@@ -0,0 +1,199 @@
+package security
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHOTPRFC4226(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	for counter, code := range want {
+		if got := HOTP(secret, uint64(counter), 6); got != code {
+			t.Errorf("counter %d: got %s want %s", counter, got, code)
+		}
+	}
+}
+
+func TestVerifyHOTPLookAhead(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	next, err := VerifyHOTP(secret, 2, "338314", 6, 3)
+	if err != nil || next != 5 {
+		t.Fatalf("expected resync to counter 5, got %d %v", next, err)
+	}
+	if _, err := VerifyHOTP(secret, 5, "338314", 6, 3); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("used counter must fail, got %v", err)
+	}
+}
+
+func TestHOTPRejectsInvalidDigits(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	for _, digits := range []int{-1, 0, 5, 9, 10} {
+		if _, err := VerifyHOTP(secret, 0, "755224", digits, 0); err == nil || errors.Is(err, ErrOTPInvalid) {
+			t.Errorf("VerifyHOTP digits %d: expected a configuration error, got %v", digits, err)
+		}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("HOTP digits %d must panic", digits)
+				}
+			}()
+			HOTP(secret, 0, digits)
+		}()
+	}
+
+	if got := HOTP(secret, 0, 8); got != "84755224" {
+		t.Fatalf("8 digit code: %s", got)
+	}
+}
+
+func TestTOTPRFC6238(t *testing.T) {
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	vectors := []struct {
+		unix int64
+		want map[string]string
+	}{
+		{59, map[string]string{"SHA1": "94287082", "SHA256": "46119246", "SHA512": "90693936"}},
+		{1111111109, map[string]string{"SHA1": "07081804", "SHA256": "68084774", "SHA512": "25091201"}},
+		{1111111111, map[string]string{"SHA1": "14050471", "SHA256": "67062674", "SHA512": "99943326"}},
+		{1234567890, map[string]string{"SHA1": "89005924", "SHA256": "91819424", "SHA512": "93441116"}},
+		{2000000000, map[string]string{"SHA1": "69279037", "SHA256": "90698825", "SHA512": "38618901"}},
+		{20000000000, map[string]string{"SHA1": "65353130", "SHA256": "77737706", "SHA512": "47863826"}},
+	}
+
+	for alg, seed := range seeds {
+		totp, err := NewTOTP([]byte(seed), TOTPConfig{Digits: 8, Algorithm: alg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range vectors {
+			if got := totp.At(time.Unix(v.unix, 0)); got != v.want[alg] {
+				t.Errorf("%s at %d: got %s want %s", alg, v.unix, got, v.want[alg])
+			}
+		}
+	}
+}
+
+func TestTOTPValidateSkewAndReplay(t *testing.T) {
+	totp, _ := NewTOTP(GenerateOTPSecret(), TOTPConfig{})
+	now := time.Unix(1_700_000_000, 0)
+
+	code := totp.At(now.Add(-30 * time.Second))
+	counter, err := totp.Validate(code, now, 0)
+	if err != nil || counter != totp.Counter(now)-1 {
+		t.Fatalf("previous step must be accepted: %d %v", counter, err)
+	}
+
+	if _, err := totp.Validate(code, now, counter); !errors.Is(err, ErrOTPReplayed) {
+		t.Fatalf("same code must not be reused, got %v", err)
+	}
+
+	// kode step sebelumnya tidak boleh dipakai setelah kode yang lebih baru
+	newer, err := totp.Validate(totp.At(now), now, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := totp.Validate(totp.At(now.Add(-30*time.Second)), now, newer); !errors.Is(err, ErrOTPReplayed) {
+		t.Fatalf("older code after newer one: %v", err)
+	}
+
+	if _, err := totp.Validate(totp.At(now.Add(-2*time.Minute)), now, 0); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("code outside skew window: %v", err)
+	}
+	for _, bad := range []string{"", "12345", "abcdef", "1234567"} {
+		if _, err := totp.Validate(bad, now, 0); !errors.Is(err, ErrOTPInvalid) {
+			t.Errorf("%q: %v", bad, err)
+		}
+	}
+
+	strict, _ := NewTOTP(GenerateOTPSecret(), TOTPConfig{Skew: -1})
+	if _, err := strict.Validate(strict.At(now.Add(-30*time.Second)), now, 0); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("skew disabled: %v", err)
+	}
+}
+
+func TestTOTPConfigValidation(t *testing.T) {
+	secret := GenerateOTPSecret()
+	bad := []TOTPConfig{
+		{Digits: 5},
+		{Digits: 9},
+		{Algorithm: "MD5"},
+		{Period: 1500 * time.Millisecond},
+	}
+	for _, cfg := range bad {
+		if _, err := NewTOTP(secret, cfg); err == nil {
+			t.Errorf("config %+v must be rejected", cfg)
+		}
+	}
+	if _, err := NewTOTP(secret[:8], TOTPConfig{}); err == nil {
+		t.Error("short secret must be rejected")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	totp, _ := NewTOTP(secret, TOTPConfig{Algorithm: "sha256"})
+
+	raw := totp.URI("Go Journey", "bisma@example.com")
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Go Journey:bisma@example.com" {
+		t.Fatalf("unexpected uri %s", raw)
+	}
+	if strings.Contains(raw, " ") {
+		t.Fatalf("uri must be escaped: %s", raw)
+	}
+
+	q := u.Query()
+	if q.Get("secret") != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" || q.Get("issuer") != "Go Journey" ||
+		q.Get("algorithm") != "SHA256" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Fatalf("unexpected query %v", q)
+	}
+
+	decoded, err := DecodeOTPSecret(strings.ToLower(q.Get("secret")))
+	if err != nil || string(decoded) != string(secret) {
+		t.Fatalf("secret roundtrip: %q %v", decoded, err)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes := GenerateRecoveryCodes(10)
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("expected 10 codes, got %d", len(codes))
+	}
+	for i, h := range hashes {
+		if strings.Contains(h, codes[i]) || len(codes[i]) != 19 {
+			t.Fatalf("code %q / hash %q", codes[i], h)
+		}
+	}
+
+	// user boleh mengetik tanpa strip dan huruf besar
+	typed := strings.ToUpper(strings.ReplaceAll(codes[3], "-", " "))
+	remaining, err := UseRecoveryCode(hashes, typed)
+	if err != nil || len(remaining) != 9 {
+		t.Fatalf("use: %d %v", len(remaining), err)
+	}
+
+	if _, err := UseRecoveryCode(remaining, codes[3]); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("recovery code must be single use, got %v", err)
+	}
+	if len(hashes) != 10 {
+		t.Fatal("original slice must not be modified")
+	}
+}
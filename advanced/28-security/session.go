@@ -0,0 +1,531 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Session berbasis cookie. Nilai cookie selalu dienkripsi AES-256-GCM
+// (lihat Encrypt), jadi sekaligus ditandatangani: cookie yang diubah satu
+// byte pun ditolak. Ada dua mode:
+//
+//   - tanpa SessionStore: seluruh isi session ada di cookie (maks 4 KiB)
+//   - dengan SessionStore: cookie hanya membawa ID, isi session di server
+//
+// Session baru tidak langsung dibuat cookie-nya; cookie baru dikirim kalau
+// session diubah, supaya pengunjung anonim tidak memenuhi store. Di mode
+// cookie, Destroy hanya menghapus cookie di browser: salinan cookie lama
+// tetap valid sampai timeout, jadi pakai store kalau logout harus tuntas.
+
+const maxCookieSize = 4096
+
+var (
+	ErrSessionNotFound = errors.New("security: session not found")
+	ErrCookieTooLarge  = errors.New("security: session cookie exceeds 4096 bytes, use a SessionStore")
+)
+
+// SessionStore menyimpan isi session yang sudah di-encode. Get harus
+// mengembalikan ErrSessionNotFound untuk ID yang tidak ada atau sudah
+// kedaluwarsa.
+type SessionStore interface {
+	Get(ctx context.Context, id string) ([]byte, error)
+	Set(ctx context.Context, id string, data []byte, expiresAt time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type SessionConfig struct {
+	// Keys untuk mengenkripsi cookie, masing-masing KeySize byte. Key
+	// pertama dipakai untuk cookie baru, sisanya hanya untuk membuka cookie
+	// lama selama rotasi.
+	Keys  [][]byte
+	Store SessionStore // nil = semua data di cookie
+
+	CookieName    string // default "session"
+	Path          string // default "/"
+	Domain        string
+	SameSite      http.SameSite // default Lax
+	AllowInsecure bool          // izinkan cookie tanpa flag Secure, untuk development
+
+	IdleTimeout     time.Duration // default 30 menit
+	AbsoluteTimeout time.Duration // default 12 jam
+
+	CSRFHeader string // default "X-CSRF-Token"
+	CSRFField  string // default "csrf_token"
+}
+
+type SessionManager struct {
+	cfg SessionConfig
+	now func() time.Time
+}
+
+func NewSessionManager(cfg SessionConfig) (*SessionManager, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("security: SessionConfig.Keys is required")
+	}
+	for i, key := range cfg.Keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("security: session key %d must be %d bytes, got %d", i, KeySize, len(key))
+		}
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "session"
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Minute
+	}
+	if cfg.AbsoluteTimeout <= 0 {
+		cfg.AbsoluteTimeout = 12 * time.Hour
+	}
+	if cfg.CSRFHeader == "" {
+		cfg.CSRFHeader = "X-CSRF-Token"
+	}
+	if cfg.CSRFField == "" {
+		cfg.CSRFField = "csrf_token"
+	}
+
+	return &SessionManager{cfg: cfg, now: time.Now}, nil
+}
+
+type sessionState struct {
+	ID       string            `json:"id"`
+	Values   map[string]string `json:"values,omitempty"`
+	CSRF     string            `json:"csrf,omitempty"`
+	Created  time.Time         `json:"created"`
+	LastSeen time.Time         `json:"last_seen"`
+}
+
+// Session hanya valid selama satu request. Perubahan disimpan otomatis oleh
+// Middleware sebelum header response dikirim.
+type Session struct {
+	mu    sync.Mutex
+	state sessionState
+
+	// oldID adalah ID yang tersimpan di store sebelum RenewID, dihapus saat
+	// session disimpan.
+	oldID     string
+	isNew     bool
+	dirty     bool
+	destroyed bool
+	hadCookie bool
+}
+
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.ID
+}
+
+func (s *Session) CreatedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Created
+}
+
+func (s *Session) Get(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Values[key]
+}
+
+func (s *Session) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.Values == nil {
+		s.state.Values = make(map[string]string)
+	}
+	s.state.Values[key] = value
+	s.dirty = true
+}
+
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.state.Values[key]; ok {
+		delete(s.state.Values, key)
+		s.dirty = true
+	}
+}
+
+// RenewID mengganti ID session dan secret CSRF tanpa membuang isinya.
+// Panggil setiap kali hak akses berubah (login, logout, ganti role) untuk
+// mencegah session fixation.
+func (s *Session) RenewID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isNew && s.oldID == "" {
+		s.oldID = s.state.ID
+	}
+	s.state.ID = randomToken(32)
+	s.state.CSRF = randomToken(32)
+	s.dirty = true
+}
+
+// Destroy menghapus session dari store dan cookie dari browser.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.destroyed = true
+}
+
+// CSRFToken mengembalikan token untuk form atau header X-CSRF-Token. Token
+// di-mask dengan pad acak sehingga berbeda tiap kali dipanggil (mencegah
+// BREACH), tapi semuanya valid untuk session yang sama.
+func (s *Session) CSRFToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.CSRF == "" {
+		s.state.CSRF = randomToken(32)
+		s.dirty = true
+	}
+	secret, _ := b64.DecodeString(s.state.CSRF)
+
+	pad := make([]byte, len(secret))
+	if _, err := rand.Read(pad); err != nil {
+		panic(err)
+	}
+	masked := make([]byte, 0, 2*len(secret))
+	masked = append(masked, pad...)
+	for i := range secret {
+		masked = append(masked, pad[i]^secret[i])
+	}
+
+	return b64.EncodeToString(masked)
+}
+
+func (s *Session) validCSRF(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, err := b64.DecodeString(s.state.CSRF)
+	if err != nil || len(secret) == 0 {
+		return false
+	}
+	masked, err := b64.DecodeString(token)
+	if err != nil || len(masked) != 2*len(secret) {
+		return false
+	}
+
+	pad, xored := masked[:len(secret)], masked[len(secret):]
+	unmasked := make([]byte, len(secret))
+	for i := range secret {
+		unmasked[i] = pad[i] ^ xored[i]
+	}
+
+	return subtle.ConstantTimeCompare(unmasked, secret) == 1
+}
+
+type sessionKey struct{}
+
+func ContextWithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(sessionKey{}).(*Session)
+	return s, ok
+}
+
+func (m *SessionManager) newSession() *Session {
+	now := m.now()
+	return &Session{
+		isNew: true,
+		state: sessionState{ID: randomToken(32), Created: now, LastSeen: now},
+	}
+}
+
+// Load membaca session dari cookie request. Cookie yang tidak ada, rusak
+// atau kedaluwarsa menghasilkan session baru; error hanya dari store.
+func (m *SessionManager) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(m.cfg.CookieName)
+	if err != nil {
+		return m.newSession(), nil
+	}
+
+	payload, ok := m.decodeCookie(cookie.Value)
+	if !ok {
+		s := m.newSession()
+		s.hadCookie = true
+		return s, nil
+	}
+
+	var state sessionState
+	if err := json.Unmarshal(payload, &state); err != nil || state.ID == "" {
+		s := m.newSession()
+		s.hadCookie = true
+		return s, nil
+	}
+
+	if m.cfg.Store != nil {
+		data, err := m.cfg.Store.Get(r.Context(), state.ID)
+		if err == nil {
+			err = json.Unmarshal(data, &state)
+		}
+		if errors.Is(err, ErrSessionNotFound) {
+			s := m.newSession()
+			s.hadCookie = true
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := m.now()
+	if now.Sub(state.LastSeen) > m.cfg.IdleTimeout || now.Sub(state.Created) > m.cfg.AbsoluteTimeout {
+		if m.cfg.Store != nil {
+			if err := m.cfg.Store.Delete(r.Context(), state.ID); err != nil {
+				return nil, err
+			}
+		}
+		s := m.newSession()
+		s.hadCookie = true
+		return s, nil
+	}
+
+	s := &Session{state: state, hadCookie: true}
+	// LastSeen tidak ditulis tiap request supaya store tidak menerima write
+	// untuk setiap klik; cukup kalau sudah lewat sepersepuluh idle timeout.
+	if now.Sub(state.LastSeen) >= m.cfg.IdleTimeout/10 {
+		s.state.LastSeen = now
+		s.dirty = true
+	}
+
+	return s, nil
+}
+
+// Save menyimpan session ke store dan menulis Set-Cookie. Harus dipanggil
+// sebelum header response dikirim; Middleware melakukannya otomatis.
+func (m *SessionManager) Save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := r.Context()
+	if s.destroyed {
+		if m.cfg.Store != nil {
+			for _, id := range []string{s.oldID, s.state.ID} {
+				if id == "" {
+					continue
+				}
+				if err := m.cfg.Store.Delete(ctx, id); err != nil {
+					return err
+				}
+			}
+		}
+		if s.hadCookie {
+			http.SetCookie(w, m.cookie("", -1))
+		}
+		return nil
+	}
+
+	if !s.dirty {
+		if s.isNew && s.hadCookie {
+			// cookie lama tidak valid, hapus supaya tidak dikirim terus
+			http.SetCookie(w, m.cookie("", -1))
+		}
+		return nil
+	}
+
+	expiresAt := s.state.LastSeen.Add(m.cfg.IdleTimeout)
+	if absolute := s.state.Created.Add(m.cfg.AbsoluteTimeout); absolute.Before(expiresAt) {
+		expiresAt = absolute
+	}
+
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+
+	payload := data
+	if m.cfg.Store != nil {
+		if s.oldID != "" {
+			if err := m.cfg.Store.Delete(ctx, s.oldID); err != nil {
+				return err
+			}
+			s.oldID = ""
+		}
+		if err := m.cfg.Store.Set(ctx, s.state.ID, data, expiresAt); err != nil {
+			return err
+		}
+		payload, _ = json.Marshal(sessionState{ID: s.state.ID})
+	}
+
+	value, err := m.encodeCookie(payload)
+	if err != nil {
+		return err
+	}
+
+	maxAge := int(s.state.Created.Add(m.cfg.AbsoluteTimeout).Sub(m.now()) / time.Second)
+	cookie := m.cookie(value, maxAge)
+	if len(cookie.String()) > maxCookieSize {
+		return ErrCookieTooLarge
+	}
+
+	http.SetCookie(w, cookie)
+	s.isNew, s.dirty, s.hadCookie = false, false, true
+	return nil
+}
+
+func (m *SessionManager) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    value,
+		Path:     m.cfg.Path,
+		Domain:   m.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   !m.cfg.AllowInsecure,
+		HttpOnly: true,
+		SameSite: m.cfg.SameSite,
+	}
+}
+
+func (m *SessionManager) encodeCookie(payload []byte) (string, error) {
+	ct, err := Encrypt(m.cfg.Keys[0], payload, []byte(m.cfg.CookieName))
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(ct), nil
+}
+
+// decodeCookie mencoba semua key, jadi cookie yang dibuat dengan key lama
+// tetap terbaca sampai key itu dikeluarkan dari Keys.
+func (m *SessionManager) decodeCookie(value string) ([]byte, bool) {
+	ct, err := b64.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+	for _, key := range m.cfg.Keys {
+		if payload, err := Decrypt(key, ct, []byte(m.cfg.CookieName)); err == nil {
+			return payload, true
+		}
+	}
+	return nil, false
+}
+
+// Middleware memuat session, menaruhnya di context, lalu menyimpannya tepat
+// sebelum header response pertama ditulis.
+func (m *SessionManager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := m.Load(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		r = r.WithContext(ContextWithSession(r.Context(), s))
+		sw := &sessionWriter{ResponseWriter: w, m: m, r: r, s: s}
+		next.ServeHTTP(sw, r)
+		sw.commit()
+	})
+}
+
+// RequireCSRF menolak request yang mengubah state (selain GET, HEAD,
+// OPTIONS, TRACE) tanpa token CSRF yang cocok dengan session. Dipasang di
+// dalam Middleware.
+func (m *SessionManager) RequireCSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			if !m.VerifyCSRF(r) {
+				http.Error(w, "invalid CSRF token", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// VerifyCSRF membaca token dari header CSRFHeader atau field form CSRFField.
+func (m *SessionManager) VerifyCSRF(r *http.Request) bool {
+	s, ok := SessionFromContext(r.Context())
+	if !ok {
+		return false
+	}
+
+	token := r.Header.Get(m.cfg.CSRFHeader)
+	if token == "" {
+		token = r.PostFormValue(m.cfg.CSRFField)
+	}
+
+	return token != "" && s.validCSRF(token)
+}
+
+type sessionWriter struct {
+	http.ResponseWriter
+	m *SessionManager
+	r *http.Request
+	s *Session
+
+	committed bool
+	failed    bool
+}
+
+// commit menyimpan session sekali saja. Kalau store gagal, response diganti
+// 500 karena header belum terkirim.
+func (w *sessionWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+
+	if err := w.m.Save(w.ResponseWriter, w.r, w.s); err != nil {
+		w.failed = true
+		http.Error(w.ResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	if w.committed {
+		if !w.failed {
+			w.ResponseWriter.WriteHeader(code)
+		}
+		return
+	}
+
+	w.commit()
+	if !w.failed {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.failed {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Flush() {
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.failed {
+		f.Flush()
+	}
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
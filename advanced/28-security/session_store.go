@@ -0,0 +1,288 @@
+package security
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionSweeper dipenuhi semua store bawaan. Sweep menghapus session yang
+// sudah kedaluwarsa dan mengembalikan jumlahnya.
+type SessionSweeper interface {
+	Sweep(ctx context.Context) (int, error)
+}
+
+// RunSweeper memanggil Sweep tiap interval sampai ctx selesai. Jalankan di
+// goroutine sendiri.
+func RunSweeper(ctx context.Context, s SessionSweeper, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep(ctx)
+		}
+	}
+}
+
+type memorySession struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// MemorySessionStore cukup untuk satu instance. Session yang kedaluwarsa
+// sudah tidak terbaca lewat Get, Sweep hanya membebaskan memorinya.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	now      func() time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]memorySession), now: time.Now}
+}
+
+func (s *MemorySessionStore) Get(_ context.Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok || !s.now().Before(sess.expiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return sess.data, nil
+}
+
+func (s *MemorySessionStore) Set(_ context.Context, id string, data []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[id] = memorySession{data: data, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *MemorySessionStore) Sweep(context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now, n := s.now(), 0
+	for id, sess := range s.sessions {
+		if !now.Before(sess.expiresAt) {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// FileSessionStore menyimpan satu file per session. Nama file adalah hash
+// dari ID, jadi ID dari cookie tidak pernah dipakai langsung sebagai path
+// dan isi direktori tidak membocorkan ID yang masih aktif.
+type FileSessionStore struct {
+	dir string
+	now func() time.Time
+}
+
+type fileSession struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Data      []byte    `json:"data"`
+}
+
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSessionStore{dir: dir, now: time.Now}, nil
+}
+
+func (s *FileSessionStore) path(id string) string {
+	return filepath.Join(s.dir, hashSessionID(id)+".json")
+}
+
+// hashSessionID dipakai store yang menyimpan session di luar proses. ID
+// session sama nilainya dengan password selama session hidup, jadi yang
+// disimpan hanya SHA-256-nya (64 karakter hex): siapa pun yang bisa membaca
+// direktori atau tabel tidak bisa memakai isinya untuk membajak session.
+func hashSessionID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *FileSessionStore) Get(_ context.Context, id string) ([]byte, error) {
+	sess, err := readFileSession(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(sess.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return sess.Data, nil
+}
+
+func (s *FileSessionStore) Set(_ context.Context, id string, data []byte, expiresAt time.Time) error {
+	raw, err := json.Marshal(fileSession{ExpiresAt: expiresAt, Data: data})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(id), raw, 0o600)
+}
+
+func (s *FileSessionStore) Delete(_ context.Context, id string) error {
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileSessionStore) Sweep(ctx context.Context) (int, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	now, n := s.now(), 0
+	for _, path := range matches {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		sess, err := readFileSession(path)
+		if err != nil || now.Before(sess.ExpiresAt) {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func readFileSession(path string) (fileSession, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fileSession{}, err
+	}
+
+	var sess fileSession
+	if err := json.Unmarshal(raw, &sess); err != nil {
+		return fileSession{}, fmt.Errorf("security: corrupt session file %s: %w", filepath.Base(path), err)
+	}
+	return sess, nil
+}
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLSessionStore menyimpan session di tabel database, misalnya koneksi
+// MySQL dari database.GetConnection() di modul 24-database. Skema tabelnya
+// ada di 24-database/script.db:
+//
+//	CREATE TABLE sessions
+//	(
+//	    id VARCHAR(64) NOT NULL,
+//	    data BLOB NOT NULL,
+//	    expires_at DATETIME NOT NULL,
+//	    PRIMARY KEY(id),
+//	    INDEX sessions_expires_at (expires_at)
+//	) ENGINE = InnoDB;
+//
+// Kolom id berisi hash dari ID session, sama seperti FileSessionStore,
+// bukan ID yang ada di cookie. Query memakai placeholder "?" (MySQL, SQLite).
+type SQLSessionStore struct {
+	db  *sql.DB
+	now func() time.Time
+
+	getQuery    string
+	deleteQuery string
+	insertQuery string
+	sweepQuery  string
+}
+
+func NewSQLSessionStore(db *sql.DB, table string) (*SQLSessionStore, error) {
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("security: invalid session table name %q", table)
+	}
+
+	q := func(format string) string { return strings.ReplaceAll(format, "{table}", table) }
+	return &SQLSessionStore{
+		db:          db,
+		now:         time.Now,
+		getQuery:    q("SELECT data, expires_at FROM {table} WHERE id = ?"),
+		deleteQuery: q("DELETE FROM {table} WHERE id = ?"),
+		insertQuery: q("INSERT INTO {table} (id, data, expires_at) VALUES (?, ?, ?)"),
+		sweepQuery:  q("DELETE FROM {table} WHERE expires_at <= ?"),
+	}, nil
+}
+
+func (s *SQLSessionStore) Get(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	var expiresAt time.Time
+
+	err := s.db.QueryRowContext(ctx, s.getQuery, hashSessionID(id)).Scan(&data, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(expiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return data, nil
+}
+
+// Set memakai DELETE lalu INSERT dalam satu transaksi supaya tidak
+// bergantung pada sintaks upsert tiap database.
+func (s *SQLSessionStore) Set(ctx context.Context, id string, data []byte, expiresAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	key := hashSessionID(id)
+	if _, err := tx.ExecContext(ctx, s.deleteQuery, key); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.insertQuery, key, data, expiresAt.UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLSessionStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.deleteQuery, hashSessionID(id))
+	return err
+}
+
+func (s *SQLSessionStore) Sweep(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx, s.sweepQuery, s.now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
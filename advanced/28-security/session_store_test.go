@@ -0,0 +1,229 @@
+package security
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSessionDB adalah driver database/sql minimal yang hanya mengerti
+// query dari SQLSessionStore, cukup untuk menguji perilakunya tanpa MySQL.
+type fakeSessionDB struct {
+	mu   sync.Mutex
+	rows map[string]fakeSessionRow
+}
+
+type fakeSessionRow struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func newFakeSessionDB() (*fakeSessionDB, *sql.DB) {
+	f := &fakeSessionDB{rows: make(map[string]fakeSessionRow)}
+	return f, sql.OpenDB(f)
+}
+
+func (f *fakeSessionDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakeSessionConn{db: f}, nil
+}
+func (f *fakeSessionDB) Driver() driver.Driver { return nil }
+
+func (f *fakeSessionDB) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.rows))
+	for k := range f.rows {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type fakeSessionConn struct {
+	db       *fakeSessionDB
+	snapshot map[string]fakeSessionRow // isi tabel saat Begin, untuk Rollback
+}
+
+func (c *fakeSessionConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSessionStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeSessionConn) Close() error { return nil }
+
+func (c *fakeSessionConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.snapshot = make(map[string]fakeSessionRow, len(c.db.rows))
+	for k, v := range c.db.rows {
+		c.snapshot[k] = v
+	}
+	return c, nil
+}
+
+func (c *fakeSessionConn) Commit() error {
+	c.snapshot = nil
+	return nil
+}
+
+func (c *fakeSessionConn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if c.snapshot != nil {
+		c.db.rows, c.snapshot = c.snapshot, nil
+	}
+	return nil
+}
+
+type fakeSessionStmt struct {
+	conn  *fakeSessionConn
+	query string
+}
+
+func (s *fakeSessionStmt) Close() error  { return nil }
+func (s *fakeSessionStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeSessionStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(s.query, "INSERT"):
+		id := args[0].(string)
+		if _, ok := db.rows[id]; ok {
+			return nil, fmt.Errorf("duplicate entry %q for key PRIMARY", id)
+		}
+		db.rows[id] = fakeSessionRow{data: append([]byte(nil), args[1].([]byte)...), expiresAt: args[2].(time.Time)}
+		return driver.RowsAffected(1), nil
+
+	case strings.HasSuffix(s.query, "WHERE id = ?"):
+		id := args[0].(string)
+		if _, ok := db.rows[id]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		delete(db.rows, id)
+		return driver.RowsAffected(1), nil
+
+	case strings.HasSuffix(s.query, "WHERE expires_at <= ?"):
+		now, n := args[0].(time.Time), 0
+		for id, row := range db.rows {
+			if !row.expiresAt.After(now) {
+				delete(db.rows, id)
+				n++
+			}
+		}
+		return driver.RowsAffected(n), nil
+	}
+	return nil, fmt.Errorf("fake db: unsupported exec %q", s.query)
+}
+
+func (s *fakeSessionStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.query, "SELECT data, expires_at") {
+		return nil, fmt.Errorf("fake db: unsupported query %q", s.query)
+	}
+
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	rows := &fakeSessionRows{}
+	if row, ok := db.rows[args[0].(string)]; ok {
+		rows.values = append(rows.values, []driver.Value{row.data, row.expiresAt})
+	}
+	return rows, nil
+}
+
+type fakeSessionRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeSessionRows) Columns() []string { return []string{"data", "expires_at"} }
+func (r *fakeSessionRows) Close() error      { return nil }
+
+func (r *fakeSessionRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newTestSQLSessionStore(t *testing.T) (*SQLSessionStore, *fakeSessionDB) {
+	t.Helper()
+
+	fake, db := newFakeSessionDB()
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewSQLSessionStore(db, "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, fake
+}
+
+func TestSQLSessionStore(t *testing.T) {
+	ctx := context.Background()
+	store, fake := newTestSQLSessionStore(t)
+	clock := &sessionClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	store.now = clock.Now
+
+	store.Set(ctx, "alive", []byte("v1"), clock.now.Add(time.Hour))
+	if err := store.Set(ctx, "alive", []byte("v2"), clock.now.Add(time.Hour)); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	if data, err := store.Get(ctx, "alive"); err != nil || string(data) != "v2" {
+		t.Fatalf("get: %q %v", data, err)
+	}
+
+	store.Set(ctx, "expired", []byte("x"), clock.now.Add(-time.Second))
+	store.Set(ctx, "expires-now", []byte("x"), clock.now)
+	for _, id := range []string{"expired", "expires-now", "missing"} {
+		if _, err := store.Get(ctx, id); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("get %s: %v", id, err)
+		}
+	}
+
+	// kolom id hanya berisi hash, ID dari cookie tidak pernah tersimpan
+	for _, key := range fake.keys() {
+		if key == "alive" || len(key) != 64 {
+			t.Fatalf("raw session id stored: %q", key)
+		}
+	}
+
+	if n, err := store.Sweep(ctx); n != 2 || err != nil {
+		t.Fatalf("sweep: %d %v", n, err)
+	}
+	if keys := fake.keys(); len(keys) != 1 || keys[0] != hashSessionID("alive") {
+		t.Fatalf("sweep must keep only the live session, left %v", keys)
+	}
+
+	if err := store.Delete(ctx, "alive"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "alive"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("get after delete: %v", err)
+	}
+	if err := store.Delete(ctx, "alive"); err != nil {
+		t.Fatalf("deleting a missing session is not an error: %v", err)
+	}
+}
+
+func TestSessionStoresHashIDsTheSameWay(t *testing.T) {
+	fileStore, _ := NewFileSessionStore(t.TempDir())
+	sqlStore, fake := newTestSQLSessionStore(t)
+
+	sqlStore.Set(context.Background(), "session-id", []byte("x"), time.Now().Add(time.Minute))
+	if want := fileStore.path("session-id"); !strings.HasSuffix(want, fake.keys()[0]+".json") {
+		t.Fatalf("file %s and sql key %s must use the same hash", want, fake.keys()[0])
+	}
+}
@@ -0,0 +1,335 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sessionClock struct{ now time.Time }
+
+func (c *sessionClock) Now() time.Time { return c.now }
+
+func newTestSessions(t *testing.T, store SessionStore) (*SessionManager, *sessionClock) {
+	t.Helper()
+
+	m, err := NewSessionManager(SessionConfig{
+		Keys:            [][]byte{NewKey()},
+		Store:           store,
+		IdleTimeout:     10 * time.Minute,
+		AbsoluteTimeout: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &sessionClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	m.now = clock.Now
+	switch store := store.(type) {
+	case *MemorySessionStore:
+		store.now = clock.Now
+	case *FileSessionStore:
+		store.now = clock.Now
+	case *SQLSessionStore:
+		store.now = clock.Now
+	}
+	return m, clock
+}
+
+// sessionApp: /login menyimpan user dan merotasi ID, /me membaca user,
+// /logout menghapus session, /csrf mengembalikan token, /transfer butuh CSRF.
+func sessionApp(m *SessionManager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := SessionFromContext(r.Context())
+		s.RenewID()
+		s.Set("user", r.URL.Query().Get("user"))
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := SessionFromContext(r.Context())
+		w.Write([]byte(s.Get("user")))
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := SessionFromContext(r.Context())
+		s.Destroy()
+	})
+	mux.HandleFunc("/csrf", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := SessionFromContext(r.Context())
+		w.Write([]byte(s.CSRFToken()))
+	})
+	mux.Handle("/transfer", m.RequireCSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})))
+
+	return m.Middleware(mux)
+}
+
+type browser struct {
+	t       *testing.T
+	handler http.Handler
+	cookie  *http.Cookie
+}
+
+func (b *browser) do(r *http.Request) *httptest.ResponseRecorder {
+	b.t.Helper()
+
+	if b.cookie != nil {
+		r.AddCookie(b.cookie)
+	}
+	rec := httptest.NewRecorder()
+	b.handler.ServeHTTP(rec, r)
+
+	for _, c := range rec.Result().Cookies() {
+		if c.MaxAge < 0 {
+			b.cookie = nil
+		} else {
+			b.cookie = c
+		}
+	}
+	return rec
+}
+
+func (b *browser) get(path string) *httptest.ResponseRecorder {
+	return b.do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func TestSessionCookieAttributes(t *testing.T) {
+	m, _ := newTestSessions(t, nil)
+	b := &browser{t: t, handler: sessionApp(m)}
+
+	if rec := b.get("/me"); len(rec.Result().Cookies()) != 0 {
+		t.Fatal("untouched session must not set a cookie")
+	}
+
+	b.get("/login?user=bisma")
+	c := b.cookie
+	if c == nil || !c.Secure || !c.HttpOnly || c.SameSite != http.SameSiteLaxMode || c.MaxAge != 3600 {
+		t.Fatalf("unexpected cookie %+v", c)
+	}
+	if strings.Contains(c.Value, "bisma") {
+		t.Fatal("cookie value must be encrypted")
+	}
+}
+
+func TestSessionStores(t *testing.T) {
+	fileStore, err := NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlStore, _ := newTestSQLSessionStore(t)
+
+	stores := map[string]SessionStore{
+		"cookie": nil,
+		"memory": NewMemorySessionStore(),
+		"file":   fileStore,
+		"sql":    sqlStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			m, _ := newTestSessions(t, store)
+			b := &browser{t: t, handler: sessionApp(m)}
+
+			b.get("/login?user=bisma")
+			if got := b.get("/me").Body.String(); got != "bisma" {
+				t.Fatalf("expected bisma, got %q", got)
+			}
+
+			b.get("/logout")
+			if b.cookie != nil {
+				t.Fatal("logout must expire the cookie")
+			}
+			if got := b.get("/me").Body.String(); got != "" {
+				t.Fatalf("expected empty session after logout, got %q", got)
+			}
+		})
+	}
+}
+
+func TestSessionTamperedCookie(t *testing.T) {
+	m, _ := newTestSessions(t, nil)
+	b := &browser{t: t, handler: sessionApp(m)}
+	b.get("/login?user=bisma")
+
+	value := []byte(b.cookie.Value)
+	value[len(value)/2] ^= 1
+	b.cookie.Value = string(value)
+
+	rec := b.get("/me")
+	if rec.Body.String() != "" {
+		t.Fatal("tampered cookie must not be trusted")
+	}
+	if b.cookie != nil {
+		t.Fatal("invalid cookie must be cleared")
+	}
+}
+
+func TestSessionRenewIDDeletesOldSession(t *testing.T) {
+	store := NewMemorySessionStore()
+	m, _ := newTestSessions(t, store)
+	b := &browser{t: t, handler: sessionApp(m)}
+
+	b.get("/login?user=guest")
+	fixated := *b.cookie
+
+	b.get("/login?user=admin")
+	if b.cookie.Value == fixated.Value {
+		t.Fatal("login must issue a new cookie")
+	}
+	if len(store.sessions) != 1 {
+		t.Fatalf("old session must be removed from store, have %d", len(store.sessions))
+	}
+
+	attacker := &browser{t: t, handler: b.handler, cookie: &fixated}
+	if got := attacker.get("/me").Body.String(); got != "" {
+		t.Fatalf("old session id must be dead, got %q", got)
+	}
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	store := NewMemorySessionStore()
+	m, clock := newTestSessions(t, store)
+	b := &browser{t: t, handler: sessionApp(m)}
+
+	b.get("/login?user=bisma")
+
+	// aktif tiap 5 menit: idle timeout 10 menit tidak pernah terlewati
+	for i := 0; i < 11; i++ {
+		clock.now = clock.now.Add(5 * time.Minute)
+		if got := b.get("/me").Body.String(); got != "bisma" {
+			t.Fatalf("after %d minutes: session expired too early", (i+1)*5)
+		}
+	}
+
+	// 60 menit sejak login: absolute timeout 1 jam berlaku walau aktif
+	clock.now = clock.now.Add(5 * time.Minute)
+	if got := b.get("/me").Body.String(); got != "" {
+		t.Fatal("absolute timeout must end the session")
+	}
+
+	b.get("/login?user=bisma")
+	clock.now = clock.now.Add(11 * time.Minute)
+	if got := b.get("/me").Body.String(); got != "" {
+		t.Fatal("idle timeout must end the session")
+	}
+
+	// store sudah menolak session kedaluwarsa, Sweep tinggal membuang sisanya
+	if n, _ := store.Sweep(context.Background()); n != 2 || len(store.sessions) != 0 {
+		t.Fatalf("sweep: removed %d, left %d", n, len(store.sessions))
+	}
+}
+
+func TestSessionKeyRotation(t *testing.T) {
+	oldKey, newKey := NewKey(), NewKey()
+
+	m, _ := NewSessionManager(SessionConfig{Keys: [][]byte{oldKey}})
+	b := &browser{t: t, handler: sessionApp(m)}
+	b.get("/login?user=bisma")
+
+	rotated, _ := NewSessionManager(SessionConfig{Keys: [][]byte{newKey, oldKey}})
+	b.handler = sessionApp(rotated)
+	if got := b.get("/me").Body.String(); got != "bisma" {
+		t.Fatalf("cookie sealed with old key must still open, got %q", got)
+	}
+
+	dropped, _ := NewSessionManager(SessionConfig{Keys: [][]byte{newKey}})
+	b.handler = sessionApp(dropped)
+	if got := b.get("/me").Body.String(); got != "" {
+		t.Fatal("cookie sealed with removed key must be rejected")
+	}
+}
+
+func TestSessionCSRF(t *testing.T) {
+	m, _ := newTestSessions(t, NewMemorySessionStore())
+	b := &browser{t: t, handler: sessionApp(m)}
+
+	token := b.get("/csrf").Body.String()
+	if again := b.get("/csrf").Body.String(); again == token {
+		t.Fatal("csrf token must be masked differently per call")
+	}
+
+	post := func(header, field string) int {
+		form := url.Values{}
+		if field != "" {
+			form.Set("csrf_token", field)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/transfer", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			r.Header.Set("X-CSRF-Token", header)
+		}
+		return b.do(r).Code
+	}
+
+	if code := post("", ""); code != http.StatusForbidden {
+		t.Fatalf("missing token: %d", code)
+	}
+	if code := post(token, ""); code != http.StatusOK {
+		t.Fatalf("header token: %d", code)
+	}
+	if code := post("", token); code != http.StatusOK {
+		t.Fatalf("form token: %d", code)
+	}
+
+	other := &browser{t: t, handler: b.handler}
+	otherToken := other.get("/csrf").Body.String()
+	if code := post(otherToken, ""); code != http.StatusForbidden {
+		t.Fatalf("token from another session: %d", code)
+	}
+
+	b.get("/login?user=bisma")
+	if code := post(token, ""); code != http.StatusForbidden {
+		t.Fatal("csrf secret must change on login")
+	}
+}
+
+type failingStore struct{ *MemorySessionStore }
+
+func (*failingStore) Set(context.Context, string, []byte, time.Time) error {
+	return os.ErrPermission
+}
+
+func TestSessionSaveErrorReplacesResponse(t *testing.T) {
+	m, _ := newTestSessions(t, &failingStore{NewMemorySessionStore()})
+	b := &browser{t: t, handler: sessionApp(m)}
+
+	rec := b.get("/login?user=bisma")
+	if rec.Code != http.StatusInternalServerError || b.cookie != nil {
+		t.Fatalf("store failure must become 500 without cookie, got %d", rec.Code)
+	}
+}
+
+func TestFileSessionStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewFileSessionStore(t.TempDir())
+	now := time.Now()
+
+	store.Set(ctx, "a", []byte("1"), now.Add(-time.Minute))
+	store.Set(ctx, "../../etc/passwd", []byte("2"), now.Add(time.Minute))
+
+	if _, err := store.Get(ctx, "a"); err != ErrSessionNotFound {
+		t.Fatalf("expired session: %v", err)
+	}
+	if data, _ := store.Get(ctx, "../../etc/passwd"); string(data) != "2" {
+		t.Fatal("ids are hashed, so any id must be a valid key")
+	}
+
+	if n, err := store.Sweep(ctx); n != 1 || err != nil {
+		t.Fatalf("sweep: %d %v", n, err)
+	}
+}
+
+func TestSQLSessionStoreTableName(t *testing.T) {
+	if _, err := NewSQLSessionStore(nil, "sessions; DROP TABLE users"); err == nil {
+		t.Fatal("table name must be validated")
+	}
+	if _, err := NewSQLSessionStore(nil, "sessions"); err != nil {
+		t.Fatal(err)
+	}
+}
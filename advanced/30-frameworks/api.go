@@ -0,0 +1,253 @@
+package frameworks
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const maxBodyBytes = 1 << 20
+
+// Route adalah definisi endpoint yang netral terhadap framework. Path memakai
+// pola net/http Go 1.22 ("/customers/{id}") dan handler membaca parameter
+// lewat r.PathValue, sehingga adapter cukup menerjemahkan pola dan mengisi
+// path value dari router masing-masing.
+type Route struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+}
+
+type API struct {
+	store *Store
+}
+
+func NewAPI(store *Store) *API {
+	return &API{store: store}
+}
+
+func (a *API) Routes() []Route {
+	return []Route{
+		{http.MethodPost, "/users", a.createUser},
+		{http.MethodGet, "/users/{username}", a.getUser},
+		{http.MethodGet, "/customers", a.listCustomers},
+		{http.MethodPost, "/customers", a.createCustomer},
+		{http.MethodGet, "/customers/{id}", a.getCustomer},
+		{http.MethodPut, "/customers/{id}", a.updateCustomer},
+		{http.MethodDelete, "/customers/{id}", a.deleteCustomer},
+	}
+}
+
+// Handler memasang semua route ke http.ServeMux biasa.
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, route := range a.Routes() {
+		mux.HandleFunc(route.Method+" "+route.Path, route.Handler)
+	}
+
+	return mux
+}
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]{3,32}$`)
+
+type createUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (req createUserRequest) validate() ValidationErrors {
+	var errs ValidationErrors
+	if !usernamePattern.MatchString(req.Username) {
+		errs.Add("username", "must be 3-32 characters of letters, digits or underscore")
+	}
+	if len(req.Password) < 8 {
+		errs.Add("password", "must be at least 8 characters")
+	}
+	// bcrypt hanya memakai 72 byte pertama, lebih dari itu ditolak daripada
+	// dipotong diam-diam
+	if len(req.Password) > 72 {
+		errs.Add("password", "must be at most 72 bytes")
+	}
+
+	return errs
+}
+
+func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationProblem(w, r, errs)
+		return
+	}
+
+	// password tidak pernah disimpan apa adanya, hanya hash bcrypt-nya
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		writeProblem(w, r, Problem{Status: http.StatusInternalServerError})
+		return
+	}
+
+	user, err := a.store.CreateUser(User{Username: req.Username, PasswordHash: hash})
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (a *API) getUser(w http.ResponseWriter, r *http.Request) {
+	user, err := a.store.FindUser(r.PathValue("username"))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+type customerRequest struct {
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Balance int32   `json:"balance"`
+	Rating  float64 `json:"rating"`
+	Married bool    `json:"married"`
+}
+
+func (req customerRequest) validate() ValidationErrors {
+	var errs ValidationErrors
+	if strings.TrimSpace(req.Name) == "" {
+		errs.Add("name", "is required")
+	}
+	if at := strings.Index(req.Email, "@"); at < 1 || at == len(req.Email)-1 {
+		errs.Add("email", "must be a valid email address")
+	}
+	if req.Balance < 0 {
+		errs.Add("balance", "must not be negative")
+	}
+	if req.Rating < 0 || req.Rating > 5 {
+		errs.Add("rating", "must be between 0 and 5")
+	}
+
+	return errs
+}
+
+func (req customerRequest) toCustomer(id string) Customer {
+	return Customer{
+		Id:      id,
+		Name:    req.Name,
+		Email:   req.Email,
+		Balance: req.Balance,
+		Rating:  req.Rating,
+		Married: req.Married,
+	}
+}
+
+func (a *API) listCustomers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.store.ListCustomers())
+}
+
+func (a *API) createCustomer(w http.ResponseWriter, r *http.Request) {
+	var req customerRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationProblem(w, r, errs)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, a.store.CreateCustomer(req.toCustomer("")))
+}
+
+func (a *API) getCustomer(w http.ResponseWriter, r *http.Request) {
+	customer, err := a.store.FindCustomer(r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customer)
+}
+
+func (a *API) updateCustomer(w http.ResponseWriter, r *http.Request) {
+	var req customerRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		writeValidationProblem(w, r, errs)
+		return
+	}
+
+	customer, err := a.store.UpdateCustomer(req.toCustomer(r.PathValue("id")))
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customer)
+}
+
+func (a *API) deleteCustomer(w http.ResponseWriter, r *http.Request) {
+	if err := a.store.DeleteCustomer(r.PathValue("id")); err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeBody membaca body JSON. Kalau gagal, problem sudah ditulis dan
+// fungsi mengembalikan false.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+		writeProblem(w, r, Problem{
+			Status: http.StatusUnsupportedMediaType,
+			Detail: "Content-Type must be application/json",
+		})
+		return false
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		if errors.Is(err, io.EOF) {
+			err = errors.New("request body is empty")
+		}
+
+		writeProblem(w, r, Problem{
+			Type:   "/problems/malformed-body",
+			Title:  "Malformed request body",
+			Status: status,
+			Detail: err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeProblem(w, r, Problem{Status: http.StatusNotFound, Detail: "resource not found"})
+	case errors.Is(err, ErrConflict):
+		writeProblem(w, r, Problem{Status: http.StatusConflict, Detail: "resource already exists"})
+	default:
+		writeProblem(w, r, Problem{Status: http.StatusInternalServerError})
+	}
+}
@@ -0,0 +1,205 @@
+package frameworks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type response struct {
+	Code        int
+	ContentType string
+	Body        []byte
+}
+
+type stack struct {
+	name string
+	do   func(*http.Request) response
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func recorderDo(h http.Handler) func(*http.Request) response {
+	return func(r *http.Request) response {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return response{rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()}
+	}
+}
+
+// stacks membuat store baru per framework, jadi tiap framework diuji dengan
+// urutan request yang sama dari kondisi kosong.
+func stacks(t *testing.T) []stack {
+	t.Helper()
+
+	app := NewFiber(NewAPI(NewStore()))
+	fiberDo := func(r *http.Request) response {
+		res, err := app.Test(r, -1)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, _ := io.ReadAll(res.Body)
+		return response{res.StatusCode, res.Header.Get("Content-Type"), body}
+	}
+
+	return []stack{
+		{"net/http", recorderDo(NewAPI(NewStore()).Handler())},
+		{"gin", recorderDo(NewGin(NewAPI(NewStore())))},
+		{"echo", recorderDo(NewEcho(NewAPI(NewStore())))},
+		{"fiber", fiberDo},
+	}
+}
+
+func newRequest(method, path, body string) *http.Request {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, path, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req
+}
+
+func TestConformance(t *testing.T) {
+	for _, s := range stacks(t) {
+		t.Run(s.name, func(t *testing.T) {
+			res := s.do(newRequest(http.MethodPost, "/users", `{"username":"bisma","password":"rahasia123"}`))
+			require.Equal(t, http.StatusCreated, res.Code, string(res.Body))
+			assert.NotContains(t, string(res.Body), "password")
+
+			res = s.do(newRequest(http.MethodPost, "/users", `{"username":"bisma","password":"rahasia123"}`))
+			assert.Equal(t, http.StatusConflict, res.Code)
+			assert.Equal(t, ProblemContentType, res.ContentType)
+
+			res = s.do(newRequest(http.MethodGet, "/users/bisma", ""))
+			require.Equal(t, http.StatusOK, res.Code)
+			var user User
+			require.NoError(t, json.Unmarshal(res.Body, &user))
+			assert.Equal(t, "bisma", user.Username)
+			assert.NotContains(t, string(res.Body), "password")
+
+			res = s.do(newRequest(http.MethodPost, "/customers", `{"name":"Budi","email":"budi@example.com","balance":1000,"rating":4.5}`))
+			require.Equal(t, http.StatusCreated, res.Code, string(res.Body))
+			var created Customer
+			require.NoError(t, json.Unmarshal(res.Body, &created))
+			assert.Equal(t, "1", created.Id)
+
+			res = s.do(newRequest(http.MethodPut, "/customers/1", `{"name":"Budi S","email":"budi@example.com","balance":2000,"rating":5,"married":true}`))
+			require.Equal(t, http.StatusOK, res.Code, string(res.Body))
+			var updated Customer
+			require.NoError(t, json.Unmarshal(res.Body, &updated))
+			assert.Equal(t, "Budi S", updated.Name)
+			assert.True(t, updated.Married)
+			assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+			res = s.do(newRequest(http.MethodGet, "/customers", ""))
+			require.Equal(t, http.StatusOK, res.Code)
+			var list []Customer
+			require.NoError(t, json.Unmarshal(res.Body, &list))
+			assert.Len(t, list, 1)
+
+			res = s.do(newRequest(http.MethodDelete, "/customers/1", ""))
+			assert.Equal(t, http.StatusNoContent, res.Code)
+
+			res = s.do(newRequest(http.MethodGet, "/customers/1", ""))
+			assert.Equal(t, http.StatusNotFound, res.Code)
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(res.Body, &problem))
+			assert.Equal(t, Problem{
+				Type:     "about:blank",
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "resource not found",
+				Instance: "/customers/1",
+			}, problem)
+		})
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		fields []string
+	}{
+		{"malformed json", http.MethodPost, "/customers", `{"name":`, http.StatusBadRequest, nil},
+		{"unknown field", http.MethodPost, "/customers", `{"nama":"Budi"}`, http.StatusBadRequest, nil},
+		{"empty body", http.MethodPost, "/users", "", http.StatusBadRequest, nil},
+		{"invalid user", http.MethodPost, "/users", `{"username":"b!","password":"123"}`, http.StatusUnprocessableEntity, []string{"username", "password"}},
+		{"invalid customer", http.MethodPost, "/customers", `{"name":" ","email":"budi","balance":-1,"rating":6}`, http.StatusUnprocessableEntity, []string{"name", "email", "balance", "rating"}},
+		{"update missing", http.MethodPut, "/customers/99", `{"name":"Budi","email":"budi@example.com"}`, http.StatusNotFound, nil},
+		{"unknown user", http.MethodGet, "/users/nobody", "", http.StatusNotFound, nil},
+	}
+
+	for _, s := range stacks(t) {
+		for _, c := range cases {
+			t.Run(s.name+"/"+c.name, func(t *testing.T) {
+				res := s.do(newRequest(c.method, c.path, c.body))
+				require.Equal(t, c.code, res.Code, string(res.Body))
+				assert.Equal(t, ProblemContentType, res.ContentType)
+
+				var problem Problem
+				require.NoError(t, json.Unmarshal(res.Body, &problem))
+				assert.Equal(t, c.code, problem.Status)
+				assert.Equal(t, c.path, problem.Instance)
+
+				var fields []string
+				for _, e := range problem.Errors {
+					fields = append(fields, e.Field)
+				}
+				assert.Equal(t, c.fields, fields)
+			})
+		}
+	}
+}
+
+func TestUnsupportedContentType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader("name=Budi"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	NewAPI(NewStore()).Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestCreateUserHashesPassword(t *testing.T) {
+	store := NewStore()
+	do := recorderDo(NewAPI(store).Handler())
+
+	res := do(newRequest(http.MethodPost, "/users", `{"username":"bisma","password":"rahasia123"}`))
+	require.Equal(t, http.StatusCreated, res.Code, string(res.Body))
+
+	user, err := store.FindUser("bisma")
+	require.NoError(t, err)
+	assert.NotContains(t, string(user.PasswordHash), "rahasia123")
+	assert.NoError(t, bcrypt.CompareHashAndPassword(user.PasswordHash, []byte("rahasia123")))
+	assert.Error(t, bcrypt.CompareHashAndPassword(user.PasswordHash, []byte("salah12345")))
+
+	res = do(newRequest(http.MethodPost, "/users", `{"username":"panjang","password":"`+strings.Repeat("a", 73)+`"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code, string(res.Body))
+}
+
+func TestColonPath(t *testing.T) {
+	assert.Equal(t, "/customers/:id", colonPath("/customers/{id}"))
+	assert.Equal(t, "/a/:x/b/:y", colonPath("/a/{x}/b/{y}"))
+	assert.Equal(t, "/users", colonPath("/users"))
+}
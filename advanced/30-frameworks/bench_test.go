@@ -0,0 +1,97 @@
+package frameworks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Benchmark membandingkan overhead routing (GET dengan path param) dan
+// decode/encode JSON (POST body) di keempat stack. Setiap run (termasuk run
+// percobaan saat testing mencari b.N) memakai store baru yang sudah diisi,
+// supaya GET selalu menemukan data dan POST di BenchmarkJSON tidak membuat
+// store terus membesar dari run ke run.
+//
+//	go test -bench . -benchmem
+
+const benchCustomer = `{"name":"Budi","email":"budi@example.com","balance":1000,"rating":4.5}`
+
+type benchStack struct {
+	name  string
+	serve func(b *testing.B, method, path, body string)
+}
+
+func seededAPI() *API {
+	store := NewStore()
+	for i := 0; i < 100; i++ {
+		store.CreateCustomer(Customer{Name: "Budi", Email: "budi@example.com"})
+	}
+
+	return NewAPI(store)
+}
+
+func httpServe(newHandler func(*API) http.Handler) func(b *testing.B, method, path, body string) {
+	return func(b *testing.B, method, path, body string) {
+		h := newHandler(seededAPI())
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code >= 400 {
+				b.Fatalf("status %d", rec.Code)
+			}
+		}
+	}
+}
+
+// fiber dijalankan langsung lewat fasthttp.RequestCtx, bukan app.Test yang
+// membuka koneksi dan akan mendominasi hasil.
+func fiberServe(b *testing.B, method, path, body string) {
+	h := NewFiber(seededAPI()).Handler()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(path)
+		ctx.Request.Header.SetContentType("application/json")
+		ctx.Request.SetBodyString(body)
+		h(&ctx)
+		if ctx.Response.StatusCode() >= 400 {
+			b.Fatalf("status %d", ctx.Response.StatusCode())
+		}
+	}
+}
+
+func benchStacks() []benchStack {
+	return []benchStack{
+		{"net/http", httpServe(func(a *API) http.Handler { return a.Handler() })},
+		{"gin", httpServe(func(a *API) http.Handler { return NewGin(a) })},
+		{"echo", httpServe(func(a *API) http.Handler { return NewEcho(a) })},
+		{"fiber", fiberServe},
+	}
+}
+
+func BenchmarkRouting(b *testing.B) {
+	for _, s := range benchStacks() {
+		b.Run(s.name, func(b *testing.B) {
+			s.serve(b, http.MethodGet, "/customers/42", "")
+		})
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	for _, s := range benchStacks() {
+		b.Run(s.name, func(b *testing.B) {
+			s.serve(b, http.MethodPost, "/customers", benchCustomer)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package frameworks
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewEcho memasang route API ke instance echo.
+func NewEcho(api *API) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	for _, route := range api.Routes() {
+		e.Add(route.Method, colonPath(route.Path), echoHandler(route.Handler))
+	}
+
+	return e
+}
+
+func echoHandler(h http.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := c.Request()
+		values := c.ParamValues()
+		for i, name := range c.ParamNames() {
+			if i < len(values) {
+				r.SetPathValue(name, values[i])
+			}
+		}
+		h(c.Response(), r)
+
+		return nil
+	}
+}
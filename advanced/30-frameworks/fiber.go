@@ -0,0 +1,47 @@
+package frameworks
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// NewFiber memasang route API ke app fiber. Fiber jalan di atas fasthttp,
+// jadi handler net/http dibungkus adaptor yang membangun *http.Request baru
+// untuk tiap request.
+func NewFiber(api *API) *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	for _, route := range api.Routes() {
+		app.Add(route.Method, colonPath(route.Path), fiberHandler(route.Handler))
+	}
+
+	return app
+}
+
+func fiberHandler(h http.HandlerFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params := c.AllParams()
+
+		return adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range params {
+				r.SetPathValue(name, value)
+			}
+			h(w, r)
+		})(c)
+	}
+}
+
+// colonPath mengubah pola "/customers/{id}" menjadi "/customers/:id" yang
+// dipakai gin, echo dan fiber.
+func colonPath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			segments[i] = ":" + s[1:len(s)-1]
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
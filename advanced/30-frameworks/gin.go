@@ -0,0 +1,27 @@
+package frameworks
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewGin memasang route API ke engine gin. Handler tetap http.HandlerFunc,
+// parameter gin (c.Params) disalin ke r.SetPathValue.
+func NewGin(api *API) *gin.Engine {
+	r := gin.New()
+	for _, route := range api.Routes() {
+		r.Handle(route.Method, colonPath(route.Path), ginHandler(route.Handler))
+	}
+
+	return r
+}
+
+func ginHandler(h http.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, p := range c.Params {
+			c.Request.SetPathValue(p.Key, p.Value)
+		}
+		h(c.Writer, c.Request)
+	}
+}
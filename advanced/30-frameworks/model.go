@@ -0,0 +1,140 @@
+package frameworks
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already exists")
+)
+
+// User dan Customer mengikuti bentuk entity di advanced/24-database.
+type User struct {
+	Username string `json:"username"`
+	// PasswordHash adalah hash bcrypt, tidak pernah ikut ke response JSON.
+	PasswordHash []byte    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type Customer struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Balance   int32     `json:"balance"`
+	Rating    float64   `json:"rating"`
+	CreatedAt time.Time `json:"createdAt"`
+	Married   bool      `json:"married"`
+}
+
+// Store penyimpanan in-memory, cukup untuk contoh dan benchmark karena yang
+// dibandingkan adalah layer HTTP-nya.
+type Store struct {
+	mu        sync.RWMutex
+	users     map[string]User
+	customers map[string]Customer
+	nextID    int
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:     make(map[string]User),
+		customers: make(map[string]Customer),
+	}
+}
+
+func (s *Store) CreateUser(u User) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[u.Username]; ok {
+		return User{}, ErrConflict
+	}
+	u.CreatedAt = time.Now().UTC()
+	s.users[u.Username] = u
+
+	return u, nil
+}
+
+func (s *Store) FindUser(username string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[username]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+
+	return u, nil
+}
+
+func (s *Store) CreateCustomer(c Customer) Customer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	c.Id = strconv.Itoa(s.nextID)
+	c.CreatedAt = time.Now().UTC()
+	s.customers[c.Id] = c
+
+	return c
+}
+
+func (s *Store) FindCustomer(id string) (Customer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.customers[id]
+	if !ok {
+		return Customer{}, ErrNotFound
+	}
+
+	return c, nil
+}
+
+func (s *Store) ListCustomers() []Customer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Customer, 0, len(s.customers))
+	for _, c := range s.customers {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, _ := strconv.Atoi(list[i].Id)
+		b, _ := strconv.Atoi(list[j].Id)
+		return a < b
+	})
+
+	return list
+}
+
+func (s *Store) UpdateCustomer(c Customer) (Customer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.customers[c.Id]
+	if !ok {
+		return Customer{}, ErrNotFound
+	}
+	c.CreatedAt = old.CreatedAt
+	s.customers[c.Id] = c
+
+	return c, nil
+}
+
+func (s *Store) DeleteCustomer(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.customers[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.customers, id)
+
+	return nil
+}
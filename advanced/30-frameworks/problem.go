@@ -0,0 +1,69 @@
+package frameworks
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem adalah format error RFC 7807 (application/problem+json).
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+const ProblemContentType = "application/problem+json"
+
+// ValidationErrors dikumpulkan per field lalu dikirim sekaligus, jadi client
+// bisa menampilkan semua kesalahan input dalam satu response.
+type ValidationErrors []FieldError
+
+func (v *ValidationErrors) Add(field, message string) {
+	*v = append(*v, FieldError{Field: field, Message: message})
+}
+
+func (v ValidationErrors) Error() string {
+	if len(v) == 0 {
+		return "validation failed"
+	}
+
+	return v[0].Field + ": " + v[0].Message
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+func writeValidationProblem(w http.ResponseWriter, r *http.Request, errs ValidationErrors) {
+	writeProblem(w, r, Problem{
+		Type:   "/problems/validation",
+		Title:  "Validation failed",
+		Status: http.StatusUnprocessableEntity,
+		Detail: "request body contains invalid fields",
+		Errors: errs,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,89 @@
+package calc
+
+import (
+	"errors"
+	"math/big"
+)
+
+var builtins = map[string]Func{
+	"abs": {MinArgs: 1, MaxArgs: 1, Help: "abs(x) absolute value", Call: func(_ *Env, args []*big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Abs(args[0]), nil
+	}},
+	"sqrt": {MinArgs: 1, MaxArgs: 1, Help: "sqrt(x) square root, rounded to Precision digits", Call: sqrt},
+	"floor": {MinArgs: 1, MaxArgs: 1, Help: "floor(x) round down", Call: func(_ *Env, args []*big.Rat) (*big.Rat, error) {
+		return new(big.Rat).SetInt(floor(args[0])), nil
+	}},
+	"ceil": {MinArgs: 1, MaxArgs: 1, Help: "ceil(x) round up", Call: func(_ *Env, args []*big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Neg(new(big.Rat).SetInt(floor(new(big.Rat).Neg(args[0])))), nil
+	}},
+	"round": {MinArgs: 1, MaxArgs: 2, Help: "round(x[, digits]) round half away from zero", Call: round},
+	"min": {MinArgs: 1, MaxArgs: -1, Help: "min(a, b, ...) smallest argument", Call: func(_ *Env, args []*big.Rat) (*big.Rat, error) {
+		return pick(args, -1), nil
+	}},
+	"max": {MinArgs: 1, MaxArgs: -1, Help: "max(a, b, ...) largest argument", Call: func(_ *Env, args []*big.Rat) (*big.Rat, error) {
+		return pick(args, 1), nil
+	}},
+}
+
+// floor untuk big.Rat; big.Int.Div sudah membulatkan ke bawah (Euclidean)
+// karena penyebut selalu positif.
+func floor(v *big.Rat) *big.Int {
+	return new(big.Int).Div(v.Num(), v.Denom())
+}
+
+func sqrt(env *Env, args []*big.Rat) (*big.Rat, error) {
+	x := args[0]
+	if x.Sign() < 0 {
+		return nil, errors.New("square root of a negative number")
+	}
+
+	// hasil eksak kalau pembilang dan penyebut kuadrat sempurna, 9/4 -> 3/2
+	num, den := new(big.Int).Sqrt(x.Num()), new(big.Int).Sqrt(x.Denom())
+	if new(big.Int).Mul(num, num).Cmp(x.Num()) == 0 && new(big.Int).Mul(den, den).Cmp(x.Denom()) == 0 {
+		return new(big.Rat).SetFrac(num, den), nil
+	}
+
+	// ~3.33 bit per digit desimal, ditambah cadangan supaya digit terakhir
+	// tetap benar setelah dibulatkan
+	prec := uint(env.Precision)*4 + 64
+	f := new(big.Float).SetPrec(prec).SetRat(x)
+	f.Sqrt(f)
+	r, _ := f.Rat(nil)
+	return roundTo(r, env.Precision), nil
+}
+
+func round(env *Env, args []*big.Rat) (*big.Rat, error) {
+	digits := 0
+	if len(args) == 2 {
+		d, err := toInt(args[1])
+		if err != nil || d < 0 || d > 1000 {
+			return nil, errors.New("digits must be an integer between 0 and 1000")
+		}
+		digits = d
+	}
+	return roundTo(args[0], digits), nil
+}
+
+func roundTo(v *big.Rat, digits int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	scaled := new(big.Rat).Mul(v, new(big.Rat).SetInt(scale))
+
+	half := big.NewRat(1, 2)
+	if scaled.Sign() < 0 {
+		scaled.Sub(scaled, half)
+		n := floor(new(big.Rat).Neg(scaled))
+		return new(big.Rat).SetFrac(n.Neg(n), scale)
+	}
+	scaled.Add(scaled, half)
+	return new(big.Rat).SetFrac(floor(scaled), scale)
+}
+
+func pick(args []*big.Rat, want int) *big.Rat {
+	best := args[0]
+	for _, v := range args[1:] {
+		if v.Cmp(best) == want {
+			best = v
+		}
+	}
+	return best
+}
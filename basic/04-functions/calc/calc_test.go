@@ -0,0 +1,155 @@
+package calc
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func eval(t *testing.T, env *Env, input string) string {
+	t.Helper()
+	v, err := env.Eval(input)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	return env.Format(v)
+}
+
+func TestEvalPrecedenceAndPrecision(t *testing.T) {
+	env := NewEnv()
+	cases := map[string]string{
+		"1 + 2 * 3":                 "7",
+		"(1 + 2) * 3":               "9",
+		"2 ^ 3 ^ 2":                 "512",
+		"-2 ^ 2":                    "-4",
+		"(-2) ^ 2":                  "4",
+		"2 ^ -2":                    "0.25",
+		"--3":                       "3",
+		"10 - 4 - 3":                "3",
+		"7 % 3 * 2":                 "2",
+		"0.1 + 0.2":                 "0.3",
+		"1 / 3 * 3":                 "1",
+		"2 / 3":                     "0.666666666666666666666666666667",
+		"1_000_000 * 1.5e3":         "1500000000",
+		"2 ^ 100":                   "1267650600228229401496703205376",
+		"99999999999999999 + 1":     "100000000000000000",
+		"sqrt(2)":                   "1.41421356237309504880168872421",
+		"sqrt(9 / 4)":               "1.5",
+		"round(pi, 4)":              "3.1416",
+		"round(-2.5)":               "-3",
+		"floor(-1.5) + ceil(1.2)":   "0",
+		"max(1, 5, 3) - min(4, -2)": "7",
+		"abs(-0.000001)":            "0.000001",
+	}
+	for input, want := range cases {
+		if got := eval(t, env, input); got != want {
+			t.Errorf("%s = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestFormatPrecision(t *testing.T) {
+	env := NewEnv()
+	cases := []struct {
+		precision int
+		input     string
+		want      string
+	}{
+		{0, "101 / 10", "10"},
+		{0, "1 / 3", "0"},
+		{0, "-1 / 3", "0"},
+		{0, "199 / 2", "100"},
+		{0, "1000.4", "1000"},
+		{2, "100.001", "100"},
+		{2, "1 / 8", "0.13"},
+	}
+	for _, c := range cases {
+		env.Precision = c.precision
+		if got := eval(t, env, c.input); got != c.want {
+			t.Errorf("precision %d: %s = %s, want %s", c.precision, c.input, got, c.want)
+		}
+	}
+}
+
+func TestEvalVariablesAndFunctions(t *testing.T) {
+	env := NewEnv()
+
+	eval(t, env, "price = 19.99")
+	eval(t, env, "qty = 3")
+	if got := eval(t, env, "total = price * qty"); got != "59.97" {
+		t.Fatalf("total %s", got)
+	}
+	if v, _ := env.Get("total"); env.Format(v) != "59.97" {
+		t.Fatal("assignment must store the value")
+	}
+
+	err := env.Register("tax", Func{MinArgs: 1, MaxArgs: 1, Call: func(env *Env, args []*big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Mul(args[0], big.NewRat(11, 100)), nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eval(t, env, "total + tax(total)"); got != "66.5667" {
+		t.Fatalf("with tax %s", got)
+	}
+
+	env.RegisterMathOperation("idiv", func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errors.New("divided by zero")
+		}
+		return a / b, nil
+	})
+	if got := eval(t, env, "idiv(7, 2)"); got != "3" {
+		t.Fatalf("idiv %s", got)
+	}
+}
+
+func TestErrorColumns(t *testing.T) {
+	env := NewEnv()
+	env.RegisterMathOperation("idiv", func(a, b int) (int, error) { return a / b, nil })
+
+	cases := []struct {
+		input  string
+		column int
+		msg    string
+	}{
+		{"2 * (3 + )", 10, `unexpected ")"`},
+		{"2 * (3 + 4", 11, `expected ")" to close "(" at column 5, got end of input`},
+		{"1 + 2 )", 7, `unexpected ")" after expression`},
+		{"1 + $", 5, `unexpected character '$'`},
+		{"1.2.3 + 1", 1, `invalid number "1.2.3"`},
+		{"10 / (5 - 5)", 4, "division by zero"},
+		{"x + 1", 1, "undefined variable x"},
+		{"1 + sqrt", 5, "sqrt is a function, call it as sqrt(...)"},
+		{"1 + foo(2)", 5, "undefined function foo"},
+		{"sqrt(1, 2)", 1, "sqrt expects 1 argument, got 2"},
+		{"3 + sqrt(-4)", 5, "sqrt: square root of a negative number"},
+		{"2 ^ 0.5", 3, "exponent must be an integer, got 0.5"},
+		{"10 ^ 10 ^ 10", 4, "result of ^ is too large"},
+		{"idiv(7.5, 2)", 1, "idiv: 15/2 is not an integer that fits in int"},
+		{"pi = 3", 1, "cannot assign to constant pi"},
+		{"max(1 2)", 7, `expected "," or ")" in call to max, got "2"`},
+	}
+	for _, c := range cases {
+		_, err := env.Eval(c.input)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s: expected *Error, got %v", c.input, err)
+			continue
+		}
+		if e.Column != c.column || e.Msg != c.msg {
+			t.Errorf("%s: got column %d %q, want column %d %q", c.input, e.Column, e.Msg, c.column, c.msg)
+		}
+	}
+
+	_, err := env.Eval("2 * (3 + )")
+	var e *Error
+	errors.As(err, &e)
+	if want := "2 * (3 + )\n         ^ unexpected \")\""; e.Pointer() != want {
+		t.Fatalf("pointer:\n%s\nwant:\n%s", e.Pointer(), want)
+	}
+	if !strings.HasPrefix(err.Error(), "calc: column 10:") {
+		t.Fatalf("error %v", err)
+	}
+}
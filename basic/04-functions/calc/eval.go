@@ -0,0 +1,326 @@
+// Package calc adalah mesin ekspresi untuk kalkulator: tokenizer, parser
+// dengan precedence, variabel, dan fungsi yang bisa didaftarkan sendiri.
+// Semua hitungan memakai big.Rat (pecahan eksak), jadi 0.1 + 0.2 = 0.3 dan
+// 1/3*3 = 1, tidak ada error pembulatan float64.
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"go-journey/basic/04-functions/utils"
+)
+
+// Func adalah fungsi yang bisa dipanggil dari ekspresi. MaxArgs -1 berarti
+// variadic.
+type Func struct {
+	MinArgs int
+	MaxArgs int
+	Help    string
+	Call    func(env *Env, args []*big.Rat) (*big.Rat, error)
+}
+
+type Env struct {
+	// Precision adalah jumlah digit di belakang koma untuk Format dan untuk
+	// fungsi yang hasilnya irasional seperti sqrt. Default 30.
+	Precision int
+
+	vars      map[string]*big.Rat
+	constants map[string]bool
+	funcs     map[string]Func
+}
+
+func NewEnv() *Env {
+	env := &Env{
+		Precision: 30,
+		vars:      make(map[string]*big.Rat),
+		constants: make(map[string]bool),
+		funcs:     make(map[string]Func),
+	}
+	env.constant("pi", "3.14159265358979323846264338327950288419716939937510582097494459")
+	env.constant("e", "2.71828182845904523536028747135266249775724709369995957496696763")
+	for name, f := range builtins {
+		env.funcs[name] = f
+	}
+	return env
+}
+
+func (env *Env) constant(name, value string) {
+	v, _ := new(big.Rat).SetString(value)
+	env.vars[name] = v
+	env.constants[name] = true
+}
+
+// Eval mengevaluasi satu baris. Bentuk "x = expr" menyimpan hasilnya ke
+// variabel x. Error parse maupun evaluasi bertipe *Error.
+func (env *Env) Eval(input string) (*big.Rat, error) {
+	st, err := parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.assign != "" {
+		if env.constants[st.assign] {
+			return nil, &Error{Input: input, Column: st.assignCol, Msg: fmt.Sprintf("cannot assign to constant %s", st.assign)}
+		}
+		if _, isFunc := env.funcs[st.assign]; isFunc {
+			return nil, &Error{Input: input, Column: st.assignCol, Msg: fmt.Sprintf("%s is a function", st.assign)}
+		}
+	}
+
+	v, err := st.expr.eval(env)
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			e.Input = input
+		}
+		return nil, err
+	}
+
+	if st.assign != "" {
+		env.vars[st.assign] = v
+	}
+	return new(big.Rat).Set(v), nil
+}
+
+func (env *Env) Set(name string, value *big.Rat) error {
+	if env.constants[name] {
+		return fmt.Errorf("calc: cannot assign to constant %s", name)
+	}
+	if _, isFunc := env.funcs[name]; isFunc {
+		return fmt.Errorf("calc: %s is a function", name)
+	}
+	env.vars[name] = new(big.Rat).Set(value)
+	return nil
+}
+
+func (env *Env) Get(name string) (*big.Rat, bool) {
+	v, ok := env.vars[name]
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).Set(v), true
+}
+
+// Vars mengembalikan nama semua variabel termasuk konstanta, terurut.
+func (env *Env) Vars() []string {
+	return sortedKeys(env.vars)
+}
+
+func (env *Env) Funcs() []string {
+	return sortedKeys(env.funcs)
+}
+
+func (env *Env) Func(name string) (Func, bool) {
+	f, ok := env.funcs[name]
+	return f, ok
+}
+
+func (env *Env) IsConstant(name string) bool {
+	return env.constants[name]
+}
+
+func (env *Env) Register(name string, f Func) error {
+	if _, isVar := env.vars[name]; isVar {
+		return fmt.Errorf("calc: %s is already a variable", name)
+	}
+	if f.Call == nil || f.MinArgs < 0 || (f.MaxArgs >= 0 && f.MaxArgs < f.MinArgs) {
+		return fmt.Errorf("calc: invalid definition for function %s", name)
+	}
+	env.funcs[name] = f
+	return nil
+}
+
+// RegisterMathOperation memakai MathOperation dari basic/04-functions
+// sebagai fungsi dua argumen. Argumennya harus bilangan bulat yang muat di
+// int, misalnya RegisterMathOperation("div", divide) lalu "div(7, 2)"
+// menghasilkan 3 seperti pembagian int di Go.
+func (env *Env) RegisterMathOperation(name string, op utils.MathOperation) error {
+	return env.Register(name, Func{
+		MinArgs: 2,
+		MaxArgs: 2,
+		Help:    name + "(a, b) integer operation",
+		Call: func(_ *Env, args []*big.Rat) (*big.Rat, error) {
+			a, err := toInt(args[0])
+			if err != nil {
+				return nil, err
+			}
+			b, err := toInt(args[1])
+			if err != nil {
+				return nil, err
+			}
+			result, err := op(a, b)
+			if err != nil {
+				return nil, err
+			}
+			return new(big.Rat).SetInt64(int64(result)), nil
+		},
+	})
+}
+
+// Format menampilkan bilangan bulat apa adanya dan pecahan sebagai desimal
+// dengan paling banyak Precision digit, tanpa nol di belakang.
+func (env *Env) Format(v *big.Rat) string {
+	if v.IsInt() {
+		return v.Num().String()
+	}
+	s := v.FloatString(env.Precision)
+	// dengan Precision 0 tidak ada titik desimal, dan nol di belakang adalah
+	// bagian dari bilangan bulatnya
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toInt(v *big.Rat) (int, error) {
+	if !v.IsInt() || !v.Num().IsInt64() || v.Num().Int64() > math.MaxInt || v.Num().Int64() < math.MinInt {
+		return 0, fmt.Errorf("%s is not an integer that fits in int", v.RatString())
+	}
+	return int(v.Num().Int64()), nil
+}
+
+func (n *numberNode) eval(*Env) (*big.Rat, error) {
+	return n.value, nil
+}
+
+func (n *varNode) eval(env *Env) (*big.Rat, error) {
+	v, ok := env.vars[n.name]
+	if !ok {
+		msg := fmt.Sprintf("undefined variable %s", n.name)
+		if _, isFunc := env.funcs[n.name]; isFunc {
+			msg = fmt.Sprintf("%s is a function, call it as %s(...)", n.name, n.name)
+		}
+		return nil, &Error{Column: n.col, Msg: msg}
+	}
+	return v, nil
+}
+
+func (n *unaryNode) eval(env *Env) (*big.Rat, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).Neg(v), nil
+}
+
+// maxPowBits membatasi ukuran hasil ^ (sekitar satu juta digit) supaya
+// 10^999999999 tidak menghabiskan memori.
+const maxPowBits = 1 << 22
+
+func (n *binaryNode) eval(env *Env) (*big.Rat, error) {
+	a, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	fail := func(format string, args ...any) (*big.Rat, error) {
+		return nil, &Error{Column: n.col, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	switch n.op {
+	case "+":
+		return new(big.Rat).Add(a, b), nil
+	case "-":
+		return new(big.Rat).Sub(a, b), nil
+	case "*":
+		return new(big.Rat).Mul(a, b), nil
+	case "/":
+		if b.Sign() == 0 {
+			return fail("division by zero")
+		}
+		return new(big.Rat).Quo(a, b), nil
+	case "%":
+		if !a.IsInt() || !b.IsInt() {
+			return fail("modulo needs integers")
+		}
+		if b.Sign() == 0 {
+			return fail("modulo by zero")
+		}
+		return new(big.Rat).SetInt(new(big.Int).Rem(a.Num(), b.Num())), nil
+	case "^":
+		if !b.IsInt() {
+			return fail("exponent must be an integer, got %s", env.Format(b))
+		}
+		bits := int64(max(a.Num().BitLen(), a.Denom().BitLen()))
+		if !b.Num().IsInt64() || (bits > 1 && new(big.Int).Abs(b.Num()).Cmp(big.NewInt(maxPowBits/bits)) > 0) {
+			return fail("result of ^ is too large")
+		}
+		exp := b.Num().Int64()
+		if exp < 0 && a.Sign() == 0 {
+			return fail("division by zero")
+		}
+		return pow(a, exp), nil
+	}
+	return fail("unknown operator %s", n.op)
+}
+
+func pow(base *big.Rat, exp int64) *big.Rat {
+	e := big.NewInt(exp)
+	if exp < 0 {
+		e.Neg(e)
+	}
+	num := new(big.Int).Exp(base.Num(), e, nil)
+	den := new(big.Int).Exp(base.Denom(), e, nil)
+	if exp < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den)
+}
+
+func (n *callNode) eval(env *Env) (*big.Rat, error) {
+	f, ok := env.funcs[n.name]
+	if !ok {
+		return nil, &Error{Column: n.col, Msg: fmt.Sprintf("undefined function %s", n.name)}
+	}
+	if len(n.args) < f.MinArgs || (f.MaxArgs >= 0 && len(n.args) > f.MaxArgs) {
+		return nil, &Error{Column: n.col, Msg: fmt.Sprintf("%s expects %s, got %d", n.name, arity(f), len(n.args))}
+	}
+
+	args := make([]*big.Rat, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	v, err := f.Call(env, args)
+	if err != nil {
+		return nil, &Error{Column: n.col, Msg: fmt.Sprintf("%s: %s", n.name, err)}
+	}
+	return v, nil
+}
+
+func arity(f Func) string {
+	switch {
+	case f.MaxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.MinArgs)
+	case f.MinArgs == f.MaxArgs && f.MinArgs == 1:
+		return "1 argument"
+	case f.MinArgs == f.MaxArgs:
+		return fmt.Sprintf("%d arguments", f.MinArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.MinArgs, f.MaxArgs)
+}
@@ -0,0 +1,110 @@
+package calc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Error adalah kesalahan parse atau evaluasi beserta posisi kolomnya
+// (dihitung dari 1, per rune) di input.
+type Error struct {
+	Input  string
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("calc: column %d: %s", e.Column, e.Msg)
+}
+
+// Pointer menampilkan input dengan tanda ^ di bawah kolom yang salah:
+//
+//	2 * (3 + )
+//	         ^ unexpected ")"
+func (e *Error) Pointer() string {
+	return fmt.Sprintf("%s\n%s^ %s", e.Input, strings.Repeat(" ", max(e.Column-1, 0)), e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp // + - * / % ^
+	tokLParen
+	tokRParen
+	tokComma
+	tokAssign
+)
+
+var punctuation = map[rune]tokenKind{
+	'+': tokOp, '-': tokOp, '*': tokOp, '/': tokOp, '%': tokOp, '^': tokOp,
+	'(': tokLParen, ')': tokRParen, ',': tokComma, '=': tokAssign,
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	col  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		col := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			// eksponen: 1e9, 2.5E-3
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), col: col})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), col: col})
+
+		default:
+			kind, ok := punctuation[r]
+			if !ok {
+				return nil, &Error{Input: input, Column: col, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: kind, text: string(r), col: col})
+			i++
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, col: len(runes) + 1}), nil
+}
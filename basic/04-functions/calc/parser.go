@@ -0,0 +1,214 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Grammar (precedence naik ke bawah):
+//
+//	statement := ident "=" expr | expr
+//	expr      := term (("+" | "-") term)*
+//	term      := unary (("*" | "/" | "%") unary)*
+//	unary     := ("-" | "+") unary | power
+//	power     := primary ("^" unary)?         // asosiatif kanan
+//	primary   := number | ident | ident "(" args ")" | "(" expr ")"
+//
+// Unary minus lebih lemah dari ^, jadi -2^2 = -4 seperti di matematika.
+
+type node interface {
+	eval(env *Env) (*big.Rat, error)
+}
+
+type numberNode struct{ value *big.Rat }
+
+type varNode struct {
+	name string
+	col  int
+}
+
+type unaryNode struct {
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	col         int
+	left, right node
+}
+
+type callNode struct {
+	name string
+	col  int
+	args []node
+}
+
+// statement adalah hasil parse satu baris: ekspresi, atau assignment kalau
+// assign tidak kosong.
+type statement struct {
+	assign    string
+	assignCol int
+	expr      node
+}
+
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func parse(input string) (*statement, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{input: input, tokens: tokens}
+
+	st := &statement{}
+	if p.peek().kind == tokIdent && p.tokens[p.pos+1].kind == tokAssign {
+		ident := p.next()
+		p.next()
+		st.assign, st.assignCol = ident.text, ident.col
+	}
+
+	if st.expr, err = p.expr(); err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "unexpected %s after expression", tok)
+	}
+	return st, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorAt(tok token, format string, args ...any) error {
+	return &Error{Input: p.input, Column: tok.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isOp(ops string) bool {
+	tok := p.peek()
+	return tok.kind == tokOp && strings.Contains(ops, tok.text)
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+-") {
+		op := p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, col: op.col, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*/%") {
+		op := p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, col: op.col, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.isOp("+-") {
+		op := p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op.text == "+" {
+			return operand, nil
+		}
+		return &unaryNode{operand: operand}, nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		op := p.next()
+		exp, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op.text, col: op.col, left: base, right: exp}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		value, ok := new(big.Rat).SetString(strings.ReplaceAll(tok.text, "_", ""))
+		if !ok || strings.Count(tok.text, ".") > 1 {
+			return nil, p.errorAt(tok, "invalid number %q", tok.text)
+		}
+		return &numberNode{value: value}, nil
+
+	case tokIdent:
+		if p.peek().kind != tokLParen {
+			return &varNode{name: tok.text, col: tok.col}, nil
+		}
+		p.next()
+		call := &callNode{name: tok.text, col: tok.col}
+		if p.peek().kind == tokRParen {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+
+			sep := p.next()
+			if sep.kind == tokRParen {
+				return call, nil
+			}
+			if sep.kind != tokComma {
+				return nil, p.errorAt(sep, "expected \",\" or \")\" in call to %s, got %s", tok.text, sep)
+			}
+		}
+
+	case tokLParen:
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected \")\" to close \"(\" at column %d, got %s", tok.col, closing)
+		}
+		return inner, nil
+	}
+
+	return nil, p.errorAt(tok, "unexpected %s", tok)
+}
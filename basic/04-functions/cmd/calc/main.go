@@ -0,0 +1,340 @@
+// Command calc adalah kalkulator REPL di atas package calc.
+//
+//	go run ./basic/04-functions/cmd/calc              # mode interaktif
+//	go run ./basic/04-functions/cmd/calc "2^64" "1/3" # evaluasi argumen lalu keluar
+//	echo "x = 2; x * 21" | go run ./basic/04-functions/cmd/calc
+//	go run ./basic/04-functions/cmd/calc -precision 5 -- "-1/3"
+//
+// Parsing flag berhenti di "--" atau di argumen pertama yang bukan flag
+// calc, jadi ekspresi yang diawali "-" seperti "-2^2" tetap dievaluasi.
+// Pakai "--" kalau ekspresinya bisa terbaca sebagai flag, misalnya
+// "-precision" untuk variabel precision.
+//
+// Mode non-interaktif (argumen atau stdin bukan terminal) keluar dengan
+// status 1 kalau ada ekspresi yang gagal.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go-journey/basic/04-functions/calc"
+	"go-journey/basic/04-functions/utils"
+)
+
+const prompt = "> "
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("calc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	historyPath := fs.String("history", defaultHistoryPath(), "file riwayat input, kosongkan untuk mematikan")
+	precision := fs.Int("precision", 30, "jumlah digit di belakang koma")
+	if err := fs.Parse(terminateFlags(fs, args)); err != nil {
+		return 2
+	}
+	if !validPrecision(*precision) {
+		fmt.Fprintln(stderr, errPrecision)
+		return 2
+	}
+
+	r := newREPL(stdout, stderr)
+	r.env.Precision = *precision
+
+	if fs.NArg() > 0 {
+		return r.batch(strings.NewReader(strings.Join(fs.Args(), "\n")))
+	}
+	if !isTerminal(stdin) {
+		return r.batch(stdin)
+	}
+
+	r.history = openHistory(*historyPath, stderr)
+	defer r.history.Close()
+	return r.interactive(stdin)
+}
+
+// terminateFlags menyisipkan "--" sebelum argumen pertama yang bukan flag
+// terdaftar, supaya ekspresi seperti "-7 % 3" tidak dibaca sebagai flag.
+func terminateFlags(fs *flag.FlagSet, args []string) []string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return args
+		}
+
+		name, hasValue := "", false
+		if strings.HasPrefix(arg, "-") {
+			name, _, hasValue = strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		}
+		if name == "h" || name == "help" {
+			continue
+		}
+		if name == "" || fs.Lookup(name) == nil {
+			return append(append(args[:i:i], "--"), args[i:]...)
+		}
+		if !hasValue {
+			// nilai flag ada di argumen berikutnya, misalnya "-precision -1"
+			i++
+		}
+	}
+	return args
+}
+
+const errPrecision = "precision must be an integer between 0 and 1000"
+
+// validPrecision dipakai flag -precision dan perintah :precision supaya
+// batasnya sama.
+func validPrecision(p int) bool {
+	return p >= 0 && p <= 1000
+}
+
+type repl struct {
+	env     *calc.Env
+	out     io.Writer
+	errOut  io.Writer
+	history *history
+}
+
+func newREPL(out, errOut io.Writer) *repl {
+	env := calc.NewEnv()
+	// pembagian integer ala Go dari MathOperation di basic/04-functions
+	env.RegisterMathOperation("idiv", utils.GetOperation("/"))
+	return &repl{env: env, out: out, errOut: errOut, history: &history{}}
+}
+
+// batch mengevaluasi setiap baris (atau bagian yang dipisah ";") dan
+// mencetak hasilnya. Baris yang gagal tidak menghentikan baris berikutnya.
+func (r *repl) batch(in io.Reader) int {
+	status := 0
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		for _, stmt := range strings.Split(scanner.Text(), ";") {
+			stmt = strings.TrimSpace(stmt)
+			if stmt == "" || strings.HasPrefix(stmt, "#") {
+				continue
+			}
+			if err := r.eval(stmt, -1); err != nil {
+				status = 1
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(r.errOut, "calc:", err)
+		return 1
+	}
+	return status
+}
+
+func (r *repl) interactive(in io.Reader) int {
+	fmt.Fprintln(r.out, "calc: ketik ekspresi, :help untuk bantuan, :quit untuk keluar")
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, prompt)
+		if !scanner.Scan() {
+			// Ctrl+D
+			fmt.Fprintln(r.out)
+			return 0
+		}
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		r.history.Add(line)
+
+		if strings.HasPrefix(line, ":") {
+			if quit := r.command(line); quit {
+				return 0
+			}
+			continue
+		}
+		if !strings.Contains(line, ";") {
+			// input sudah terlihat di layar, cukup tunjuk kolomnya di
+			// bawah prompt
+			indent := utf8.RuneCountInString(raw[:strings.Index(raw, line)])
+			r.eval(line, len(prompt)+indent)
+			continue
+		}
+		for _, stmt := range strings.Split(line, ";") {
+			if stmt = strings.TrimSpace(stmt); stmt != "" {
+				r.eval(stmt, -1)
+			}
+		}
+	}
+}
+
+// eval mencetak hasil atau error. caretOffset >= 0 berarti input sudah
+// tampil di layar dengan offset itu, jadi error cukup berupa tanda ^.
+func (r *repl) eval(input string, caretOffset int) error {
+	v, err := r.env.Eval(input)
+	if err != nil {
+		var e *calc.Error
+		switch {
+		case !errors.As(err, &e):
+			fmt.Fprintln(r.errOut, err)
+		case caretOffset >= 0:
+			fmt.Fprintf(r.errOut, "%s^ %s\n", strings.Repeat(" ", caretOffset+e.Column-1), e.Msg)
+		default:
+			fmt.Fprintf(r.errOut, "error: %s\n", e.Pointer())
+		}
+		return err
+	}
+
+	r.env.Set("ans", v)
+	fmt.Fprintln(r.out, r.env.Format(v))
+	return nil
+}
+
+const helpText = `Ekspresi:
+  1 + 2 * 3, (1 + 2) * 3, 2 ^ 10, -x, 7 % 3
+  x = 5                simpan ke variabel
+  ans                  hasil terakhir
+  sqrt(2), round(x, 2) panggil fungsi, lihat :funcs
+  a = 1; b = 2; a + b  beberapa ekspresi dalam satu baris
+
+Perintah:
+  :vars                daftar variabel
+  :funcs               daftar fungsi
+  :history [n]         n input terakhir (default 20)
+  :precision [n]       lihat atau ubah jumlah digit desimal
+  :help                bantuan ini
+  :quit                keluar (atau Ctrl+D)`
+
+// command menjalankan perintah ":..." dan mengembalikan true untuk keluar.
+func (r *repl) command(line string) bool {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case ":quit", ":q", ":exit":
+		return true
+
+	case ":help", ":h":
+		fmt.Fprintln(r.out, helpText)
+
+	case ":vars":
+		for _, name := range r.env.Vars() {
+			v, _ := r.env.Get(name)
+			suffix := ""
+			if r.env.IsConstant(name) {
+				suffix = " (const)"
+			}
+			fmt.Fprintf(r.out, "%-10s = %s%s\n", name, r.env.Format(v), suffix)
+		}
+
+	case ":funcs":
+		for _, name := range r.env.Funcs() {
+			f, _ := r.env.Func(name)
+			fmt.Fprintf(r.out, "%-8s %s\n", name, f.Help)
+		}
+
+	case ":history":
+		n := 20
+		if len(args) > 0 {
+			if parsed, err := strconv.Atoi(args[0]); err == nil && parsed > 0 {
+				n = parsed
+			}
+		}
+		entries := r.history.Last(n)
+		for i, entry := range entries {
+			fmt.Fprintf(r.out, "%4d  %s\n", r.history.Len()-len(entries)+i+1, entry)
+		}
+
+	case ":precision":
+		if len(args) > 0 {
+			p, err := strconv.Atoi(args[0])
+			if err != nil || !validPrecision(p) {
+				fmt.Fprintln(r.errOut, errPrecision)
+				break
+			}
+			r.env.Precision = p
+		}
+		fmt.Fprintln(r.out, r.env.Precision)
+
+	default:
+		fmt.Fprintf(r.errOut, "unknown command %s, try :help\n", name)
+	}
+	return false
+}
+
+// history menyimpan input di memori dan menambahkannya ke file supaya
+// :history tetap ada di sesi berikutnya.
+type history struct {
+	entries []string
+	file    *os.File
+}
+
+func openHistory(path string, stderr io.Writer) *history {
+	h := &history{}
+	if path == "" {
+		return h
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				h.entries = append(h.entries, line)
+			}
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		fmt.Fprintln(stderr, "calc: history disabled:", err)
+		return h
+	}
+	h.file = f
+	return h
+}
+
+func (h *history) Add(line string) {
+	h.entries = append(h.entries, line)
+	if h.file != nil {
+		fmt.Fprintln(h.file, line)
+	}
+}
+
+func (h *history) Last(n int) []string {
+	return h.entries[max(len(h.entries)-n, 0):]
+}
+
+func (h *history) Len() int { return len(h.entries) }
+
+func (h *history) Close() error {
+	if h.file == nil {
+		return nil
+	}
+	return h.file.Close()
+}
+
+func defaultHistoryPath() string {
+	if path := os.Getenv("CALC_HISTORY"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".calc_history")
+}
+
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
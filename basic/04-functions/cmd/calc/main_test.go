@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunNonInteractive(t *testing.T) {
+	var out, errOut bytes.Buffer
+	status := run([]string{"-history", "", "1 + 2", "idiv(7, 2)", "x = 0.1; x + 0.2"}, strings.NewReader(""), &out, &errOut)
+	if status != 0 || out.String() != "3\n3\n0.1\n0.3\n" {
+		t.Fatalf("status %d out %q err %q", status, out.String(), errOut.String())
+	}
+
+	out.Reset()
+	stdin := strings.NewReader("# komentar\nprice = 10\nprice * (1 +\nans * 2\n")
+	status = run([]string{"-precision", "2"}, stdin, &out, &errOut)
+	if status != 1 {
+		t.Fatalf("a failing line must give status 1, got %d", status)
+	}
+	if out.String() != "10\n20\n" {
+		t.Fatalf("later lines must still run, out %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "price * (1 +\n            ^ unexpected end of input") {
+		t.Fatalf("stderr %q", errOut.String())
+	}
+}
+
+func TestRunRejectsInvalidPrecision(t *testing.T) {
+	for _, p := range []string{"-1", "1001", "abc"} {
+		var out, errOut bytes.Buffer
+		status := run([]string{"-history", "", "-precision", p, "1/3"}, strings.NewReader(""), &out, &errOut)
+		if status != 2 || out.Len() != 0 {
+			t.Errorf("-precision %s: status %d out %q", p, status, out.String())
+		}
+	}
+
+	var out, errOut bytes.Buffer
+	if status := run([]string{"-history", "", "-precision", "1000", "1"}, strings.NewReader(""), &out, &errOut); status != 0 {
+		t.Fatalf("-precision 1000 must be accepted, status %d err %q", status, errOut.String())
+	}
+
+	out.Reset()
+	status := run([]string{"-history", "", "-precision", "0", "101/10", "1/3", "2000.5"}, strings.NewReader(""), &out, &errOut)
+	if status != 0 || out.String() != "10\n0\n2001\n" {
+		t.Fatalf("-precision 0: status %d out %q err %q", status, out.String(), errOut.String())
+	}
+}
+
+func TestInteractiveSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	session := func(input string) (string, string) {
+		var out, errOut bytes.Buffer
+		r := newREPL(&out, &errOut)
+		r.history = openHistory(path, &errOut)
+		defer r.history.Close()
+		if status := r.interactive(strings.NewReader(input)); status != 0 {
+			t.Fatalf("status %d", status)
+		}
+		return out.String(), errOut.String()
+	}
+
+	out, errOut := session("rate = 1.5\n  2 * / rate\n:precision 3\n1/3\n:vars\n:quit\nnot reached\n")
+	for _, want := range []string{"> 1.5\n", "> 3\n> 0.333\n", "rate       = 1.5\n", "pi         = 3.142 (const)\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output must contain %q:\n%s", want, out)
+		}
+	}
+	// 2 karakter prompt + 2 spasi indent + kolom 5
+	if errOut != "        ^ unexpected \"/\"\n" {
+		t.Errorf("caret must line up under the input, got %q", errOut)
+	}
+
+	out, _ = session(":history 3\n:funcs\n:nope\n")
+	if !strings.Contains(out, "   5  :vars\n   6  :quit\n   7  :history 3\n") {
+		t.Errorf("history must survive sessions:\n%s", out)
+	}
+	if !strings.Contains(out, "idiv     idiv(a, b) integer operation") {
+		t.Errorf(":funcs must list registered functions:\n%s", out)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || strings.Count(string(data), "\n") != 9 {
+		t.Fatalf("history file %q %v", data, err)
+	}
+}
+
+func TestRunNegativeExpressionArgs(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-history", "", "-2^2", "-7 % 3"}, "-4\n-1\n"},
+		{[]string{"-history=", "-precision=2", "-1/3"}, "-0.33\n"},
+		{[]string{"-history", "", "--", "-2 * 3"}, "-6\n"},
+	}
+
+	for _, c := range cases {
+		var out, errOut bytes.Buffer
+		status := run(c.args, strings.NewReader(""), &out, &errOut)
+		if status != 0 || out.String() != c.want {
+			t.Errorf("%q: status %d out %q err %q", c.args, status, out.String(), errOut.String())
+		}
+	}
+}
@@ -209,3 +209,9 @@ func getOperation(op string) MathOperation {
 		return nil
 	}
 }
+
+// GetOperation versi exported dari getOperation, dipakai kalkulator di
+// cmd/calc. Mengembalikan nil kalau operator tidak dikenal.
+func GetOperation(op string) MathOperation {
+	return getOperation(op)
+}
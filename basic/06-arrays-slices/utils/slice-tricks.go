@@ -1,8 +1,258 @@
 package utils
 
-import "go-journey/basic/helper"
+import (
+	"cmp"
+	"fmt"
+	"go-journey/basic/helper"
+	"slices"
+	"strings"
+)
+
+// Kumpulan SliceTricks klasik (github.com/golang/go/wiki/SliceTricks) versi
+// generic. Prinsipnya:
+//   - fungsi "InPlace" memakai ulang backing array input, jadi input tidak
+//     boleh dipakai lagi setelahnya, hanya hasil return-nya
+//   - slot yang tidak terpakai di ekor selalu dinolkan supaya pointer di
+//     dalamnya tidak menahan memori (memory leak)
+//   - hasil yang berbagi memori dengan input dibatasi cap-nya (s[i:j:j])
+//     supaya append ke hasil tidak menimpa elemen tetangga
 
 func CallSliceTrickLearnSection() {
-	helper.CalledFunction("CallSliceLearnSection")
+	helper.CalledFunction("CallSliceTrickLearnSection")
+
+	fmt.Println("=== SLICE TRICKS ===")
+
+	nums := []int{1, 2, 3, 4, 5}
+	nums = InsertAt(nums, 2, 10, 11)
+	fmt.Printf("InsertAt index 2: %v\n", nums)
+
+	nums = DeleteAt(nums, 0)
+	fmt.Printf("DeleteAt index 0: %v\n", nums)
+
+	nums = FilterInPlace(nums, func(n int) bool { return n%2 == 0 })
+	fmt.Printf("FilterInPlace genap: %v\n", nums)
+
+	fmt.Printf("Dedupe: %v\n", Dedupe([]string{"go", "rust", "go", "zig", "rust"}))
+	fmt.Printf("DedupeSorted: %v\n", DedupeSorted([]int{1, 1, 2, 3, 3, 3, 4}))
+
+	fmt.Printf("Chunk 2: %v\n", Chunk([]int{1, 2, 3, 4, 5}, 2))
+	fmt.Printf("Window 3: %v\n", Window([]int{1, 2, 3, 4, 5}, 3))
+
+	even, odd := Partition([]int{1, 2, 3, 4, 5, 6}, func(n int) bool { return n%2 == 0 })
+	fmt.Printf("Partition: genap %v ganjil %v\n", even, odd)
+
+	fmt.Printf("Flatten: %v\n", Flatten([][]int{{1, 2}, {}, {3}, {4, 5}}))
+
+	pairs := Zip([]string{"a", "b", "c"}, []int{1, 2, 3})
+	letters, numbers := Unzip(pairs)
+	fmt.Printf("Zip: %v, Unzip: %v %v\n", pairs, letters, numbers)
+
+	words := []string{"go", "java", "c", "rust", "zig", "python"}
+	byLen := GroupBy(words, func(w string) int { return len(w) })
+	fmt.Printf("GroupBy panjang: %v\n", byLen)
+
+	SortByKey(words, strings.ToUpper)
+	fmt.Printf("SortByKey: %v\n", words)
+	fmt.Println()
+}
+
+// InsertAt menyisipkan values di index i. Kalau kapasitas cukup tidak ada
+// alokasi; versi naif append(s[:i], append(values, s[i:]...)...) selalu
+// membuat slice sementara.
+func InsertAt[T any](s []T, i int, values ...T) []T {
+	n := len(values)
+	if n == 0 {
+		return s
+	}
+	s = slices.Grow(s, n)
+	s = s[:len(s)+n]
+	copy(s[i+n:], s[i:])
+	copy(s[i:], values)
+	return s
+}
+
+// DeleteAt menghapus elemen di index i dengan menjaga urutan.
+func DeleteAt[T any](s []T, i int) []T {
+	return DeleteRange(s, i, i+1)
+}
+
+// DeleteRange menghapus s[i:j]. Ekor yang tergeser dinolkan, sesuatu yang
+// tidak dilakukan append(s[:i], s[j:]...).
+func DeleteRange[T any](s []T, i, j int) []T {
+	n := copy(s[i:], s[j:])
+	clear(s[i+n:])
+	return s[:i+n]
+}
+
+// DeleteUnordered menghapus index i dalam O(1) dengan memindahkan elemen
+// terakhir ke posisinya. Urutan tidak dijaga.
+func DeleteUnordered[T any](s []T, i int) []T {
+	last := len(s) - 1
+	s[i] = s[last]
+	var zero T
+	s[last] = zero
+	return s[:last]
+}
+
+// FilterInPlace menyimpan elemen yang lolos keep tanpa alokasi.
+func FilterInPlace[T any](s []T, keep func(T) bool) []T {
+	n := 0
+	for _, v := range s {
+		if keep(v) {
+			s[n] = v
+			n++
+		}
+	}
+	clear(s[n:])
+	return s[:n]
+}
+
+// DedupeSorted membuang duplikat berurutan (input harus sudah di-sort)
+// tanpa alokasi.
+func DedupeSorted[T comparable](s []T) []T {
+	if len(s) < 2 {
+		return s
+	}
+	n := 1
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[n-1] {
+			s[n] = s[i]
+			n++
+		}
+	}
+	clear(s[n:])
+	return s[:n]
+}
+
+// Dedupe membuang duplikat dari slice yang belum di-sort, menyimpan
+// kemunculan pertama. Memakai map sehingga O(n) tapi ada alokasi.
+func Dedupe[T comparable](s []T) []T {
+	seen := make(map[T]struct{}, len(s))
+	return FilterInPlace(s, func(v T) bool {
+		if _, dup := seen[v]; dup {
+			return false
+		}
+		seen[v] = struct{}{}
+		return true
+	})
+}
+
+// Chunk membagi s menjadi potongan berukuran size (potongan terakhir bisa
+// lebih pendek). Potongan berbagi memori dengan s.
+func Chunk[T any](s []T, size int) [][]T {
+	if size <= 0 {
+		panic("utils: chunk size must be positive")
+	}
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for size < len(s) {
+		s, chunks = s[size:], append(chunks, s[:size:size])
+	}
+	if len(s) > 0 {
+		chunks = append(chunks, s[:len(s):len(s)])
+	}
+	return chunks
+}
+
+// Window mengembalikan semua sliding window berukuran size, misalnya untuk
+// moving average. Window berbagi memori dengan s.
+func Window[T any](s []T, size int) [][]T {
+	if size <= 0 {
+		panic("utils: window size must be positive")
+	}
+	if len(s) < size {
+		return nil
+	}
+	windows := make([][]T, 0, len(s)-size+1)
+	for i := 0; i+size <= len(s); i++ {
+		windows = append(windows, s[i:i+size:i+size])
+	}
+	return windows
+}
+
+// Partition memisahkan elemen yang memenuhi pred dan yang tidak, keduanya
+// dengan urutan asli. Hanya satu alokasi untuk kedua hasil.
+func Partition[T any](s []T, pred func(T) bool) (matched, rest []T) {
+	buf := make([]T, len(s))
+	front, back := 0, len(s)
+	for _, v := range s {
+		if pred(v) {
+			buf[front] = v
+			front++
+		} else {
+			back--
+			buf[back] = v
+		}
+	}
+	rest = buf[front:]
+	slices.Reverse(rest)
+	return buf[:front:front], rest
+}
+
+// Flatten menggabungkan slice of slice dengan satu alokasi karena total
+// panjang dihitung lebih dulu.
+func Flatten[T any](ss [][]T) []T {
+	total := 0
+	for _, s := range ss {
+		total += len(s)
+	}
+	out := make([]T, 0, total)
+	for _, s := range ss {
+		out = append(out, s...)
+	}
+	return out
+}
+
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+func (p Pair[A, B]) String() string {
+	return fmt.Sprintf("(%v, %v)", p.First, p.Second)
+}
+
+// Zip memasangkan a[i] dengan b[i]; panjang hasil mengikuti slice terpendek.
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	n := min(len(a), len(b))
+	out := make([]Pair[A, B], n)
+	for i := range out {
+		out[i] = Pair[A, B]{a[i], b[i]}
+	}
+	return out
+}
+
+func Unzip[A, B any](pairs []Pair[A, B]) ([]A, []B) {
+	as, bs := make([]A, len(pairs)), make([]B, len(pairs))
+	for i, p := range pairs {
+		as[i], bs[i] = p.First, p.Second
+	}
+	return as, bs
+}
+
+// GroupBy mengelompokkan elemen berdasarkan key; urutan dalam tiap grup
+// mengikuti urutan input.
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, v := range s {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
 
+// SortByKey mengurutkan s secara stabil berdasarkan key. Key dihitung sekali
+// per elemen (n kali), bukan di setiap perbandingan (n log n kali) seperti
+// sort.SliceStable(s, func(i, j) bool { return key(s[i]) < key(s[j]) }),
+// berguna kalau key mahal seperti strings.ToLower.
+func SortByKey[T any, K cmp.Ordered](s []T, key func(T) K) {
+	keyed := make([]Pair[K, T], len(s))
+	for i, v := range s {
+		keyed[i] = Pair[K, T]{key(v), v}
+	}
+	slices.SortStableFunc(keyed, func(a, b Pair[K, T]) int {
+		return cmp.Compare(a.First, b.First)
+	})
+	for i, p := range keyed {
+		s[i] = p.Second
+	}
 }
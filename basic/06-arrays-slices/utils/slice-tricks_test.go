@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestInsertAndDelete(t *testing.T) {
+	s := make([]int, 0, 10)
+	s = append(s, 1, 2, 5)
+	before := &s[0]
+	s = InsertAt(s, 2, 3, 4)
+	if !slices.Equal(s, []int{1, 2, 3, 4, 5}) || &s[0] != before {
+		t.Fatalf("InsertAt with spare capacity must reuse the array: %v", s)
+	}
+	s = InsertAt(s, 5, 6)
+	s = InsertAt(s, 0, 0)
+	if !slices.Equal(s, []int{0, 1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("insert at edges: %v", s)
+	}
+
+	s = DeleteRange(s, 1, 3)
+	if !slices.Equal(s, []int{0, 3, 4, 5, 6}) {
+		t.Fatalf("DeleteRange: %v", s)
+	}
+	s = DeleteUnordered(s, 0)
+	if !slices.Equal(s, []int{6, 3, 4, 5}) {
+		t.Fatalf("DeleteUnordered: %v", s)
+	}
+}
+
+func TestDeleteClearsTail(t *testing.T) {
+	a, b, c := "a", "b", "c"
+	ptrs := []*string{&a, &b, &c}
+
+	ptrs = DeleteAt(ptrs, 0)
+	if tail := ptrs[:cap(ptrs)][2]; tail != nil {
+		t.Fatal("DeleteAt must nil the vacated slot so the GC can free it")
+	}
+
+	ptrs = FilterInPlace(ptrs, func(p *string) bool { return *p == "c" })
+	if len(ptrs) != 1 || ptrs[:cap(ptrs)][1] != nil {
+		t.Fatal("FilterInPlace must nil the tail")
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	if got := DedupeSorted([]int{1, 1, 1, 2, 3, 3}); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("DedupeSorted %v", got)
+	}
+	if got := Dedupe([]string{"b", "a", "b", "c", "a"}); !slices.Equal(got, []string{"b", "a", "c"}) {
+		t.Fatalf("Dedupe must keep first occurrences in order, got %v", got)
+	}
+	if got := DedupeSorted([]int{}); len(got) != 0 {
+		t.Fatal("empty")
+	}
+}
+
+func TestChunkAndWindowAreCapLimited(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6, 7}
+
+	chunks := Chunk(s, 3)
+	if fmt.Sprint(chunks) != "[[1 2 3] [4 5 6] [7]]" {
+		t.Fatalf("chunks %v", chunks)
+	}
+	chunks[0] = append(chunks[0], 99)
+	if s[3] != 4 {
+		t.Fatal("appending to a chunk must not overwrite the next chunk")
+	}
+	// potongan terakhir juga dibatasi, s di sini punya sisa kapasitas
+	buf := make([]int, 4, 8)
+	last := Chunk(buf, 3)[1]
+	_ = append(last, 99)
+	if buf[:5][4] != 0 {
+		t.Fatal("appending to the last chunk must not write into the spare capacity of s")
+	}
+
+	windows := Window(s, 5)
+	if fmt.Sprint(windows) != "[[1 2 3 4 5] [2 3 4 5 6] [3 4 5 6 7]]" {
+		t.Fatalf("windows %v", windows)
+	}
+	if Window(s, 8) != nil {
+		t.Fatal("window larger than input")
+	}
+}
+
+func TestPartitionFlattenZip(t *testing.T) {
+	small, large := Partition([]int{5, 12, 3, 40, 7, 18}, func(n int) bool { return n < 10 })
+	if !slices.Equal(small, []int{5, 3, 7}) || !slices.Equal(large, []int{12, 40, 18}) {
+		t.Fatalf("Partition must keep order: %v %v", small, large)
+	}
+	if small = append(small, 1); large[0] != 12 {
+		t.Fatal("appending to matched must not overwrite rest")
+	}
+
+	if got := Flatten([][]string{{"a"}, nil, {"b", "c"}}); !slices.Equal(got, []string{"a", "b", "c"}) || cap(got) != 3 {
+		t.Fatalf("Flatten %v cap %d", got, cap(got))
+	}
+
+	pairs := Zip([]string{"x", "y", "z"}, []int{1, 2})
+	names, values := Unzip(pairs)
+	if len(pairs) != 2 || !slices.Equal(names, []string{"x", "y"}) || !slices.Equal(values, []int{1, 2}) {
+		t.Fatalf("zip %v", pairs)
+	}
+}
+
+type employee struct {
+	Name string
+	Dept string
+}
+
+func TestGroupByAndSortByKey(t *testing.T) {
+	staff := []employee{{"Bisma", "eng"}, {"Ayu", "ops"}, {"Dewi", "eng"}, {"Candra", "ops"}, {"Eko", "hr"}}
+
+	groups := GroupBy(staff, func(e employee) string { return e.Dept })
+	if len(groups) != 3 || groups["eng"][0].Name != "Bisma" || groups["eng"][1].Name != "Dewi" {
+		t.Fatalf("groups %v", groups)
+	}
+
+	SortByKey(staff, func(e employee) string { return e.Dept })
+	var names []string
+	for _, e := range staff {
+		names = append(names, e.Name)
+	}
+	// stabil: dalam satu dept urutan asli dipertahankan
+	if strings.Join(names, ",") != "Bisma,Dewi,Eko,Ayu,Candra" {
+		t.Fatalf("SortByKey %v", names)
+	}
+}
+
+// Benchmark di bawah membandingkan versi toolkit dengan cara naif.
+// Jalankan: go test -bench . -benchmem ./basic/06-arrays-slices/utils
+
+func BenchmarkInsertAt(b *testing.B) {
+	base := make([]int, 1000)
+	b.Run("InsertAt", func(b *testing.B) {
+		b.ReportAllocs()
+		s := make([]int, len(base), len(base)+1)
+		for i := 0; i < b.N; i++ {
+			s = InsertAt(s[:len(base)], 500, 42)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		b.ReportAllocs()
+		s := make([]int, len(base), len(base)+1)
+		for i := 0; i < b.N; i++ {
+			s = s[:len(base)]
+			s = append(s[:500], append([]int{42}, s[500:]...)...)
+		}
+	})
+}
+
+func BenchmarkFilter(b *testing.B) {
+	src := make([]int, 10_000)
+	for i := range src {
+		src[i] = i
+	}
+	even := func(n int) bool { return n%2 == 0 }
+	work := make([]int, len(src))
+
+	b.Run("FilterInPlace", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			copy(work[:len(src)], src)
+			_ = FilterInPlace(work[:len(src)], even)
+		}
+	})
+	b.Run("append-new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out []int
+			for _, v := range src {
+				if even(v) {
+					out = append(out, v)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkFlatten(b *testing.B) {
+	ss := make([][]int, 100)
+	for i := range ss {
+		ss[i] = make([]int, 100)
+	}
+	b.Run("Flatten", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Flatten(ss)
+		}
+	})
+	b.Run("append-grow", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out []int
+			for _, s := range ss {
+				out = append(out, s...)
+			}
+		}
+	})
+}
+
+func BenchmarkSortByKey(b *testing.B) {
+	words := make([]string, 2000)
+	for i := range words {
+		words[i] = fmt.Sprintf("Word-%d", (i*7919)%2000)
+	}
+	work := make([]string, len(words))
+
+	b.Run("SortByKey", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			copy(work, words)
+			SortByKey(work, strings.ToLower)
+		}
+	})
+	// key dihitung di setiap perbandingan: dua alokasi ToLower per compare
+	b.Run("SliceStable", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			copy(work, words)
+			sort.SliceStable(work, func(i, j int) bool { return strings.ToLower(work[i]) < strings.ToLower(work[j]) })
+		}
+	})
+}
@@ -77,7 +77,7 @@ func slicingOperations() {
 }
 
 func mainMateri() {
-	fmt.Println("=== TUTORIAL SLICE GO ===\n")
+	fmt.Print("=== TUTORIAL SLICE GO ===\n\n")
 
 	// 1. Membuat slice kosong
 	fmt.Println("1. Slice Kosong:")
@@ -0,0 +1,269 @@
+// Package linkedlist adalah doubly linked list generic, pengganti
+// LinkedList int di utils yang Add-nya O(n). Bentuknya mirip container/list
+// tapi type-safe: tidak perlu type assertion dari interface{}.
+//
+// Di balik layar list memakai node sentinel (root) yang melingkar, sehingga
+// insert dan remove tidak perlu cek nil untuk head/tail.
+package linkedlist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Element adalah satu node di list. Pointer ke Element tetap valid sampai
+// node itu di-Remove, jadi bisa disimpan untuk operasi O(1) nanti.
+type Element[T any] struct {
+	Value T
+
+	next, prev *Element[T]
+	list       *List[T]
+}
+
+// Next mengembalikan elemen berikutnya atau nil di ujung list.
+func (e *Element[T]) Next() *Element[T] {
+	if e.list == nil || e.next == &e.list.root {
+		return nil
+	}
+	return e.next
+}
+
+// Prev mengembalikan elemen sebelumnya atau nil di awal list.
+func (e *Element[T]) Prev() *Element[T] {
+	if e.list == nil || e.prev == &e.list.root {
+		return nil
+	}
+	return e.prev
+}
+
+// List siap dipakai sebagai zero value: var l List[int].
+type List[T any] struct {
+	root Element[T]
+	len  int
+}
+
+func New[T any]() *List[T] {
+	return new(List[T]).init()
+}
+
+// FromSlice membuat list dengan urutan yang sama seperti s.
+func FromSlice[T any](s []T) *List[T] {
+	l := New[T]()
+	for _, v := range s {
+		l.PushBack(v)
+	}
+	return l
+}
+
+func (l *List[T]) init() *List[T] {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+	return l
+}
+
+// lazyInit supaya zero value List bisa langsung dipakai.
+func (l *List[T]) lazyInit() {
+	if l.root.next == nil {
+		l.init()
+	}
+}
+
+func (l *List[T]) Len() int { return l.len }
+
+func (l *List[T]) Front() *Element[T] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+func (l *List[T]) Back() *Element[T] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// insert menaruh e setelah at.
+func (l *List[T]) insert(e, at *Element[T]) *Element[T] {
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+	e.list = l
+	l.len++
+	return e
+}
+
+// unlink melepas e dari list tanpa mengosongkan list-nya.
+func (l *List[T]) unlink(e *Element[T]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	l.len--
+}
+
+func (l *List[T]) PushFront(v T) *Element[T] {
+	l.lazyInit()
+	return l.insert(&Element[T]{Value: v}, &l.root)
+}
+
+func (l *List[T]) PushBack(v T) *Element[T] {
+	l.lazyInit()
+	return l.insert(&Element[T]{Value: v}, l.root.prev)
+}
+
+// InsertBefore menyisipkan v sebelum mark. Kalau mark bukan milik l, list
+// tidak berubah dan hasilnya nil.
+func (l *List[T]) InsertBefore(v T, mark *Element[T]) *Element[T] {
+	if mark == nil || mark.list != l {
+		return nil
+	}
+	return l.insert(&Element[T]{Value: v}, mark.prev)
+}
+
+// InsertAfter menyisipkan v setelah mark, aturannya sama dengan InsertBefore.
+func (l *List[T]) InsertAfter(v T, mark *Element[T]) *Element[T] {
+	if mark == nil || mark.list != l {
+		return nil
+	}
+	return l.insert(&Element[T]{Value: v}, mark)
+}
+
+// Remove melepas e dari l dalam O(1) dan mengembalikan nilainya. Element
+// milik list lain diabaikan.
+func (l *List[T]) Remove(e *Element[T]) T {
+	if e.list == l {
+		l.unlink(e)
+		// putus pointer supaya node yang sudah dihapus tidak menahan
+		// tetangganya dari garbage collector
+		e.next, e.prev, e.list = nil, nil, nil
+	}
+	return e.Value
+}
+
+func (l *List[T]) PopFront() (T, bool) {
+	e := l.Front()
+	if e == nil {
+		var zero T
+		return zero, false
+	}
+	return l.Remove(e), true
+}
+
+func (l *List[T]) PopBack() (T, bool) {
+	e := l.Back()
+	if e == nil {
+		var zero T
+		return zero, false
+	}
+	return l.Remove(e), true
+}
+
+func (l *List[T]) MoveToFront(e *Element[T]) {
+	if e.list != l || l.root.next == e {
+		return
+	}
+	l.unlink(e)
+	l.insert(e, &l.root)
+}
+
+func (l *List[T]) MoveToBack(e *Element[T]) {
+	if e.list != l || l.root.prev == e {
+		return
+	}
+	l.unlink(e)
+	l.insert(e, l.root.prev)
+}
+
+// Reverse membalik urutan di tempat dengan menukar next/prev setiap node,
+// termasuk root. Element yang sudah dipegang caller tetap valid.
+func (l *List[T]) Reverse() {
+	if l.len < 2 {
+		return
+	}
+	e := &l.root
+	for {
+		e.next, e.prev = e.prev, e.next
+		e = e.prev // next yang lama
+		if e == &l.root {
+			return
+		}
+	}
+}
+
+// Clear mengosongkan list. Element lama dilepas satu per satu supaya
+// pemanggilan Remove dengan element lama tidak merusak list.
+func (l *List[T]) Clear() {
+	for e := l.Front(); e != nil; {
+		next := e.Next()
+		e.next, e.prev, e.list = nil, nil, nil
+		e = next
+	}
+	l.init()
+}
+
+// Values adalah iterator dari depan ke belakang. Berhenti kalau yield
+// mengembalikan false. Bentuknya sama dengan iter.Seq, jadi di Go 1.23+
+// bisa langsung dipakai: for v := range l.Values() { ... }
+func (l *List[T]) Values() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for e := l.Front(); e != nil; e = e.Next() {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward adalah iterator dari belakang ke depan.
+func (l *List[T]) Backward() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Each memanggil fn untuk setiap nilai beserta posisinya.
+func (l *List[T]) Each(fn func(i int, v T)) {
+	i := 0
+	for e := l.Front(); e != nil; e = e.Next() {
+		fn(i, e.Value)
+		i++
+	}
+}
+
+// Find mengembalikan element pertama yang memenuhi match, atau nil.
+func (l *List[T]) Find(match func(T) bool) *Element[T] {
+	for e := l.Front(); e != nil; e = e.Next() {
+		if match(e.Value) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (l *List[T]) ToSlice() []T {
+	out := make([]T, 0, l.len)
+	for e := l.Front(); e != nil; e = e.Next() {
+		out = append(out, e.Value)
+	}
+	return out
+}
+
+// String menampilkan list seperti Display versi lama: [10 <-> 20 <-> 30].
+func (l *List[T]) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for e := l.Front(); e != nil; e = e.Next() {
+		if e != l.Front() {
+			b.WriteString(" <-> ")
+		}
+		fmt.Fprint(&b, e.Value)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,215 @@
+package linkedlist
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// op adalah satu operasi acak yang dibangkitkan testing/quick. Field harus
+// exported supaya quick bisa mengisinya.
+type op struct {
+	Kind  uint8
+	Value int
+	Index uint8
+}
+
+func elementAt[T any](l *List[T], i int) *Element[T] {
+	e := l.Front()
+	for ; i > 0; i-- {
+		e = e.Next()
+	}
+	return e
+}
+
+// checkInvariants memastikan list sama dengan model slice dari dua arah dan
+// semua pointer next/prev konsisten.
+func checkInvariants(l *List[int], model []int) error {
+	if l.Len() != len(model) {
+		return fmt.Errorf("len %d, model %d", l.Len(), len(model))
+	}
+	if got := l.ToSlice(); !slices.Equal(got, model) {
+		return fmt.Errorf("forward %v, model %v", got, model)
+	}
+
+	var backward []int
+	l.Backward()(func(v int) bool { backward = append(backward, v); return true })
+	reversed := slices.Clone(model)
+	slices.Reverse(reversed)
+	if !slices.Equal(backward, reversed) && len(model) > 0 {
+		return fmt.Errorf("backward %v, model %v", backward, model)
+	}
+
+	for e := l.Front(); e != nil; e = e.Next() {
+		if next := e.Next(); next != nil && next.Prev() != e {
+			return fmt.Errorf("broken link at %d", e.Value)
+		}
+	}
+	if len(model) == 0 && (l.Front() != nil || l.Back() != nil) {
+		return fmt.Errorf("empty list must have no front/back")
+	}
+	return nil
+}
+
+// apply menjalankan op ke list dan ke model slice dengan semantik yang sama.
+func apply(l *List[int], model []int, o op) []int {
+	n := len(model)
+	switch o.Kind % 10 {
+	case 0:
+		l.PushFront(o.Value)
+		model = slices.Insert(model, 0, o.Value)
+	case 1:
+		l.PushBack(o.Value)
+		model = append(model, o.Value)
+	case 2:
+		if n > 0 {
+			i := int(o.Index) % n
+			l.InsertBefore(o.Value, elementAt(l, i))
+			model = slices.Insert(model, i, o.Value)
+		}
+	case 3:
+		if n > 0 {
+			i := int(o.Index) % n
+			l.InsertAfter(o.Value, elementAt(l, i))
+			model = slices.Insert(model, i+1, o.Value)
+		}
+	case 4:
+		if n > 0 {
+			i := int(o.Index) % n
+			l.Remove(elementAt(l, i))
+			model = slices.Delete(model, i, i+1)
+		}
+	case 5:
+		if v, ok := l.PopFront(); ok {
+			if v != model[0] {
+				panic("PopFront returned the wrong value")
+			}
+			model = model[1:]
+		}
+	case 6:
+		if v, ok := l.PopBack(); ok {
+			if v != model[n-1] {
+				panic("PopBack returned the wrong value")
+			}
+			model = model[:n-1]
+		}
+	case 7:
+		if n > 0 {
+			i := int(o.Index) % n
+			l.MoveToFront(elementAt(l, i))
+			v := model[i]
+			model = slices.Insert(slices.Delete(model, i, i+1), 0, v)
+		}
+	case 8:
+		if n > 0 {
+			i := int(o.Index) % n
+			l.MoveToBack(elementAt(l, i))
+			v := model[i]
+			model = append(slices.Delete(model, i, i+1), v)
+		}
+	case 9:
+		l.Reverse()
+		slices.Reverse(model)
+	}
+	return model
+}
+
+func TestListMatchesSliceModel(t *testing.T) {
+	property := func(initial []int, ops []op) bool {
+		l := FromSlice(initial)
+		model := slices.Clone(initial)
+		if err := checkInvariants(l, model); err != nil {
+			t.Log(err)
+			return false
+		}
+		for _, o := range ops {
+			model = apply(l, model, o)
+			if err := checkInvariants(l, model); err != nil {
+				t.Logf("after %+v: %v", o, err)
+				return false
+			}
+		}
+		return true
+	}
+
+	require.NoError(t, quick.Check(property, &quick.Config{MaxCount: 500}))
+}
+
+func TestReverseProperties(t *testing.T) {
+	twiceIsIdentity := func(s []string) bool {
+		l := FromSlice(s)
+		l.Reverse()
+		l.Reverse()
+		return slices.Equal(l.ToSlice(), s) || (len(s) == 0 && l.Len() == 0)
+	}
+	require.NoError(t, quick.Check(twiceIsIdentity, nil))
+
+	matchesSlices := func(s []int) bool {
+		l := FromSlice(s)
+		l.Reverse()
+		want := slices.Clone(s)
+		slices.Reverse(want)
+		return checkInvariants(l, want) == nil
+	}
+	require.NoError(t, quick.Check(matchesSlices, nil))
+}
+
+func TestElementsStayValid(t *testing.T) {
+	var l List[string] // zero value langsung bisa dipakai
+	a := l.PushBack("a")
+	b := l.PushBack("b")
+	c := l.PushBack("c")
+
+	l.Reverse()
+	assert.Equal(t, "[c <-> b <-> a]", l.String())
+	assert.Equal(t, a, b.Next(), "elements keep their identity after Reverse")
+
+	other := FromSlice([]string{"x"})
+	assert.Nil(t, other.InsertAfter("y", b), "foreign mark must be rejected")
+	other.Remove(b)
+	assert.Equal(t, 3, l.Len(), "removing a foreign element must not touch either list")
+
+	assert.Equal(t, "b", l.Remove(b))
+	assert.Equal(t, "b", l.Remove(b), "double remove is a no-op")
+	assert.Nil(t, b.Next())
+	assert.Equal(t, []string{"c", "a"}, l.ToSlice())
+
+	l.MoveToFront(a)
+	assert.Equal(t, c, l.Back())
+
+	l.Clear()
+	assert.Equal(t, 0, l.Len())
+	assert.Nil(t, l.Front())
+	l.Remove(a)
+	assert.Equal(t, 0, l.Len(), "elements from before Clear must be detached")
+}
+
+func TestIterators(t *testing.T) {
+	l := FromSlice([]int{1, 2, 3, 4, 5})
+
+	var firstThree []int
+	l.Values()(func(v int) bool {
+		firstThree = append(firstThree, v)
+		return len(firstThree) < 3
+	})
+	assert.Equal(t, []int{1, 2, 3}, firstThree)
+
+	sum := 0
+	l.Each(func(i, v int) { sum += i * v })
+	assert.Equal(t, 0*1+1*2+2*3+3*4+4*5, sum)
+
+	assert.Equal(t, 4, l.Find(func(v int) bool { return v%4 == 0 }).Value)
+	assert.Nil(t, l.Find(func(v int) bool { return v > 10 }))
+}
+
+func BenchmarkPushBack(b *testing.B) {
+	b.ReportAllocs()
+	l := New[int]()
+	for i := 0; i < b.N; i++ {
+		l.PushBack(i)
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"go-journey/basic/helper"
+	"pointer/linkedlist"
 )
 
 func CallPointerarnSection() {
@@ -198,46 +199,22 @@ func updateMap(m map[string]int) {
 // 6. CONTOH PRAKTIS: LINKED LIST
 // ====================
 
-type Node struct {
-	Data int
-	Next *Node
-}
-
-type LinkedList struct {
-	Head *Node
-}
-
-func (ll *LinkedList) Add(data int) {
-	newNode := &Node{Data: data}
-	if ll.Head == nil {
-		ll.Head = newNode
-	} else {
-		current := ll.Head
-		for current.Next != nil {
-			current = current.Next
-		}
-		current.Next = newNode
-	}
-}
-
-func (ll *LinkedList) Display() {
-	current := ll.Head
-	fmt.Print("LinkedList: ")
-	for current != nil {
-		fmt.Printf("%d -> ", current.Data)
-		current = current.Next
-	}
-	fmt.Println("nil")
-}
-
+// Versi lama LinkedList hanya untuk int dan Add-nya O(n) karena harus jalan
+// sampai node terakhir. Sekarang pakai linkedlist.List: generic, doubly
+// linked, dan menyimpan tail sehingga PushBack O(1).
 func linkedListExample() {
 	fmt.Println("=== CONTOH PRAKTIS: LINKED LIST ===")
 
-	list := &LinkedList{}
-	list.Add(10)
-	list.Add(20)
-	list.Add(30)
-	list.Display()
+	list := linkedlist.New[int]()
+	list.PushBack(10)
+	list.PushBack(20)
+	thirty := list.PushBack(30)
+	list.InsertBefore(25, thirty)
+	list.PushFront(5)
+	fmt.Println("LinkedList:", list)
+
+	list.Reverse()
+	fmt.Println("Reversed:", list, "len", list.Len())
 	fmt.Println()
 }
 
@@ -0,0 +1,343 @@
+package behavioral
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// document adalah "editor" sederhana untuk contoh command.
+type document struct{ text string }
+
+func insert(doc *document, s string) Command {
+	return NewCommand("insert "+s,
+		func(context.Context) error { doc.text += s; return nil },
+		func(context.Context) error { doc.text = strings.TrimSuffix(doc.text, s); return nil },
+	)
+}
+
+func TestBusUndoRedo(t *testing.T) {
+	ctx := context.Background()
+	doc := &document{}
+	var log []string
+	bus := NewBus(BusConfig{HistoryLimit: 2, OnExecute: func(action string, cmd Command, err error) {
+		log = append(log, action+":"+cmd.Name())
+	}})
+
+	for _, s := range []string{"a", "b", "c"} {
+		if err := bus.Execute(ctx, insert(doc, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h := strings.Join(bus.History(), ","); h != "insert b,insert c" {
+		t.Fatalf("history must keep the last 2 commands, got %s", h)
+	}
+
+	bus.Undo(ctx)
+	bus.Undo(ctx)
+	if doc.text != "a" {
+		t.Fatalf("after two undos: %q", doc.text)
+	}
+	if err := bus.Undo(ctx); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("history limit reached, got %v", err)
+	}
+
+	bus.Redo(ctx)
+	if doc.text != "ab" || !bus.CanRedo() {
+		t.Fatalf("after redo: %q", doc.text)
+	}
+
+	// command baru membuang riwayat redo
+	bus.Execute(ctx, insert(doc, "x"))
+	if err := bus.Redo(ctx); !errors.Is(err, ErrNothingToRedo) {
+		t.Fatalf("redo after new command: %v", err)
+	}
+	if doc.text != "abx" || len(log) != 7 {
+		t.Fatalf("text %q log %v", doc.text, log)
+	}
+}
+
+func TestMacroRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	doc := &document{text: "start "}
+	boom := NewCommand("boom",
+		func(context.Context) error { return errors.New("disk full") },
+		func(context.Context) error { t.Fatal("failed command must not be undone"); return nil },
+	)
+
+	bus := NewBus(BusConfig{})
+	err := bus.Execute(ctx, NewMacro("paste", insert(doc, "one "), insert(doc, "two "), boom))
+	if err == nil || !strings.Contains(err.Error(), "step boom: disk full") {
+		t.Fatalf("got %v", err)
+	}
+	if doc.text != "start " || bus.CanUndo() {
+		t.Fatalf("macro must be all or nothing: %q", doc.text)
+	}
+
+	bus.Execute(ctx, NewMacro("paste", insert(doc, "one "), insert(doc, "two ")))
+	bus.Undo(ctx)
+	if doc.text != "start " {
+		t.Fatalf("macro undo: %q", doc.text)
+	}
+}
+
+type expense struct {
+	Amount    int
+	Submitter string
+}
+
+func approver(role string, limit int) Handler[expense, string] {
+	return When(func(e expense) bool { return e.Amount <= limit },
+		func(ctx context.Context, e expense) (string, error) {
+			return fmt.Sprintf("%d approved by %s", e.Amount, role), nil
+		})
+}
+
+func TestChainShortCircuits(t *testing.T) {
+	var reached []string
+	trace := func(name string) Handler[expense, string] {
+		return func(ctx context.Context, e expense, next Next[expense, string]) (string, error) {
+			reached = append(reached, name)
+			return next(ctx, e)
+		}
+	}
+	validate := func(ctx context.Context, e expense, next Next[expense, string]) (string, error) {
+		if e.Amount <= 0 || e.Submitter == "" {
+			return "", errors.New("invalid expense")
+		}
+		return next(ctx, e)
+	}
+
+	base := NewChain(validate, trace("lead"), approver("team lead", 1_000_000), trace("manager"), approver("manager", 10_000_000))
+	withDirector := base.Then(trace("director"), approver("director", 100_000_000))
+
+	got, err := withDirector.Handle(context.Background(), expense{Amount: 500_000, Submitter: "bisma"})
+	if err != nil || got != "500000 approved by team lead" || strings.Join(reached, ",") != "lead" {
+		t.Fatalf("got %q %v, reached %v", got, err, reached)
+	}
+
+	reached = nil
+	got, _ = withDirector.Handle(context.Background(), expense{Amount: 50_000_000, Submitter: "bisma"})
+	if got != "50000000 approved by director" || strings.Join(reached, ",") != "lead,manager,director" {
+		t.Fatalf("got %q, reached %v", got, reached)
+	}
+
+	if _, err := base.Handle(context.Background(), expense{Amount: 50_000_000, Submitter: "bisma"}); !errors.Is(err, ErrUnhandled) {
+		t.Fatalf("Then must not modify the base chain, got %v", err)
+	}
+
+	reached = nil
+	if _, err := withDirector.Handle(context.Background(), expense{Amount: 10}); err == nil || len(reached) != 0 {
+		t.Fatalf("validation must stop the chain: %v %v", err, reached)
+	}
+}
+
+const (
+	Pending   State = "pending"
+	Paid      State = "paid"
+	Shipped   State = "shipped"
+	Delivered State = "delivered"
+	Cancelled State = "cancelled"
+
+	Pay     Event = "pay"
+	Ship    Event = "ship"
+	Deliver Event = "deliver"
+	Cancel  Event = "cancel"
+)
+
+type order struct {
+	ID       string
+	Total    int
+	Address  string
+	Refunded bool
+	Emails   []string
+}
+
+func newOrderMachine(t *testing.T) *StateMachine[*order] {
+	t.Helper()
+
+	sm, err := NewStateMachine(Definition[*order]{
+		Initial: Pending,
+		Transitions: []Transition[*order]{
+			{Event: Pay, From: []State{Pending}, To: Paid, Guard: func(ctx context.Context, o *order) error {
+				if o.Total <= 0 {
+					return errors.New("order total must be positive")
+				}
+				return nil
+			}},
+			{Event: Ship, From: []State{Paid}, To: Shipped, Guard: func(ctx context.Context, o *order) error {
+				if o.Address == "" {
+					return errors.New("shipping address is missing")
+				}
+				return nil
+			}},
+			{Event: Deliver, From: []State{Shipped}, To: Delivered},
+			{Event: Cancel, From: []State{Pending, Paid}, To: Cancelled},
+		},
+		OnExit: map[State]Action[*order]{
+			Paid: func(ctx context.Context, info TransitionInfo, o *order) error {
+				if info.Event == Cancel {
+					o.Refunded = true
+				}
+				return nil
+			},
+		},
+		OnEnter: map[State]Action[*order]{
+			Shipped: func(ctx context.Context, info TransitionInfo, o *order) error {
+				o.Emails = append(o.Emails, "your order is on the way")
+				return nil
+			},
+		},
+		Listeners: []func(context.Context, TransitionInfo, *order){
+			func(ctx context.Context, info TransitionInfo, o *order) {
+				o.Emails = append(o.Emails, fmt.Sprintf("%s: %s -> %s", o.ID, info.From, info.To))
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sm
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	sm := newOrderMachine(t)
+
+	o := &order{ID: "ord-1", Total: 150_000}
+	m := sm.New(o)
+	if err := m.Fire(ctx, Ship); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("cannot ship a pending order: %v", err)
+	}
+	if err := m.Fire(ctx, Pay); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.Fire(ctx, Ship)
+	if !errors.Is(err, ErrGuardRejected) || !strings.Contains(err.Error(), "shipping address is missing") {
+		t.Fatalf("guard must explain the rejection: %v", err)
+	}
+	if got := fmt.Sprint(m.AvailableEvents(ctx)); got != "[cancel]" {
+		t.Fatalf("available events %s", got)
+	}
+
+	o.Address = "Jl. Sudirman 1"
+	m.Fire(ctx, Ship)
+	m.Fire(ctx, Deliver)
+	if m.State() != Delivered || len(m.History()) != 3 {
+		t.Fatalf("state %s history %v", m.State(), m.History())
+	}
+	want := "ord-1: pending -> paid|your order is on the way|ord-1: paid -> shipped|ord-1: shipped -> delivered"
+	if got := strings.Join(o.Emails, "|"); got != want {
+		t.Fatalf("emails:\n got %s\nwant %s", got, want)
+	}
+	if m.Can(ctx, Cancel) {
+		t.Fatal("delivered orders cannot be cancelled")
+	}
+}
+
+func TestOrderCancelAfterPaymentRefunds(t *testing.T) {
+	ctx := context.Background()
+	sm := newOrderMachine(t)
+
+	o := &order{ID: "ord-2", Total: 10}
+	m, err := sm.Restore(Paid, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Fire(ctx, Cancel); err != nil || !o.Refunded {
+		t.Fatalf("exit action must refund: %v %+v", err, o)
+	}
+
+	if _, err := sm.Restore("lost", o); err == nil {
+		t.Fatal("unknown state must be rejected")
+	}
+	if !strings.Contains(sm.Mermaid(), "paid --> shipped: ship [guarded]") {
+		t.Fatalf("mermaid:\n%s", sm.Mermaid())
+	}
+}
+
+func TestStateMachineFailedActionKeepsState(t *testing.T) {
+	ctx := context.Background()
+	exits := 0
+	sm, err := NewStateMachine(Definition[*order]{
+		Initial:     Pending,
+		Transitions: []Transition[*order]{{Event: Pay, From: []State{Pending}, To: Paid}},
+		OnExit: map[State]Action[*order]{
+			Pending: func(context.Context, TransitionInfo, *order) error { exits++; return nil },
+		},
+		OnEnter: map[State]Action[*order]{
+			Paid: func(context.Context, TransitionInfo, *order) error { return errors.New("mail server down") },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := sm.New(&order{})
+	if err := m.Fire(ctx, Pay); err == nil || m.State() != Pending {
+		t.Fatalf("failed entry action must keep the old state: %v %s", err, m.State())
+	}
+	// OnExit yang sudah jalan tidak dibatalkan, Fire ulang menjalankannya lagi
+	m.Fire(ctx, Pay)
+	if exits != 2 {
+		t.Fatalf("exit action ran %d times, want 2", exits)
+	}
+
+	_, err = NewStateMachine(Definition[*order]{
+		Initial: Pending,
+		Transitions: []Transition[*order]{
+			{Event: Pay, From: []State{Pending}, To: Paid},
+			{Event: Pay, From: []State{Pending}, To: Cancelled},
+		},
+	})
+	if err == nil {
+		t.Fatal("transition shadowed by an unguarded one must be rejected")
+	}
+}
+
+func TestStateMachineListenerCanUseMachine(t *testing.T) {
+	ctx := context.Background()
+	var m *Machine[*order]
+	var seen []string
+	sm, err := NewStateMachine(Definition[*order]{
+		Initial: Pending,
+		Transitions: []Transition[*order]{
+			{Event: Pay, From: []State{Pending}, To: Paid},
+			{Event: Ship, From: []State{Paid}, To: Shipped},
+		},
+		Listeners: []func(context.Context, TransitionInfo, *order){
+			func(ctx context.Context, info TransitionInfo, o *order) {
+				seen = append(seen, fmt.Sprintf("%s@%s/%d", info.To, m.State(), len(m.History())))
+				// pesanan digital langsung dikirim setelah dibayar
+				if info.To == Paid {
+					if err := m.Fire(ctx, Ship); err != nil {
+						t.Errorf("fire from listener: %v", err)
+					}
+				}
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = sm.New(&order{})
+
+	done := make(chan error, 1)
+	go func() { done <- m.Fire(ctx, Pay) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener calling the machine deadlocked")
+	}
+
+	if m.State() != Shipped || strings.Join(seen, ",") != "paid@paid/1,shipped@shipped/2" {
+		t.Fatalf("state %s, listener saw %v", m.State(), seen)
+	}
+}
@@ -0,0 +1,64 @@
+package behavioral
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnhandled dikembalikan kalau request sampai di ujung chain tanpa ada
+// handler yang menanganinya.
+var ErrUnhandled = errors.New("behavioral: request not handled by any handler in chain")
+
+// Next meneruskan request ke handler berikutnya di chain.
+type Next[Req, Res any] func(ctx context.Context, req Req) (Res, error)
+
+// Handler menerima request dan memutuskan sendiri: menangani dan berhenti
+// (short-circuit) dengan tidak memanggil next, atau meneruskannya, bisa
+// juga sambil mengubah request atau hasil dari handler setelahnya.
+type Handler[Req, Res any] func(ctx context.Context, req Req, next Next[Req, Res]) (Res, error)
+
+// Chain adalah pipeline handler yang immutable; Then mengembalikan chain
+// baru sehingga chain dasar bisa dipakai bersama.
+type Chain[Req, Res any] struct {
+	handlers []Handler[Req, Res]
+}
+
+func NewChain[Req, Res any](handlers ...Handler[Req, Res]) *Chain[Req, Res] {
+	return &Chain[Req, Res]{handlers: append([]Handler[Req, Res](nil), handlers...)}
+}
+
+func (c *Chain[Req, Res]) Then(handlers ...Handler[Req, Res]) *Chain[Req, Res] {
+	combined := make([]Handler[Req, Res], 0, len(c.handlers)+len(handlers))
+	combined = append(combined, c.handlers...)
+	combined = append(combined, handlers...)
+	return &Chain[Req, Res]{handlers: combined}
+}
+
+func (c *Chain[Req, Res]) Handle(ctx context.Context, req Req) (Res, error) {
+	return c.next(0)(ctx, req)
+}
+
+func (c *Chain[Req, Res]) next(i int) Next[Req, Res] {
+	return func(ctx context.Context, req Req) (Res, error) {
+		if err := ctx.Err(); err != nil {
+			var zero Res
+			return zero, err
+		}
+		if i >= len(c.handlers) {
+			var zero Res
+			return zero, ErrUnhandled
+		}
+		return c.handlers[i](ctx, req, c.next(i+1))
+	}
+}
+
+// When membuat handler yang menangani request kalau match bernilai true dan
+// meneruskannya kalau tidak, bentuk klasik chain of responsibility.
+func When[Req, Res any](match func(Req) bool, handle func(ctx context.Context, req Req) (Res, error)) Handler[Req, Res] {
+	return func(ctx context.Context, req Req, next Next[Req, Res]) (Res, error) {
+		if match(req) {
+			return handle(ctx, req)
+		}
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,197 @@
+// Package behavioral berisi behavioral pattern yang bisa dipakai ulang:
+// command bus dengan undo/redo, chain of responsibility, dan finite state
+// machine deklaratif untuk lifecycle seperti order.
+package behavioral
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrNothingToUndo = errors.New("behavioral: nothing to undo")
+	ErrNothingToRedo = errors.New("behavioral: nothing to redo")
+)
+
+// Command membungkus satu aksi beserta kebalikannya. Undo hanya dipanggil
+// setelah Execute sukses.
+type Command interface {
+	Name() string
+	Execute(ctx context.Context) error
+	Undo(ctx context.Context) error
+}
+
+type funcCommand struct {
+	name string
+	do   func(ctx context.Context) error
+	undo func(ctx context.Context) error
+}
+
+func (c *funcCommand) Name() string                      { return c.name }
+func (c *funcCommand) Execute(ctx context.Context) error { return c.do(ctx) }
+func (c *funcCommand) Undo(ctx context.Context) error    { return c.undo(ctx) }
+
+// NewCommand membuat Command dari dua fungsi, praktis untuk aksi kecil.
+func NewCommand(name string, do, undo func(ctx context.Context) error) Command {
+	return &funcCommand{name: name, do: do, undo: undo}
+}
+
+type macro struct {
+	name     string
+	commands []Command
+}
+
+// NewMacro menggabungkan beberapa command menjadi satu langkah undo. Kalau
+// salah satu gagal, command yang sudah jalan di-undo dengan urutan terbalik
+// sehingga macro berlaku utuh atau tidak sama sekali.
+func NewMacro(name string, commands ...Command) Command {
+	return &macro{name: name, commands: commands}
+}
+
+func (m *macro) Name() string { return m.name }
+
+func (m *macro) Execute(ctx context.Context) error {
+	for i, cmd := range m.commands {
+		if err := cmd.Execute(ctx); err != nil {
+			err = fmt.Errorf("behavioral: %s: step %s: %w", m.name, cmd.Name(), err)
+			if rbErr := undoAll(ctx, m.commands[:i]); rbErr != nil {
+				return errors.Join(err, rbErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *macro) Undo(ctx context.Context) error {
+	return undoAll(ctx, m.commands)
+}
+
+func undoAll(ctx context.Context, commands []Command) error {
+	var errs []error
+	for i := len(commands) - 1; i >= 0; i-- {
+		if err := commands[i].Undo(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("behavioral: undo %s: %w", commands[i].Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type BusConfig struct {
+	// HistoryLimit membatasi jumlah langkah yang bisa di-undo, default 100.
+	// Langkah paling lama dibuang lebih dulu.
+	HistoryLimit int
+	// OnExecute dipanggil setelah setiap Execute, Undo, dan Redo, misalnya
+	// untuk audit log. action bernilai "execute", "undo", atau "redo".
+	OnExecute func(action string, cmd Command, err error)
+}
+
+// Bus menjalankan command satu per satu dan menyimpan riwayatnya untuk
+// undo/redo, seperti Ctrl+Z di editor. Aman dipakai dari banyak goroutine;
+// command dijalankan berurutan.
+type Bus struct {
+	cfg BusConfig
+
+	mu     sync.Mutex
+	done   []Command
+	undone []Command
+}
+
+func NewBus(cfg BusConfig) *Bus {
+	if cfg.HistoryLimit <= 0 {
+		cfg.HistoryLimit = 100
+	}
+	if cfg.OnExecute == nil {
+		cfg.OnExecute = func(string, Command, error) {}
+	}
+	return &Bus{cfg: cfg}
+}
+
+// Execute menjalankan cmd dan, kalau sukses, mencatatnya di riwayat.
+// Command baru menghapus riwayat redo.
+func (b *Bus) Execute(ctx context.Context, cmd Command) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := cmd.Execute(ctx)
+	b.cfg.OnExecute("execute", cmd, err)
+	if err != nil {
+		return err
+	}
+
+	b.done = append(b.done, cmd)
+	if len(b.done) > b.cfg.HistoryLimit {
+		b.done = b.done[len(b.done)-b.cfg.HistoryLimit:]
+	}
+	b.undone = nil
+	return nil
+}
+
+// Undo membatalkan command terakhir. Kalau Undo gagal, command tetap di
+// riwayat supaya bisa dicoba lagi.
+func (b *Bus) Undo(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.done) == 0 {
+		return ErrNothingToUndo
+	}
+	cmd := b.done[len(b.done)-1]
+
+	err := cmd.Undo(ctx)
+	b.cfg.OnExecute("undo", cmd, err)
+	if err != nil {
+		return err
+	}
+
+	b.done = b.done[:len(b.done)-1]
+	b.undone = append(b.undone, cmd)
+	return nil
+}
+
+// Redo menjalankan ulang command yang terakhir di-undo.
+func (b *Bus) Redo(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.undone) == 0 {
+		return ErrNothingToRedo
+	}
+	cmd := b.undone[len(b.undone)-1]
+
+	err := cmd.Execute(ctx)
+	b.cfg.OnExecute("redo", cmd, err)
+	if err != nil {
+		return err
+	}
+
+	b.undone = b.undone[:len(b.undone)-1]
+	b.done = append(b.done, cmd)
+	return nil
+}
+
+func (b *Bus) CanUndo() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.done) > 0
+}
+
+func (b *Bus) CanRedo() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.undone) > 0
+}
+
+// History mengembalikan nama command yang bisa di-undo, paling lama dulu.
+func (b *Bus) History() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	names := make([]string, len(b.done))
+	for i, cmd := range b.done {
+		names[i] = cmd.Name()
+	}
+	return names
+}
@@ -0,0 +1,267 @@
+package behavioral
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidTransition = errors.New("behavioral: invalid transition")
+	ErrGuardRejected     = errors.New("behavioral: transition rejected by guard")
+)
+
+type State string
+
+type Event string
+
+// Transition mendeklarasikan perpindahan dari salah satu state From ke To
+// ketika Event terjadi. Guard opsional; error dari Guard menjelaskan kenapa
+// transisi ditolak. Boleh ada beberapa Transition untuk pasangan From/Event
+// yang sama dengan Guard berbeda, yang pertama lolos yang dipakai.
+type Transition[T any] struct {
+	Event Event
+	From  []State
+	To    State
+	Guard func(ctx context.Context, subject T) error
+}
+
+// TransitionInfo dikirim ke action dan listener.
+type TransitionInfo struct {
+	Event Event
+	From  State
+	To    State
+	At    time.Time
+}
+
+type Action[T any] func(ctx context.Context, info TransitionInfo, subject T) error
+
+// Definition adalah deskripsi lengkap sebuah state machine. OnExit state asal
+// jalan lebih dulu, lalu OnEnter state tujuan; kalau salah satunya gagal
+// state tidak berubah. Efek samping OnExit tidak dibatalkan kalau OnEnter
+// gagal, jadi action sebaiknya aman dijalankan ulang saat event yang sama
+// di-Fire lagi.
+//
+// Guard dan action dijalankan selagi Machine terkunci, jadi tidak boleh
+// memanggil method Machine yang sama. Listener dipanggil setelah state
+// berubah dan kunci dilepas, tidak bisa membatalkan transisi, tapi boleh
+// memanggil State, History, bahkan Fire lagi. Listener dari Fire yang
+// berjalan bersamaan bisa saling mendahului; pakai info.From/To, bukan
+// State(), untuk tahu transisi mana yang sedang dilaporkan.
+type Definition[T any] struct {
+	Initial     State
+	Transitions []Transition[T]
+	OnEnter     map[State]Action[T]
+	OnExit      map[State]Action[T]
+	Listeners   []func(ctx context.Context, info TransitionInfo, subject T)
+}
+
+// StateMachine adalah Definition yang sudah divalidasi. Satu StateMachine
+// dipakai bersama oleh banyak Machine (misalnya satu per order).
+type StateMachine[T any] struct {
+	def    Definition[T]
+	byFrom map[State]map[Event][]Transition[T]
+	states map[State]bool
+	now    func() time.Time
+}
+
+func NewStateMachine[T any](def Definition[T]) (*StateMachine[T], error) {
+	if def.Initial == "" {
+		return nil, errors.New("behavioral: state machine needs an initial state")
+	}
+
+	sm := &StateMachine[T]{
+		def:    def,
+		byFrom: make(map[State]map[Event][]Transition[T]),
+		states: map[State]bool{def.Initial: true},
+		now:    time.Now,
+	}
+	for i, t := range def.Transitions {
+		if t.Event == "" || t.To == "" || len(t.From) == 0 {
+			return nil, fmt.Errorf("behavioral: transition %d needs Event, From and To", i)
+		}
+		sm.states[t.To] = true
+		for _, from := range t.From {
+			sm.states[from] = true
+			if sm.byFrom[from] == nil {
+				sm.byFrom[from] = make(map[Event][]Transition[T])
+			}
+			existing := sm.byFrom[from][t.Event]
+			if len(existing) > 0 && existing[len(existing)-1].Guard == nil {
+				// transisi tanpa guard selalu menang, yang setelahnya tidak
+				// akan pernah terpakai
+				return nil, fmt.Errorf("behavioral: transition %s --%s--> %s is unreachable", from, t.Event, t.To)
+			}
+			sm.byFrom[from][t.Event] = append(existing, t)
+		}
+	}
+	for state := range def.OnEnter {
+		if !sm.states[state] {
+			return nil, fmt.Errorf("behavioral: OnEnter for unknown state %q", state)
+		}
+	}
+	for state := range def.OnExit {
+		if !sm.states[state] {
+			return nil, fmt.Errorf("behavioral: OnExit for unknown state %q", state)
+		}
+	}
+
+	return sm, nil
+}
+
+// New membuat Machine baru di state Initial. OnEnter state awal tidak
+// dipanggil.
+func (sm *StateMachine[T]) New(subject T) *Machine[T] {
+	return &Machine[T]{sm: sm, state: sm.def.Initial, subject: subject}
+}
+
+// Restore membuat Machine dari state yang tersimpan, misalnya kolom status
+// di database.
+func (sm *StateMachine[T]) Restore(state State, subject T) (*Machine[T], error) {
+	if !sm.states[state] {
+		return nil, fmt.Errorf("behavioral: unknown state %q", state)
+	}
+	return &Machine[T]{sm: sm, state: state, subject: subject}, nil
+}
+
+// States mengembalikan semua state yang dikenal, terurut.
+func (sm *StateMachine[T]) States() []State {
+	states := make([]State, 0, len(sm.states))
+	for s := range sm.states {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+	return states
+}
+
+// Mermaid menggambar state machine sebagai diagram Mermaid, berguna untuk
+// dokumentasi yang selalu sinkron dengan kode.
+func (sm *StateMachine[T]) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "    [*] --> %s\n", sm.def.Initial)
+	for _, t := range sm.def.Transitions {
+		for _, from := range t.From {
+			label := string(t.Event)
+			if t.Guard != nil {
+				label += " [guarded]"
+			}
+			fmt.Fprintf(&b, "    %s --> %s: %s\n", from, t.To, label)
+		}
+	}
+	return b.String()
+}
+
+// Machine adalah satu instance yang berjalan. Fire aman dipanggil dari
+// banyak goroutine; transisi dijalankan berurutan.
+type Machine[T any] struct {
+	sm      *StateMachine[T]
+	subject T
+
+	mu      sync.Mutex
+	state   State
+	history []TransitionInfo
+}
+
+func (m *Machine[T]) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// History berisi transisi yang sudah terjadi sejak Machine dibuat.
+func (m *Machine[T]) History() []TransitionInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]TransitionInfo(nil), m.history...)
+}
+
+// Can melaporkan apakah event akan diterima saat ini, termasuk cek guard.
+func (m *Machine[T]) Can(ctx context.Context, event Event) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.pick(ctx, event)
+	return err == nil
+}
+
+// AvailableEvents mengembalikan event yang lolos guard dari state sekarang,
+// misalnya untuk menampilkan tombol aksi di UI.
+func (m *Machine[T]) AvailableEvents(ctx context.Context) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []Event
+	for event := range m.sm.byFrom[m.state] {
+		if _, err := m.pick(ctx, event); err == nil {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}
+
+// Fire menjalankan transisi untuk event. Error membungkus
+// ErrInvalidTransition kalau event tidak berlaku di state sekarang, atau
+// ErrGuardRejected beserta alasan dari guard.
+func (m *Machine[T]) Fire(ctx context.Context, event Event) error {
+	info, err := m.transition(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	for _, listener := range m.sm.def.Listeners {
+		listener(ctx, info, m.subject)
+	}
+	return nil
+}
+
+func (m *Machine[T]) transition(ctx context.Context, event Event) (TransitionInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.pick(ctx, event)
+	if err != nil {
+		return TransitionInfo{}, err
+	}
+
+	info := TransitionInfo{Event: event, From: m.state, To: t.To, At: m.sm.now()}
+	if exit := m.sm.def.OnExit[info.From]; exit != nil {
+		if err := exit(ctx, info, m.subject); err != nil {
+			return TransitionInfo{}, fmt.Errorf("behavioral: exit %s: %w", info.From, err)
+		}
+	}
+	if enter := m.sm.def.OnEnter[info.To]; enter != nil {
+		if err := enter(ctx, info, m.subject); err != nil {
+			return TransitionInfo{}, fmt.Errorf("behavioral: enter %s: %w", info.To, err)
+		}
+	}
+
+	m.state = info.To
+	m.history = append(m.history, info)
+	return info, nil
+}
+
+func (m *Machine[T]) pick(ctx context.Context, event Event) (Transition[T], error) {
+	candidates := m.sm.byFrom[m.state][event]
+	if len(candidates) == 0 {
+		return Transition[T]{}, fmt.Errorf("%w: %q in state %q", ErrInvalidTransition, event, m.state)
+	}
+
+	var reasons []error
+	for _, t := range candidates {
+		if t.Guard == nil {
+			return t, nil
+		}
+		err := t.Guard(ctx, m.subject)
+		if err == nil {
+			return t, nil
+		}
+		reasons = append(reasons, err)
+	}
+	return Transition[T]{}, fmt.Errorf("%w: %q in state %q: %w", ErrGuardRejected, event, m.state, errors.Join(reasons...))
+}
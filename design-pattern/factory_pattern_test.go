@@ -1,8 +1,11 @@
 package designpattern
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"testing"
+
+	"go-journey/design-pattern/notification"
 )
 
 /*
@@ -17,37 +20,36 @@ Easier to test and mock different implementations
 
 */
 
-type Notifier interface {
-	Send(msg string)
-}
-
-type EmailNotifier struct {
-}
-
-func (e *EmailNotifier) Send(msg string) {
-	fmt.Println("Hello send message from Email Notifier with message: ", msg)
-}
-
-type SmsNotifier struct{}
-
-func (e *SmsNotifier) Send(msg string) {
-	fmt.Println("Hello send message from Sms Notifier with message: ", msg)
+// Versi awal memakai switch di GetNotifier dan mengembalikan nil untuk
+// channel yang tidak dikenal. Sekarang setiap driver (smtp, webhook, console)
+// mendaftarkan dirinya sendiri di package notification, dan Registry yang
+// berperan sebagai factory: channel baru cukup di-Open tanpa mengubah kode
+// ini.
 
+func GetNotifier(r *notification.Registry, channel string) (notification.Notifier, error) {
+	return r.Notifier(channel)
 }
 
-func GetNotifier(channel string) Notifier {
-	switch channel {
-	case "email":
-		return &EmailNotifier{}
-	case "sms":
-		return &SmsNotifier{}
+func TestFactoryPattern(t *testing.T) {
+	r := notification.NewRegistry(nil)
+	if err := r.Open("email", "console", map[string]string{"prefix": "Email Notifier"}, notification.RetryPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Open("sms", "console", map[string]string{"prefix": "Sms Notifier"}, notification.RetryPolicy{}); err != nil {
+		t.Fatal(err)
+	}
 
-	default:
-		return nil
+	err := r.Send(context.Background(), notification.Message{
+		Channel: "email",
+		To:      []string{"abang@example.com"},
+		Body:    "Hallo {{.}} sekalian",
+		Data:    "abang-abang jago",
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
-}
 
-func TestFactoryPattern(t *testing.T) {
-	notifer := GetNotifier("email")
-	notifer.Send("Hallo abang-abang jago sekalian")
+	if _, err := GetNotifier(r, "push"); !errors.Is(err, notification.ErrUnknownChannel) {
+		t.Fatalf("unknown channel must return an error instead of nil, got %v", err)
+	}
 }
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+func init() {
+	RegisterDriver("console", func(opts map[string]string) (Notifier, error) {
+		return NewConsole(os.Stdout, opts["prefix"]), nil
+	})
+}
+
+// ConsoleNotifier hanya mencetak pesan, untuk development atau channel yang
+// belum punya provider (misalnya SMS).
+type ConsoleNotifier struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+}
+
+func NewConsole(w io.Writer, prefix string) *ConsoleNotifier {
+	return &ConsoleNotifier{w: w, prefix: prefix}
+}
+
+func (c *ConsoleNotifier) Send(_ context.Context, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := c.prefix
+	if prefix == "" {
+		prefix = msg.Channel
+	}
+	_, err := fmt.Fprintf(c.w, "[%s] to=%s subject=%q body=%q\n", prefix, strings.Join(msg.To, ","), msg.Subject, msg.Body)
+	return err
+}
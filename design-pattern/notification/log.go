@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusSent   Status = "sent"
+	StatusFailed Status = "failed"
+)
+
+// Delivery adalah satu percobaan pengiriman. Body tidak ikut dicatat karena
+// bisa berisi data pribadi.
+type Delivery struct {
+	MessageID string        `json:"message_id"`
+	Channel   string        `json:"channel"`
+	To        []string      `json:"to"`
+	Subject   string        `json:"subject,omitempty"`
+	Attempt   int           `json:"attempt"`
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	At        time.Time     `json:"at"`
+	Duration  time.Duration `json:"duration_ns"`
+}
+
+type DeliveryLog interface {
+	Record(ctx context.Context, d Delivery) error
+}
+
+type discardLog struct{}
+
+func (discardLog) Record(context.Context, Delivery) error { return nil }
+
+// MemoryDeliveryLog menyimpan delivery di memori, cocok untuk test dan
+// halaman admin sederhana.
+type MemoryDeliveryLog struct {
+	mu         sync.Mutex
+	deliveries []Delivery
+}
+
+func (l *MemoryDeliveryLog) Record(_ context.Context, d Delivery) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.deliveries = append(l.deliveries, d)
+	return nil
+}
+
+func (l *MemoryDeliveryLog) Deliveries() []Delivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Delivery(nil), l.deliveries...)
+}
+
+// ForMessage mengembalikan semua percobaan untuk satu message ID.
+func (l *MemoryDeliveryLog) ForMessage(id string) []Delivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var out []Delivery
+	for _, d := range l.deliveries {
+		if d.MessageID == id {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// JSONDeliveryLog menulis satu baris JSON per delivery, misalnya ke file
+// yang dibuka dengan O_APPEND.
+type JSONDeliveryLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONDeliveryLog(w io.Writer) *JSONDeliveryLog {
+	return &JSONDeliveryLog{enc: json.NewEncoder(w)}
+}
+
+func (l *JSONDeliveryLog) Record(_ context.Context, d Delivery) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.enc.Encode(d)
+}
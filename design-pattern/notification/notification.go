@@ -0,0 +1,297 @@
+// Package notification mengirim pesan lewat berbagai channel (email, webhook,
+// dll) dengan factory pattern: setiap driver mendaftarkan dirinya sendiri
+// lewat RegisterDriver di init(), lalu Registry membuat notifier per channel
+// dari nama driver dan opsinya, mirip database/sql dengan sql.Register.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+var (
+	ErrUnknownChannel = errors.New("notification: unknown channel")
+	ErrUnknownDriver  = errors.New("notification: unknown driver")
+)
+
+// Message adalah pesan yang akan dikirim. Subject dan Body adalah template
+// text/template yang dirender dengan Data sebelum diteruskan ke notifier,
+// misalnya Body: "Halo {{.Name}}, saldo kamu {{.Balance}}".
+type Message struct {
+	ID      string // diisi otomatis kalau kosong
+	Channel string
+	To      []string
+	Subject string
+	Body    string
+	Data    any
+}
+
+// Notifier mengirim pesan yang sudah dirender ke satu channel. Kembalikan
+// error dibungkus Permanent kalau mengulang tidak ada gunanya.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type NotifierFunc func(ctx context.Context, msg Message) error
+
+func (f NotifierFunc) Send(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// Factory membuat notifier dari opsi string, misalnya hasil baca file config.
+type Factory func(opts map[string]string) (Notifier, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+// RegisterDriver dipanggil dari init() tiap driver. Panic kalau nama sudah
+// dipakai, sama seperti sql.Register.
+func RegisterDriver(name string, f Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if f == nil {
+		panic("notification: RegisterDriver factory is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("notification: RegisterDriver called twice for driver " + name)
+	}
+	drivers[name] = f
+}
+
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RetryPolicy per channel. Zero value berarti satu kali coba tanpa retry.
+type RetryPolicy struct {
+	MaxAttempts int           // total percobaan, default 1
+	Backoff     time.Duration // jeda sebelum retry pertama, lalu dikali 2
+	MaxBackoff  time.Duration // default 30 detik
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent menandai error yang tidak perlu di-retry, misalnya alamat email
+// ditolak server atau webhook membalas 400.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+type channel struct {
+	notifier Notifier
+	retry    RetryPolicy
+}
+
+type Registry struct {
+	mu       sync.RWMutex
+	channels map[string]channel
+	log      DeliveryLog
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRegistry membuat registry kosong. log boleh nil kalau tidak perlu
+// delivery log.
+func NewRegistry(log DeliveryLog) *Registry {
+	if log == nil {
+		log = discardLog{}
+	}
+	return &Registry{
+		channels: make(map[string]channel),
+		log:      log,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// Register memasang notifier yang sudah jadi ke sebuah channel.
+func (r *Registry) Register(name string, n Notifier, retry RetryPolicy) {
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = 1
+	}
+	if retry.MaxBackoff <= 0 {
+		retry.MaxBackoff = 30 * time.Second
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.channels[name] = channel{notifier: n, retry: retry}
+}
+
+// Open membuat notifier dari driver terdaftar lalu memasangnya ke channel.
+func (r *Registry) Open(name, driver string, opts map[string]string, retry RetryPolicy) error {
+	driversMu.RLock()
+	f, ok := drivers[driver]
+	driversMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w %q (registered: %s)", ErrUnknownDriver, driver, strings.Join(Drivers(), ", "))
+	}
+
+	n, err := f(opts)
+	if err != nil {
+		return fmt.Errorf("notification: open channel %s with driver %s: %w", name, driver, err)
+	}
+
+	r.Register(name, n, retry)
+	return nil
+}
+
+func (r *Registry) Notifier(name string) (Notifier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ch, ok := r.channels[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownChannel, name)
+	}
+	return ch.notifier, nil
+}
+
+// Send merender template pesan lalu mengirimnya ke channel msg.Channel
+// dengan retry sesuai policy channel. Setiap percobaan dicatat di delivery
+// log.
+func (r *Registry) Send(ctx context.Context, msg Message) error {
+	r.mu.RLock()
+	ch, ok := r.channels[msg.Channel]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownChannel, msg.Channel)
+	}
+	if len(msg.To) == 0 {
+		return errors.New("notification: message has no recipient")
+	}
+
+	rendered, err := render(msg)
+	if err != nil {
+		return err
+	}
+
+	backoff := ch.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		start := r.now()
+		err := ch.notifier.Send(ctx, rendered)
+		r.record(ctx, rendered, attempt, start, err)
+
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) || attempt >= ch.retry.MaxAttempts {
+			return fmt.Errorf("notification: send %s via %s failed after %d attempt(s): %w", rendered.ID, rendered.Channel, attempt, err)
+		}
+
+		if err := r.sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, ch.retry.MaxBackoff)
+	}
+}
+
+func (r *Registry) record(ctx context.Context, msg Message, attempt int, start time.Time, err error) {
+	d := Delivery{
+		MessageID: msg.ID,
+		Channel:   msg.Channel,
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Attempt:   attempt,
+		Status:    StatusSent,
+		At:        start,
+		Duration:  r.now().Sub(start),
+	}
+	if err != nil {
+		d.Status = StatusFailed
+		d.Error = err.Error()
+	}
+
+	// gagal mencatat log tidak boleh menggagalkan pengiriman
+	_ = r.log.Record(ctx, d)
+}
+
+func render(msg Message) (Message, error) {
+	if msg.ID == "" {
+		msg.ID = newMessageID()
+	}
+
+	var err error
+	if msg.Subject, err = execute("subject", msg.Subject, msg.Data); err != nil {
+		return Message{}, err
+	}
+	if msg.Body, err = execute("body", msg.Body, msg.Data); err != nil {
+		return Message{}, err
+	}
+	msg.Data = nil
+
+	return msg, nil
+}
+
+func execute(name, text string, data any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("notification: parse %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("notification: render %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func newMessageID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
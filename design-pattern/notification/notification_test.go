@@ -0,0 +1,349 @@
+package notification
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP adalah server SMTP minimal untuk test: tanpa TLS dan AUTH,
+// menyimpan email yang diterima.
+type fakeSMTP struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	received []*mail.Message
+	failData int             // jumlah DATA berikutnya yang dibalas 451
+	reject   map[string]bool // RCPT yang dibalas 550
+	dropQuit bool            // tutup koneksi tanpa membalas QUIT
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, reject: make(map[string]bool)}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) addr() string { return s.ln.Addr().String() }
+
+func (s *fakeSMTP) messages() []*mail.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*mail.Message(nil), s.received...)
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP ready")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-fake greets %s", arg)
+			tp.PrintfLine("250 8BITMIME")
+		case "MAIL", "NOOP", "RSET":
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			addr := strings.Trim(strings.TrimPrefix(strings.ToUpper(arg), "TO:"), "<>")
+			s.mu.Lock()
+			rejected := s.reject[strings.ToLower(addr)]
+			s.mu.Unlock()
+			if rejected {
+				tp.PrintfLine("550 no such user")
+			} else {
+				tp.PrintfLine("250 OK")
+			}
+		case "DATA":
+			s.mu.Lock()
+			fail := s.failData > 0
+			if fail {
+				s.failData--
+			}
+			s.mu.Unlock()
+			if fail {
+				tp.PrintfLine("451 try again later")
+				continue
+			}
+
+			tp.PrintfLine("354 end with <CRLF>.<CRLF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg, err := mail.ReadMessage(bytes.NewReader(data))
+			if err != nil {
+				tp.PrintfLine("554 malformed message")
+				continue
+			}
+			s.mu.Lock()
+			s.received = append(s.received, msg)
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			s.mu.Lock()
+			drop := s.dropQuit
+			s.mu.Unlock()
+			if !drop {
+				tp.PrintfLine("221 bye")
+			}
+			return
+		default:
+			tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func newTestRegistry(log DeliveryLog) (*Registry, *[]time.Duration) {
+	r := NewRegistry(log)
+	var sleeps []time.Duration
+	r.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return r, &sleeps
+}
+
+func TestSMTPSendsTemplatedEmail(t *testing.T) {
+	srv := startFakeSMTP(t)
+	r, _ := newTestRegistry(nil)
+
+	err := r.Open("email", "smtp", map[string]string{"addr": srv.addr(), "from": "noreply@go-journey.dev", "timeout": "2s"}, RetryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Send(context.Background(), Message{
+		ID:      "welcome-1",
+		Channel: "email",
+		To:      []string{"bisma@example.com"},
+		Subject: "Selamat datang, {{.Name}} 👋",
+		Body:    "Halo {{.Name}},\n\nSaldo awal kamu {{.Balance}}.\n.\nTerima kasih",
+		Data:    map[string]any{"Name": "Bisma", "Balance": 10000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := srv.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(msgs))
+	}
+	msg := msgs[0]
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Selamat datang, Bisma 👋" {
+		t.Fatalf("subject %q", subject)
+	}
+	if msg.Header.Get("Message-ID") != "<welcome-1@go-journey.dev>" || msg.Header.Get("To") != "bisma@example.com" {
+		t.Fatalf("unexpected headers %v", msg.Header)
+	}
+
+	// ReadDotBytes sudah mengubah CRLF jadi LF; baris "." harus selamat
+	// berkat dot-stuffing
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	want := "Halo Bisma,\n\nSaldo awal kamu 10000.\n.\nTerima kasih"
+	if strings.TrimRight(string(body), "\n") != want {
+		t.Fatalf("body %q", body)
+	}
+}
+
+func TestSMTPIgnoresQuitErrorAfterAcceptedData(t *testing.T) {
+	srv := startFakeSMTP(t)
+	srv.dropQuit = true
+
+	log := &MemoryDeliveryLog{}
+	r, _ := newTestRegistry(log)
+	smtpNotifier, _ := NewSMTP(SMTPConfig{Addr: srv.addr(), From: "noreply@go-journey.dev"})
+	r.Register("email", smtpNotifier, RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond})
+
+	if err := r.Send(context.Background(), Message{ID: "m1", Channel: "email", To: []string{"a@example.com"}, Body: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.messages()); n != 1 {
+		t.Fatalf("accepted email must not be sent again, server got %d", n)
+	}
+	if n := len(log.Deliveries()); n != 1 {
+		t.Fatalf("expected one delivery attempt, got %d", n)
+	}
+}
+
+func TestSMTPRetriesTemporaryFailures(t *testing.T) {
+	srv := startFakeSMTP(t)
+	srv.failData = 2
+
+	log := &MemoryDeliveryLog{}
+	r, sleeps := newTestRegistry(log)
+	smtpNotifier, _ := NewSMTP(SMTPConfig{Addr: srv.addr(), From: "noreply@go-journey.dev"})
+	r.Register("email", smtpNotifier, RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond})
+
+	err := r.Send(context.Background(), Message{ID: "m1", Channel: "email", To: []string{"a@example.com"}, Body: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := log.ForMessage("m1")
+	var statuses []string
+	for _, d := range deliveries {
+		statuses = append(statuses, string(d.Status))
+	}
+	if got := strings.Join(statuses, ","); got != "failed,failed,sent" {
+		t.Fatalf("delivery log: %s", got)
+	}
+	if !strings.Contains(deliveries[0].Error, "451") || deliveries[2].Attempt != 3 {
+		t.Fatalf("unexpected deliveries %+v", deliveries)
+	}
+	if len(*sleeps) != 2 || (*sleeps)[0] != 10*time.Millisecond || (*sleeps)[1] != 20*time.Millisecond {
+		t.Fatalf("backoff %v", *sleeps)
+	}
+}
+
+func TestSMTPPermanentFailureIsNotRetried(t *testing.T) {
+	srv := startFakeSMTP(t)
+	srv.reject["ghost@example.com"] = true
+
+	log := &MemoryDeliveryLog{}
+	r, _ := newTestRegistry(log)
+	n, _ := NewSMTP(SMTPConfig{Addr: srv.addr(), From: "noreply@go-journey.dev"})
+	r.Register("email", n, RetryPolicy{MaxAttempts: 5})
+
+	err := r.Send(context.Background(), Message{Channel: "email", To: []string{"ghost@example.com"}, Body: "hi"})
+	if err == nil || !IsPermanent(err) || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("expected permanent 550 error, got %v", err)
+	}
+	if len(log.Deliveries()) != 1 {
+		t.Fatalf("permanent failure must not be retried, got %d attempts", len(log.Deliveries()))
+	}
+
+	err = r.Send(context.Background(), Message{Channel: "email", To: []string{"a@example.com"}, Subject: "hi\r\nBcc: victim@example.com"})
+	if !IsPermanent(err) {
+		t.Fatalf("header injection must be rejected, got %v", err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifySignature("rahasia", body, r.Header.Get(SignatureHeader)); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Idempotency-Key") == "" {
+			http.Error(w, "missing idempotency key", http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	log := &MemoryDeliveryLog{}
+	r, _ := newTestRegistry(log)
+	retry := RetryPolicy{MaxAttempts: 3}
+	if err := r.Open("slack", "webhook", map[string]string{"url": srv.URL, "secret": "rahasia"}, retry); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Open("unsigned", "webhook", map[string]string{"url": srv.URL}, retry); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := Message{Channel: "slack", To: []string{"#ops"}, Body: "deploy {{.Version}} selesai", Data: struct{ Version string }{"v1.2.0"}}
+	if err := r.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Deliveries()) != 2 {
+		t.Fatalf("expected 503 then success, got %+v", log.Deliveries())
+	}
+
+	msg.Channel = "unsigned"
+	err := r.Send(context.Background(), msg)
+	if !IsPermanent(err) || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("4xx must be permanent, got %v", err)
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	r, _ := newTestRegistry(nil)
+
+	if err := r.Send(context.Background(), Message{Channel: "pigeon", To: []string{"x"}}); !errors.Is(err, ErrUnknownChannel) {
+		t.Fatalf("unknown channel: %v", err)
+	}
+	if err := r.Open("pigeon", "carrier", nil, RetryPolicy{}); !errors.Is(err, ErrUnknownDriver) {
+		t.Fatalf("unknown driver: %v", err)
+	}
+	if err := r.Open("email", "smtp", map[string]string{"addr": "no-port"}, RetryPolicy{}); err == nil {
+		t.Fatal("invalid driver options must fail")
+	}
+
+	r.Register("sms", NotifierFunc(func(context.Context, Message) error { return nil }), RetryPolicy{})
+	err := r.Send(context.Background(), Message{Channel: "sms", To: []string{"0812"}, Body: "OTP {{.Code}}", Data: map[string]string{}})
+	if err == nil || !strings.Contains(err.Error(), "render body template") {
+		t.Fatalf("missing template key must fail, got %v", err)
+	}
+}
+
+func TestJSONDeliveryLog(t *testing.T) {
+	var buf bytes.Buffer
+	r, _ := newTestRegistry(NewJSONDeliveryLog(&buf))
+	r.Register("sms", NewConsole(io.Discard, ""), RetryPolicy{})
+
+	r.Send(context.Background(), Message{ID: "a", Channel: "sms", To: []string{"0812"}, Body: "x"})
+	r.Send(context.Background(), Message{ID: "b", Channel: "sms", To: []string{"0813"}, Body: "y"})
+
+	sc := bufio.NewScanner(&buf)
+	lines := 0
+	for sc.Scan() {
+		if !strings.Contains(sc.Text(), `"status":"sent"`) {
+			t.Fatalf("unexpected line %s", sc.Text())
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("expected 2 lines, got %d", lines)
+	}
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterDriver("smtp", func(opts map[string]string) (Notifier, error) {
+		cfg := SMTPConfig{
+			Addr:     opts["addr"],
+			From:     opts["from"],
+			Username: opts["username"],
+			Password: opts["password"],
+		}
+		if v := opts["timeout"]; v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("smtp timeout: %w", err)
+			}
+			cfg.Timeout = d
+		}
+		return NewSMTP(cfg)
+	})
+}
+
+type SMTPConfig struct {
+	Addr     string // host:port
+	From     string
+	Username string // kosong = tanpa AUTH
+	Password string
+	Timeout  time.Duration // default 10 detik untuk seluruh sesi SMTP
+}
+
+// SMTPNotifier mengirim email plain text. STARTTLS dipakai otomatis kalau
+// server mendukung; net/smtp menolak AUTH PLAIN tanpa TLS kecuali ke
+// localhost.
+type SMTPNotifier struct {
+	cfg SMTPConfig
+	now func() time.Time
+}
+
+func NewSMTP(cfg SMTPConfig) (*SMTPNotifier, error) {
+	if cfg.Addr == "" || cfg.From == "" {
+		return nil, errors.New("notification: SMTPConfig.Addr and From are required")
+	}
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		return nil, fmt.Errorf("notification: invalid SMTP address: %w", err)
+	}
+	if err := checkHeader(cfg.From); err != nil {
+		return nil, err
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &SMTPNotifier{cfg: cfg, now: time.Now}, nil
+}
+
+func (s *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	for _, v := range append([]string{msg.Subject}, msg.To...) {
+		if err := checkHeader(v); err != nil {
+			return Permanent(err)
+		}
+	}
+
+	data, err := s.buildMessage(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	host, _, _ := net.SplitHostPort(s.cfg.Addr)
+	dialer := net.Dialer{Timeout: s.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	deadline := s.now().Add(s.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return classifySMTP(err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)); err != nil {
+			return classifySMTP(err)
+		}
+	}
+
+	if err := c.Mail(s.cfg.From); err != nil {
+		return classifySMTP(err)
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return classifySMTP(err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return classifySMTP(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTP(err)
+	}
+
+	// server sudah menerima email; error saat QUIT (koneksi putus, deadline)
+	// tidak boleh membuat Registry mengirim ulang
+	_ = c.Quit()
+	return nil
+}
+
+func (s *SMTPNotifier) buildMessage(msg Message) ([]byte, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(s.cfg.From, "@"); at >= 0 {
+		domain = strings.Trim(s.cfg.From[at+1:], "> ")
+	}
+
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", s.cfg.From)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", s.now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", msg.ID, domain))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// checkHeader mencegah header injection lewat subject atau alamat.
+func checkHeader(v string) error {
+	if strings.ContainsAny(v, "\r\n") {
+		return fmt.Errorf("notification: header value %q contains a line break", v)
+	}
+	return nil
+}
+
+// classifySMTP: balasan 5xx berarti ditolak permanen, 4xx masih boleh
+// dicoba lagi.
+func classifySMTP(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
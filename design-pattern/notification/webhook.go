@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterDriver("webhook", func(opts map[string]string) (Notifier, error) {
+		cfg := WebhookConfig{URL: opts["url"], Secret: opts["secret"]}
+		if v := opts["timeout"]; v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("webhook timeout: %w", err)
+			}
+			cfg.Timeout = d
+		}
+		return NewWebhook(cfg)
+	})
+}
+
+// SignatureHeader berisi "sha256=<hex>" dari HMAC-SHA256 body memakai
+// WebhookConfig.Secret, supaya penerima bisa memastikan request dari kita.
+const SignatureHeader = "X-Notification-Signature"
+
+type WebhookConfig struct {
+	URL     string
+	Secret  string        // kosong = tanpa signature
+	Timeout time.Duration // default 10 detik
+	Client  *http.Client  // default http.Client dengan Timeout
+}
+
+type WebhookNotifier struct {
+	cfg WebhookConfig
+}
+
+type webhookPayload struct {
+	ID      string   `json:"id"`
+	Channel string   `json:"channel"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject,omitempty"`
+	Body    string   `json:"body"`
+}
+
+func NewWebhook(cfg WebhookConfig) (*WebhookNotifier, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("notification: invalid webhook URL %q", cfg.URL)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return &WebhookNotifier{cfg: cfg}, nil
+}
+
+func (wh *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:      msg.ID,
+		Channel: msg.Channel,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// penerima bisa memakai ID ini untuk membuang kiriman ganda saat retry
+	req.Header.Set("Idempotency-Key", msg.ID)
+	if wh.cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(wh.cfg.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := wh.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("webhook responded %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode >= 500:
+		return err
+	default:
+		return Permanent(err)
+	}
+}
+
+// VerifySignature dipakai di sisi penerima webhook.
+func VerifySignature(secret string, body []byte, header string) error {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	want, err := hex.DecodeString(sig)
+	if !ok || err != nil {
+		return errors.New("notification: malformed signature")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), want) {
+		return errors.New("notification: signature mismatch")
+	}
+	return nil
+}
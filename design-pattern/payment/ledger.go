@@ -0,0 +1,169 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrUnbalanced = errors.New("payment: ledger transaction is not balanced")
+
+// Nama akun ledger. Clearing adalah uang yang masih dipegang provider dan
+// belum dicairkan ke rekening kita.
+const (
+	AccountRevenue = "revenue"
+	AccountRefunds = "refunds"
+)
+
+func ClearingAccount(provider string) string {
+	return "clearing:" + provider
+}
+
+// Entry adalah satu baris jurnal. Amount positif berarti debit, negatif
+// berarti kredit.
+type Entry struct {
+	Account string
+	Amount  Money
+}
+
+type TxKind string
+
+const (
+	TxCharge TxKind = "charge"
+	TxRefund TxKind = "refund"
+)
+
+// Transaction dicatat utuh atau tidak sama sekali, dan jumlah semua entry
+// per mata uang harus nol (double-entry).
+type Transaction struct {
+	ID          string
+	Kind        TxKind
+	PaymentID   string
+	Provider    string
+	ProviderRef string
+	Entries     []Entry
+	PostedAt    time.Time
+}
+
+// Amount adalah nilai transaksi: total debit.
+func (tx Transaction) Amount() Money {
+	var total Money
+	for _, e := range tx.Entries {
+		if e.Amount.Minor > 0 {
+			total.Currency = e.Amount.Currency
+			total.Minor += e.Amount.Minor
+		}
+	}
+	return total
+}
+
+type Ledger struct {
+	mu           sync.RWMutex
+	transactions []Transaction
+	balances     map[string]map[string]int64 // account -> currency -> minor
+	now          func() time.Time
+	// failPost dipakai test untuk mensimulasikan penyimpanan ledger yang
+	// gagal setelah provider berhasil.
+	failPost func(Transaction) error
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{balances: make(map[string]map[string]int64), now: time.Now}
+}
+
+func (l *Ledger) Post(tx Transaction) (Transaction, error) {
+	if len(tx.Entries) < 2 {
+		return Transaction{}, fmt.Errorf("%w: need at least two entries", ErrUnbalanced)
+	}
+
+	sums := make(map[string]Money)
+	for _, e := range tx.Entries {
+		if e.Account == "" || e.Amount.IsZero() {
+			return Transaction{}, fmt.Errorf("payment: invalid ledger entry %+v", e)
+		}
+		sum, ok := sums[e.Amount.Currency]
+		if !ok {
+			sum = Money{Currency: e.Amount.Currency}
+		}
+		sum, err := sum.Add(e.Amount)
+		if err != nil {
+			return Transaction{}, err
+		}
+		sums[e.Amount.Currency] = sum
+	}
+	for currency, sum := range sums {
+		if !sum.IsZero() {
+			return Transaction{}, fmt.Errorf("%w: %s off by %s", ErrUnbalanced, currency, sum.Decimal())
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.failPost != nil {
+		if err := l.failPost(tx); err != nil {
+			return Transaction{}, err
+		}
+	}
+	if tx.ID == "" {
+		tx.ID = fmt.Sprintf("tx_%06d", len(l.transactions)+1)
+	}
+	tx.PostedAt = l.now()
+	tx.Entries = append([]Entry(nil), tx.Entries...)
+
+	for _, e := range tx.Entries {
+		if l.balances[e.Account] == nil {
+			l.balances[e.Account] = make(map[string]int64)
+		}
+		l.balances[e.Account][e.Amount.Currency] += e.Amount.Minor
+	}
+	l.transactions = append(l.transactions, tx)
+
+	return tx, nil
+}
+
+func (l *Ledger) Balance(account, currency string) Money {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return Money{Minor: l.balances[account][currency], Currency: currency}
+}
+
+func (l *Ledger) Transactions() []Transaction {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]Transaction(nil), l.transactions...)
+}
+
+// TrialBalance menjumlahkan saldo semua akun per mata uang. Hasilnya selalu
+// nol kalau ledger konsisten.
+func (l *Ledger) TrialBalance() map[string]Money {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	out := make(map[string]Money)
+	for _, byCurrency := range l.balances {
+		for currency, minor := range byCurrency {
+			m := out[currency]
+			m.Currency = currency
+			m.Minor += minor
+			out[currency] = m
+		}
+	}
+	return out
+}
+
+func (l *Ledger) Accounts() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	accounts := make([]string, 0, len(l.balances))
+	for a := range l.balances {
+		accounts = append(accounts, a)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
@@ -0,0 +1,147 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrCurrencyMismatch = errors.New("payment: currency mismatch")
+
+// currencyExponent adalah jumlah digit desimal per mata uang (ISO 4217).
+var currencyExponent = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"JPY": 0,
+}
+
+// Money disimpan sebagai integer dalam satuan terkecil (sen) supaya tidak
+// ada error pembulatan float64: 0.1 + 0.2 tetap 0.3.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+func New(minor int64, currency string) (Money, error) {
+	if _, ok := currencyExponent[currency]; !ok {
+		return Money{}, fmt.Errorf("payment: unsupported currency %q", currency)
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// ParseMoney membaca nominal desimal seperti "12.34" atau "-5". Digit di
+// belakang koma tidak boleh melebihi exponent mata uangnya.
+func ParseMoney(amount, currency string) (Money, error) {
+	exp, ok := currencyExponent[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("payment: unsupported currency %q", currency)
+	}
+
+	s := strings.TrimSpace(amount)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || (hasFrac && frac == "") || len(frac) > exp {
+		return Money{}, fmt.Errorf("payment: invalid %s amount %q", currency, amount)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("payment: invalid %s amount %q", currency, amount)
+	}
+	if neg {
+		minor = -minor
+	}
+
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+func MustParse(amount, currency string) Money {
+	m, err := ParseMoney(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	if (o.Minor > 0 && m.Minor > math.MaxInt64-o.Minor) || (o.Minor < 0 && m.Minor < math.MinInt64-o.Minor) {
+		return Money{}, errors.New("payment: amount overflow")
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Neg())
+}
+
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+func (m Money) IsZero() bool     { return m.Minor == 0 }
+func (m Money) IsPositive() bool { return m.Minor > 0 }
+
+// Decimal mengembalikan nominal tanpa kode mata uang, misalnya "12.34".
+func (m Money) Decimal() string {
+	exp := currencyExponent[m.Currency]
+
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absInt64(minor), 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+func absInt64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON menulis nominal sebagai string supaya klien JavaScript tidak
+// mengubahnya jadi float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := ParseMoney(raw.Amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
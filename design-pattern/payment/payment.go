@@ -0,0 +1,347 @@
+// Package payment adalah versi serius dari contoh strategy pattern: setiap
+// Provider (PayPal, Stripe, ...) adalah strategy, Processor memilihnya saat
+// runtime berdasarkan PaymentRequest.Provider, lalu mencatat hasilnya di
+// ledger double-entry. Nominal memakai Money (integer satuan terkecil),
+// bukan float64.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownProvider      = errors.New("payment: unknown provider")
+	ErrUnknownPayment       = errors.New("payment: unknown payment")
+	ErrIdempotencyConflict  = errors.New("payment: idempotency key reused with a different request")
+	ErrRefundExceedsPayment = errors.New("payment: refund exceeds refundable amount")
+	// ErrLedgerPost berarti provider sudah memindahkan uang tapi jurnalnya
+	// gagal dicatat. Error ini final: retry tidak boleh menagih atau
+	// me-refund lagi, selisihnya diselesaikan lewat rekonsiliasi.
+	ErrLedgerPost = errors.New("payment: provider succeeded but ledger post failed")
+)
+
+type PaymentRequest struct {
+	// IdempotencyKey wajib diisi klien dan dipakai ulang saat retry. Request
+	// kedua dengan key yang sama mengembalikan hasil yang sama tanpa
+	// menagih lagi.
+	IdempotencyKey string
+	Provider       string
+	Amount         Money
+	Customer       string
+	Description    string
+}
+
+type RefundPaymentRequest struct {
+	IdempotencyKey string
+	PaymentID      string
+	Amount         Money
+}
+
+type Receipt struct {
+	ID            string    `json:"id"`
+	Kind          TxKind    `json:"kind"`
+	PaymentID     string    `json:"payment_id"`
+	Provider      string    `json:"provider"`
+	ProviderRef   string    `json:"provider_ref"`
+	Amount        Money     `json:"amount"`
+	TransactionID string    `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type paymentRecord struct {
+	provider string
+	ref      string
+	amount   Money
+	// refunded termasuk refund yang sedang diproses, supaya dua refund
+	// bersamaan tidak melebihi nominal pembayaran
+	refunded Money
+}
+
+// Unposted adalah transaksi yang sudah berhasil di provider tapi gagal
+// dicatat di ledger, dan perlu diposting ulang oleh tim finance.
+type Unposted struct {
+	Transaction Transaction
+	Err         error
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	done        chan struct{}
+	receipt     Receipt
+	err         error
+}
+
+type Processor struct {
+	ledger    *Ledger
+	providers map[string]Provider
+
+	mu       sync.Mutex
+	keys     map[string]*idempotencyEntry
+	payments map[string]*paymentRecord
+	unposted []Unposted
+	seq      int
+}
+
+func NewProcessor(ledger *Ledger, providers ...Provider) *Processor {
+	p := &Processor{
+		ledger:    ledger,
+		providers: make(map[string]Provider),
+		keys:      make(map[string]*idempotencyEntry),
+		payments:  make(map[string]*paymentRecord),
+	}
+	for _, provider := range providers {
+		p.providers[provider.Name()] = provider
+	}
+	return p
+}
+
+// Pay menagih lewat provider yang diminta lalu mencatat jurnal:
+// debit clearing:<provider>, kredit revenue.
+func (p *Processor) Pay(ctx context.Context, req PaymentRequest) (Receipt, error) {
+	if req.IdempotencyKey == "" {
+		return Receipt{}, errors.New("payment: IdempotencyKey is required")
+	}
+	if !req.Amount.IsPositive() {
+		return Receipt{}, fmt.Errorf("payment: amount must be positive, got %s", req.Amount)
+	}
+	provider, ok := p.providers[req.Provider]
+	if !ok {
+		return Receipt{}, fmt.Errorf("%w %q", ErrUnknownProvider, req.Provider)
+	}
+
+	fingerprint := fmt.Sprintf("pay|%s|%s|%s", req.Provider, req.Amount, req.Customer)
+	return p.idempotent(ctx, req.IdempotencyKey, fingerprint, func() (Receipt, error) {
+		ref, err := provider.Charge(ctx, ChargeRequest{
+			IdempotencyKey: req.IdempotencyKey,
+			Amount:         req.Amount,
+			Customer:       req.Customer,
+			Description:    req.Description,
+		})
+		if err != nil {
+			return Receipt{}, err
+		}
+
+		paymentID := p.nextID("pay")
+		tx := Transaction{
+			Kind:        TxCharge,
+			PaymentID:   paymentID,
+			Provider:    req.Provider,
+			ProviderRef: ref,
+			Entries: []Entry{
+				{Account: ClearingAccount(req.Provider), Amount: req.Amount},
+				{Account: AccountRevenue, Amount: req.Amount.Neg()},
+			},
+		}
+		posted, postErr := p.ledger.Post(tx)
+
+		// pembayaran tetap dicatat walaupun jurnal gagal: uangnya sudah
+		// terpotong dan harus tetap bisa di-refund
+		p.mu.Lock()
+		p.payments[paymentID] = &paymentRecord{
+			provider: req.Provider,
+			ref:      ref,
+			amount:   req.Amount,
+			refunded: Money{Currency: req.Amount.Currency},
+		}
+		p.mu.Unlock()
+
+		receipt := Receipt{
+			ID:            paymentID,
+			Kind:          TxCharge,
+			PaymentID:     paymentID,
+			Provider:      req.Provider,
+			ProviderRef:   ref,
+			Amount:        req.Amount,
+			TransactionID: posted.ID,
+			CreatedAt:     posted.PostedAt,
+		}
+		if postErr != nil {
+			return receipt, p.recordUnposted(paymentID, tx, postErr)
+		}
+		return receipt, nil
+	})
+}
+
+// Refund mengembalikan sebagian atau seluruh pembayaran dan mencatat jurnal
+// kebalikannya: debit refunds, kredit clearing:<provider>.
+func (p *Processor) Refund(ctx context.Context, req RefundPaymentRequest) (Receipt, error) {
+	if req.IdempotencyKey == "" {
+		return Receipt{}, errors.New("payment: IdempotencyKey is required")
+	}
+	if !req.Amount.IsPositive() {
+		return Receipt{}, fmt.Errorf("payment: amount must be positive, got %s", req.Amount)
+	}
+
+	fingerprint := fmt.Sprintf("refund|%s|%s", req.PaymentID, req.Amount)
+	return p.idempotent(ctx, req.IdempotencyKey, fingerprint, func() (Receipt, error) {
+		record, err := p.reserveRefund(req.PaymentID, req.Amount)
+		if err != nil {
+			return Receipt{}, err
+		}
+
+		ref, err := p.providers[record.provider].Refund(ctx, RefundRequest{
+			IdempotencyKey: req.IdempotencyKey,
+			ChargeRef:      record.ref,
+			Amount:         req.Amount,
+		})
+		if err != nil {
+			p.releaseRefund(record, req.Amount)
+			return Receipt{}, err
+		}
+
+		// mulai dari sini refund sudah terjadi di provider, jadi reservasi
+		// tidak dilepas walaupun jurnal gagal dicatat
+		refundID := p.nextID("ref")
+		tx := Transaction{
+			Kind:        TxRefund,
+			PaymentID:   req.PaymentID,
+			Provider:    record.provider,
+			ProviderRef: ref,
+			Entries: []Entry{
+				{Account: AccountRefunds, Amount: req.Amount},
+				{Account: ClearingAccount(record.provider), Amount: req.Amount.Neg()},
+			},
+		}
+		posted, postErr := p.ledger.Post(tx)
+
+		receipt := Receipt{
+			ID:            refundID,
+			Kind:          TxRefund,
+			PaymentID:     req.PaymentID,
+			Provider:      record.provider,
+			ProviderRef:   ref,
+			Amount:        req.Amount,
+			TransactionID: posted.ID,
+			CreatedAt:     posted.PostedAt,
+		}
+		if postErr != nil {
+			return receipt, p.recordUnposted(refundID, tx, postErr)
+		}
+		return receipt, nil
+	})
+}
+
+func (p *Processor) reserveRefund(paymentID string, amount Money) (*paymentRecord, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record, ok := p.payments[paymentID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownPayment, paymentID)
+	}
+
+	refunded, err := record.refunded.Add(amount)
+	if err != nil {
+		return nil, err
+	}
+	if refunded.Minor > record.amount.Minor {
+		remaining, _ := record.amount.Sub(record.refunded)
+		return nil, fmt.Errorf("%w: %s left on %s", ErrRefundExceedsPayment, remaining, paymentID)
+	}
+	record.refunded = refunded
+
+	return record, nil
+}
+
+// recordUnposted menyimpan transaksi yang gagal diposting untuk laporan
+// rekonsiliasi dan mengembalikan error final untuk receipt id.
+func (p *Processor) recordUnposted(id string, tx Transaction, err error) error {
+	p.mu.Lock()
+	p.unposted = append(p.unposted, Unposted{Transaction: tx, Err: err})
+	p.mu.Unlock()
+
+	return fmt.Errorf("%w: %s: %v", ErrLedgerPost, id, err)
+}
+
+// Unposted mengembalikan transaksi yang berhasil di provider tapi belum
+// tercatat di ledger.
+func (p *Processor) Unposted() []Unposted {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Unposted(nil), p.unposted...)
+}
+
+func (p *Processor) releaseRefund(record *paymentRecord, amount Money) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record.refunded, _ = record.refunded.Sub(amount)
+}
+
+// idempotent menjalankan fn sekali per key. Request yang bersamaan dengan
+// key sama menunggu hasil request pertama. Hasil sukses dan ErrDeclined
+// disimpan; error sementara (provider down, timeout, ctx dibatalkan) tidak,
+// supaya klien bisa mencoba lagi dengan key yang sama.
+func (p *Processor) idempotent(ctx context.Context, key, fingerprint string, fn func() (Receipt, error)) (Receipt, error) {
+	for {
+		p.mu.Lock()
+		entry, exists := p.keys[key]
+		if !exists {
+			entry = &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+			p.keys[key] = entry
+		}
+		p.mu.Unlock()
+
+		if exists {
+			if entry.fingerprint != fingerprint {
+				return Receipt{}, fmt.Errorf("%w: %s", ErrIdempotencyConflict, key)
+			}
+			select {
+			case <-entry.done:
+			case <-ctx.Done():
+				return Receipt{}, ctx.Err()
+			}
+			if entry.err != nil && !isFinal(entry.err) {
+				// request pertama gagal sementara dan key sudah dilepas
+				continue
+			}
+			return entry.receipt, entry.err
+		}
+
+		return p.runOnce(key, entry, fn)
+	}
+}
+
+// runOnce menjalankan fn untuk entry yang baru didaftarkan. Kalau fn panic,
+// key tetap dilepas dan done tetap ditutup supaya request yang menunggu
+// tidak menggantung; mereka mencoba lagi seperti setelah error sementara,
+// dan panic diteruskan ke caller.
+func (p *Processor) runOnce(key string, entry *idempotencyEntry, fn func() (Receipt, error)) (Receipt, error) {
+	finished := false
+	defer func() {
+		if !finished {
+			entry.err = fmt.Errorf("payment: request %s panicked", key)
+		}
+		if entry.err != nil && !isFinal(entry.err) {
+			p.mu.Lock()
+			delete(p.keys, key)
+			p.mu.Unlock()
+		}
+		close(entry.done)
+	}()
+
+	entry.receipt, entry.err = fn()
+	finished = true
+
+	return entry.receipt, entry.err
+}
+
+func isFinal(err error) bool {
+	return errors.Is(err, ErrDeclined) ||
+		errors.Is(err, ErrUnknownPayment) ||
+		errors.Is(err, ErrRefundExceedsPayment) ||
+		errors.Is(err, ErrLedgerPost)
+}
+
+func (p *Processor) nextID(prefix string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	return fmt.Sprintf("%s_%06d", prefix, p.seq)
+}
@@ -0,0 +1,349 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMoney(t *testing.T) {
+	a := MustParse("0.10", "USD")
+	b := MustParse("0.2", "USD")
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "USD 0.30" {
+		t.Fatalf("0.1 + 0.2 = %s %v", sum, err)
+	}
+
+	cases := map[string]string{
+		"-5":      "USD -5.00",
+		"1234.5":  "USD 1234.50",
+		"0.07":    "USD 0.07",
+		" 42.00 ": "USD 42.00",
+	}
+	for in, want := range cases {
+		if got := MustParse(in, "USD").String(); got != want {
+			t.Errorf("%q: got %s want %s", in, got, want)
+		}
+	}
+	if got := MustParse("1500", "JPY").String(); got != "JPY 1500" {
+		t.Errorf("JPY has no minor unit: %s", got)
+	}
+
+	for _, bad := range []string{"", "1.234", "1.", ".5", "abc", "1e3", "--1", "+-1"} {
+		if _, err := ParseMoney(bad, "USD"); err == nil {
+			t.Errorf("%q must be rejected", bad)
+		}
+	}
+	if _, err := ParseMoney("1", "XYZ"); err == nil {
+		t.Error("unknown currency must be rejected")
+	}
+	if _, err := a.Add(MustParse("1", "IDR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("currency mismatch: %v", err)
+	}
+
+	data, _ := json.Marshal(MustParse("19.99", "EUR"))
+	if string(data) != `{"amount":"19.99","currency":"EUR"}` {
+		t.Fatalf("json %s", data)
+	}
+	var back Money
+	if err := json.Unmarshal(data, &back); err != nil || back != MustParse("19.99", "EUR") {
+		t.Fatalf("json roundtrip %v %v", back, err)
+	}
+}
+
+func TestLedgerRejectsUnbalanced(t *testing.T) {
+	l := NewLedger()
+
+	_, err := l.Post(Transaction{Entries: []Entry{
+		{Account: "a", Amount: MustParse("10", "USD")},
+		{Account: "b", Amount: MustParse("-9.99", "USD")},
+	}})
+	if !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("expected ErrUnbalanced, got %v", err)
+	}
+
+	_, err = l.Post(Transaction{Entries: []Entry{
+		{Account: "a", Amount: MustParse("10", "USD")},
+		{Account: "b", Amount: MustParse("-10", "EUR")},
+	}})
+	if !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("balance must hold per currency, got %v", err)
+	}
+	if len(l.Transactions()) != 0 {
+		t.Fatal("rejected transactions must not be posted")
+	}
+}
+
+func newTestProcessor() (*Processor, *Ledger, *FakeProvider, *FakeProvider) {
+	ledger := NewLedger()
+	paypal, stripe := NewFakeProvider("paypal"), NewFakeProvider("stripe")
+	return NewProcessor(ledger, paypal, stripe), ledger, paypal, stripe
+}
+
+func TestPayAndRefundPostLedgerEntries(t *testing.T) {
+	p, ledger, _, _ := newTestProcessor()
+	ctx := context.Background()
+
+	receipt, err := p.Pay(ctx, PaymentRequest{IdempotencyKey: "order-1", Provider: "stripe", Amount: MustParse("150000", "IDR"), Customer: "bisma"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Pay(ctx, PaymentRequest{IdempotencyKey: "order-2", Provider: "paypal", Amount: MustParse("25.50", "USD")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.Refund(ctx, RefundPaymentRequest{IdempotencyKey: "rf-1", PaymentID: receipt.PaymentID, Amount: MustParse("50000", "IDR")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Refund(ctx, RefundPaymentRequest{IdempotencyKey: "rf-2", PaymentID: receipt.PaymentID, Amount: MustParse("100000.01", "IDR")})
+	if !errors.Is(err, ErrRefundExceedsPayment) {
+		t.Fatalf("over-refund must fail, got %v", err)
+	}
+
+	balances := map[string]string{
+		ClearingAccount("stripe"): "IDR 100000.00",
+		AccountRevenue:            "IDR -150000.00",
+		AccountRefunds:            "IDR 50000.00",
+	}
+	for account, want := range balances {
+		if got := ledger.Balance(account, "IDR").String(); got != want {
+			t.Errorf("%s: got %s want %s", account, got, want)
+		}
+	}
+	if got := ledger.Balance(ClearingAccount("paypal"), "USD").String(); got != "USD 25.50" {
+		t.Errorf("paypal clearing: %s", got)
+	}
+	for currency, sum := range ledger.TrialBalance() {
+		if !sum.IsZero() {
+			t.Errorf("trial balance %s is %s", currency, sum)
+		}
+	}
+
+	if _, err := p.Pay(ctx, PaymentRequest{IdempotencyKey: "x", Provider: "bitcoin", Amount: MustParse("1", "USD")}); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("unknown provider: %v", err)
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	p, ledger, _, stripe := newTestProcessor()
+	ctx := context.Background()
+	req := PaymentRequest{IdempotencyKey: "order-9", Provider: "stripe", Amount: MustParse("10", "USD"), Customer: "bisma"}
+
+	first, err := p.Pay(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := p.Pay(ctx, req)
+	if err != nil || again != first {
+		t.Fatalf("replay must return the same receipt: %+v %v", again, err)
+	}
+	if stripe.Calls() != 1 || len(ledger.Transactions()) != 1 {
+		t.Fatalf("replay must not charge again: %d calls, %d txs", stripe.Calls(), len(ledger.Transactions()))
+	}
+
+	changed := req
+	changed.Amount = MustParse("11", "USD")
+	if _, err := p.Pay(ctx, changed); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Fatalf("different request with same key: %v", err)
+	}
+}
+
+func TestIdempotencyConcurrent(t *testing.T) {
+	p, ledger, _, stripe := newTestProcessor()
+	req := PaymentRequest{IdempotencyKey: "double-click", Provider: "stripe", Amount: MustParse("99.99", "USD")}
+
+	var wg sync.WaitGroup
+	receipts := make([]Receipt, 20)
+	for i := range receipts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			receipts[i], _ = p.Pay(context.Background(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, r := range receipts {
+		if r != receipts[0] || r.ID == "" {
+			t.Fatalf("all callers must get the same receipt: %+v vs %+v", r, receipts[0])
+		}
+	}
+	if stripe.Calls() != 1 || len(ledger.Transactions()) != 1 {
+		t.Fatalf("expected one charge, got %d calls %d txs", stripe.Calls(), len(ledger.Transactions()))
+	}
+}
+
+func TestIdempotencyPanicReleasesKey(t *testing.T) {
+	p, _, _, _ := newTestProcessor()
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		p.idempotent(ctx, "k", "f", func() (Receipt, error) {
+			close(started)
+			<-release
+			panic("provider SDK bug")
+		})
+	}()
+	<-started
+
+	// request kedua dengan key sama menunggu request pertama, lalu mencoba
+	// sendiri setelah request pertama panic
+	waiter := make(chan error, 1)
+	go func() {
+		receipt, err := p.idempotent(ctx, "k", "f", func() (Receipt, error) { return Receipt{ID: "ok"}, nil })
+		if err == nil && receipt.ID != "ok" {
+			err = fmt.Errorf("unexpected receipt %+v", receipt)
+		}
+		waiter <- err
+	}()
+	close(release)
+
+	if r := <-panicked; r != "provider SDK bug" {
+		t.Fatalf("panic must reach the caller, got %v", r)
+	}
+	select {
+	case err := <-waiter:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter hangs after the first request panicked")
+	}
+}
+
+func TestProviderFailureModes(t *testing.T) {
+	p, ledger, paypal, _ := newTestProcessor()
+	ctx := context.Background()
+
+	// decline bersifat final: retry dengan key yang sama tetap ditolak
+	paypal.FailNext(FailDecline)
+	declined := PaymentRequest{IdempotencyKey: "d-1", Provider: "paypal", Amount: MustParse("5", "USD")}
+	if _, err := p.Pay(ctx, declined); !errors.Is(err, ErrDeclined) {
+		t.Fatalf("expected decline, got %v", err)
+	}
+	if _, err := p.Pay(ctx, declined); !errors.Is(err, ErrDeclined) || paypal.Calls() != 1 {
+		t.Fatalf("decline must be replayed without calling provider: %v, %d calls", err, paypal.Calls())
+	}
+
+	// provider down: key dilepas, retry berhasil
+	paypal.FailNext(FailUnavailable)
+	flaky := PaymentRequest{IdempotencyKey: "u-1", Provider: "paypal", Amount: MustParse("5", "USD")}
+	if _, err := p.Pay(ctx, flaky); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("expected unavailable, got %v", err)
+	}
+	if _, err := p.Pay(ctx, flaky); err != nil {
+		t.Fatalf("retry after outage must succeed: %v", err)
+	}
+
+	// timeout setelah uang terpotong: retry dengan key sama tidak menagih dua
+	// kali karena provider juga idempotent
+	paypal.FailNext(FailTimeoutAfterCharge)
+	lost := PaymentRequest{IdempotencyKey: "t-1", Provider: "paypal", Amount: MustParse("7", "USD")}
+	if _, err := p.Pay(ctx, lost); !errors.Is(err, ErrProviderTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if _, err := p.Pay(ctx, lost); err != nil {
+		t.Fatal(err)
+	}
+
+	settlements, _ := paypal.Settlements(ctx)
+	if len(settlements) != 2 || len(ledger.Transactions()) != 2 {
+		t.Fatalf("expected 2 charges on both sides, provider %d ledger %d", len(settlements), len(ledger.Transactions()))
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	p, _, paypal, _ := newTestProcessor()
+	ctx := context.Background()
+
+	p.Pay(ctx, PaymentRequest{IdempotencyKey: "ok-1", Provider: "paypal", Amount: MustParse("10", "USD")})
+	paid, _ := p.Pay(ctx, PaymentRequest{IdempotencyKey: "ok-2", Provider: "paypal", Amount: MustParse("20", "USD")})
+	p.Refund(ctx, RefundPaymentRequest{IdempotencyKey: "rf", PaymentID: paid.PaymentID, Amount: MustParse("5", "USD")})
+
+	report, err := Reconcile(ctx, p.ledger, paypal)
+	if err != nil || !report.OK() || report.Matched != 3 {
+		t.Fatalf("clean books: %+v %v", report, err)
+	}
+
+	// klien menyerah setelah timeout dan tidak pernah retry
+	paypal.FailNext(FailTimeoutAfterCharge)
+	p.Pay(ctx, PaymentRequest{IdempotencyKey: "lost", Provider: "paypal", Amount: MustParse("30", "USD")})
+	// chargeback langsung di provider
+	paypal.AddSettlement(Settlement{Ref: "paypal_chargeback_1", Kind: TxRefund, ChargeRef: paid.ProviderRef, Amount: MustParse("15", "USD")})
+
+	report, _ = Reconcile(ctx, p.ledger, paypal)
+	if report.OK() || report.Matched != 3 || len(report.MissingInLedger) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	var out strings.Builder
+	report.WriteTo(&out)
+	for _, want := range []string{"missing in ledger", "paypal_charge_0004", "USD 30.00", "paypal_chargeback_1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report must mention %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLedgerFailureAfterProviderIsFinal(t *testing.T) {
+	p, ledger, paypal, _ := newTestProcessor()
+	ctx := context.Background()
+
+	paid, err := p.Pay(ctx, PaymentRequest{IdempotencyKey: "pay", Provider: "paypal", Amount: MustParse("20", "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger.failPost = func(tx Transaction) error { return errors.New("disk full") }
+
+	// refund sudah terjadi di provider: retry tidak boleh me-refund lagi
+	// dan nominalnya tetap terhitung terhadap sisa pembayaran
+	refund := RefundPaymentRequest{IdempotencyKey: "rf", PaymentID: paid.PaymentID, Amount: MustParse("15", "USD")}
+	first, err := p.Refund(ctx, refund)
+	if !errors.Is(err, ErrLedgerPost) || first.ProviderRef == "" {
+		t.Fatalf("expected final ledger error with provider ref, got %+v %v", first, err)
+	}
+	again, err := p.Refund(ctx, refund)
+	if !errors.Is(err, ErrLedgerPost) || again != first || paypal.Calls() != 2 {
+		t.Fatalf("retry must replay the result without calling provider: %+v %v, %d calls", again, err, paypal.Calls())
+	}
+
+	// charge yang gagal diposting tetap tercatat sebagai pembayaran
+	lost, err := p.Pay(ctx, PaymentRequest{IdempotencyKey: "lost", Provider: "paypal", Amount: MustParse("30", "USD")})
+	if !errors.Is(err, ErrLedgerPost) || lost.PaymentID == "" {
+		t.Fatalf("expected final ledger error with payment id, got %+v %v", lost, err)
+	}
+
+	ledger.failPost = nil
+	_, err = p.Refund(ctx, RefundPaymentRequest{IdempotencyKey: "rf-2", PaymentID: paid.PaymentID, Amount: MustParse("10", "USD")})
+	if !errors.Is(err, ErrRefundExceedsPayment) {
+		t.Fatalf("unposted refund must still count against the payment, got %v", err)
+	}
+	if _, err := p.Refund(ctx, RefundPaymentRequest{IdempotencyKey: "rf-3", PaymentID: lost.PaymentID, Amount: MustParse("5", "USD")}); err != nil {
+		t.Fatalf("unposted charge must stay refundable: %v", err)
+	}
+
+	report, err := p.Reconcile(ctx, paypal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || len(report.Unposted) != 2 || len(report.MissingInLedger) != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	var out strings.Builder
+	report.WriteTo(&out)
+	for _, want := range []string{"ledger post failed", first.ProviderRef, lost.ProviderRef, "disk full"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report must mention %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDeclined adalah penolakan final dari provider (kartu ditolak, saldo
+	// kurang). Hasil ini disimpan untuk idempotency key yang sama.
+	ErrDeclined = errors.New("payment: declined by provider")
+	// ErrProviderUnavailable berarti provider tidak memproses request sama
+	// sekali, jadi aman dicoba lagi dengan idempotency key yang sama.
+	ErrProviderUnavailable = errors.New("payment: provider unavailable")
+	// ErrProviderTimeout berarti status pembayaran tidak diketahui: bisa
+	// jadi uang sudah terpotong. Coba lagi dengan key yang sama, dan
+	// Reconcile akan menemukan charge yang tidak tercatat di ledger.
+	ErrProviderTimeout = errors.New("payment: provider timed out")
+)
+
+type ChargeRequest struct {
+	IdempotencyKey string
+	Amount         Money
+	Customer       string
+	Description    string
+}
+
+type RefundRequest struct {
+	IdempotencyKey string
+	ChargeRef      string
+	Amount         Money
+}
+
+// Settlement adalah catatan transaksi di sisi provider, bahan rekonsiliasi.
+type Settlement struct {
+	Ref       string
+	ChargeRef string // untuk refund: charge yang di-refund
+	Kind      TxKind
+	Amount    Money
+	At        time.Time
+}
+
+// Provider adalah strategy pembayaran: PayPal, Stripe, transfer bank, dll.
+// Provider wajib meneruskan IdempotencyKey ke API-nya supaya retry tidak
+// menagih dua kali.
+type Provider interface {
+	Name() string
+	Charge(ctx context.Context, req ChargeRequest) (ref string, err error)
+	Refund(ctx context.Context, req RefundRequest) (ref string, err error)
+	Settlements(ctx context.Context) ([]Settlement, error)
+}
+
+type Failure int
+
+const (
+	FailNone Failure = iota
+	FailDecline
+	FailUnavailable
+	// FailTimeoutAfterCharge mencatat charge di provider tapi membalas
+	// timeout, seperti koneksi yang putus setelah uang terpotong.
+	FailTimeoutAfterCharge
+)
+
+// FakeProvider meniru provider sungguhan di memori. Kegagalan diatur
+// dengan FailNext dan dipakai berurutan, satu per request.
+type FakeProvider struct {
+	name string
+
+	mu          sync.Mutex
+	failures    []Failure
+	byKey       map[string]string
+	settlements []Settlement
+	seq         int
+	calls       int
+	now         func() time.Time
+}
+
+func NewFakeProvider(name string) *FakeProvider {
+	return &FakeProvider{name: name, byKey: make(map[string]string), now: time.Now}
+}
+
+func (f *FakeProvider) Name() string { return f.name }
+
+func (f *FakeProvider) FailNext(failures ...Failure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, failures...)
+}
+
+// Calls adalah jumlah request Charge dan Refund yang diterima.
+func (f *FakeProvider) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *FakeProvider) Charge(ctx context.Context, req ChargeRequest) (string, error) {
+	return f.record(ctx, req.IdempotencyKey, Settlement{Kind: TxCharge, Amount: req.Amount})
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, req RefundRequest) (string, error) {
+	f.mu.Lock()
+	found := false
+	for _, s := range f.settlements {
+		if s.Kind == TxCharge && s.Ref == req.ChargeRef {
+			found = true
+		}
+	}
+	f.mu.Unlock()
+	if !found {
+		return "", fmt.Errorf("%w: unknown charge %s", ErrDeclined, req.ChargeRef)
+	}
+
+	return f.record(ctx, req.IdempotencyKey, Settlement{Kind: TxRefund, ChargeRef: req.ChargeRef, Amount: req.Amount})
+}
+
+func (f *FakeProvider) record(ctx context.Context, key string, s Settlement) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if ref, ok := f.byKey[key]; ok && key != "" {
+		return ref, nil
+	}
+
+	failure := FailNone
+	if len(f.failures) > 0 {
+		failure, f.failures = f.failures[0], f.failures[1:]
+	}
+	switch failure {
+	case FailDecline:
+		return "", fmt.Errorf("%w: %s %s", ErrDeclined, f.name, "insufficient_funds")
+	case FailUnavailable:
+		return "", fmt.Errorf("%w: %s returned 503", ErrProviderUnavailable, f.name)
+	}
+
+	f.seq++
+	s.Ref = fmt.Sprintf("%s_%s_%04d", f.name, s.Kind, f.seq)
+	s.At = f.now()
+	f.settlements = append(f.settlements, s)
+	if key != "" {
+		f.byKey[key] = s.Ref
+	}
+
+	if failure == FailTimeoutAfterCharge {
+		return "", fmt.Errorf("%w: %s", ErrProviderTimeout, f.name)
+	}
+	return s.Ref, nil
+}
+
+func (f *FakeProvider) Settlements(context.Context) ([]Settlement, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Settlement(nil), f.settlements...), nil
+}
+
+// AddSettlement memasukkan catatan langsung ke sisi provider, misalnya
+// untuk mensimulasikan chargeback yang tidak lewat aplikasi kita.
+func (f *FakeProvider) AddSettlement(s Settlement) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s.At.IsZero() {
+		s.At = f.now()
+	}
+	f.settlements = append(f.settlements, s)
+}
@@ -0,0 +1,145 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Mismatch adalah transaksi yang ada di kedua sisi tapi nominalnya beda.
+type Mismatch struct {
+	Settlement  Settlement
+	Transaction Transaction
+}
+
+// ReconciliationReport membandingkan catatan provider dengan ledger.
+type ReconciliationReport struct {
+	Provider string
+	Matched  int
+	// MissingInLedger: uang bergerak di provider tapi tidak tercatat,
+	// biasanya karena timeout setelah charge atau chargeback.
+	MissingInLedger []Settlement
+	// MissingAtProvider: tercatat di ledger tapi provider tidak tahu.
+	MissingAtProvider []Transaction
+	Mismatched        []Mismatch
+	// Unposted: provider berhasil tapi Processor gagal memposting jurnalnya.
+	// Settlement-nya tidak diulang di MissingInLedger.
+	Unposted []Unposted
+}
+
+func (r ReconciliationReport) OK() bool {
+	return len(r.MissingInLedger) == 0 && len(r.MissingAtProvider) == 0 &&
+		len(r.Mismatched) == 0 && len(r.Unposted) == 0
+}
+
+// Reconcile mencocokkan settlement provider dengan transaksi ledger lewat
+// (jenis, provider ref).
+func Reconcile(ctx context.Context, ledger *Ledger, provider Provider) (ReconciliationReport, error) {
+	settlements, err := provider.Settlements(ctx)
+	if err != nil {
+		return ReconciliationReport{}, fmt.Errorf("payment: fetch settlements from %s: %w", provider.Name(), err)
+	}
+
+	type key struct {
+		kind TxKind
+		ref  string
+	}
+	booked := make(map[key]Transaction)
+	for _, tx := range ledger.Transactions() {
+		if tx.Provider == provider.Name() {
+			booked[key{tx.Kind, tx.ProviderRef}] = tx
+		}
+	}
+
+	report := ReconciliationReport{Provider: provider.Name()}
+	for _, s := range settlements {
+		k := key{s.Kind, s.Ref}
+		tx, ok := booked[k]
+		if !ok {
+			report.MissingInLedger = append(report.MissingInLedger, s)
+			continue
+		}
+		delete(booked, k)
+
+		if tx.Amount() != s.Amount {
+			report.Mismatched = append(report.Mismatched, Mismatch{Settlement: s, Transaction: tx})
+			continue
+		}
+		report.Matched++
+	}
+
+	for _, tx := range booked {
+		report.MissingAtProvider = append(report.MissingAtProvider, tx)
+	}
+	sort.Slice(report.MissingAtProvider, func(i, j int) bool {
+		return report.MissingAtProvider[i].ID < report.MissingAtProvider[j].ID
+	})
+
+	return report, nil
+}
+
+// Reconcile sama dengan Reconcile di level package, ditambah transaksi
+// yang gagal diposting oleh processor ini.
+func (p *Processor) Reconcile(ctx context.Context, provider Provider) (ReconciliationReport, error) {
+	report, err := Reconcile(ctx, p.ledger, provider)
+	if err != nil {
+		return report, err
+	}
+
+	unposted := make(map[[2]string]bool)
+	for _, u := range p.Unposted() {
+		if u.Transaction.Provider == provider.Name() {
+			report.Unposted = append(report.Unposted, u)
+			unposted[[2]string{string(u.Transaction.Kind), u.Transaction.ProviderRef}] = true
+		}
+	}
+
+	missing := report.MissingInLedger[:0]
+	for _, s := range report.MissingInLedger {
+		if !unposted[[2]string{string(s.Kind), s.Ref}] {
+			missing = append(missing, s)
+		}
+	}
+	report.MissingInLedger = missing
+
+	return report, nil
+}
+
+// WriteTo menulis laporan dalam bentuk tabel untuk dibaca tim finance.
+func (r ReconciliationReport) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "provider\t%s\n", r.Provider)
+	fmt.Fprintf(tw, "matched\t%d\n", r.Matched)
+	for _, s := range r.MissingInLedger {
+		fmt.Fprintf(tw, "missing in ledger\t%s\t%s\t%s\n", s.Kind, s.Ref, s.Amount)
+	}
+	for _, tx := range r.MissingAtProvider {
+		fmt.Fprintf(tw, "missing at provider\t%s\t%s\t%s\n", tx.Kind, tx.ProviderRef, tx.Amount())
+	}
+	for _, m := range r.Mismatched {
+		fmt.Fprintf(tw, "amount mismatch\t%s\t%s\tprovider %s, ledger %s\n",
+			m.Settlement.Kind, m.Settlement.Ref, m.Settlement.Amount, m.Transaction.Amount())
+	}
+	for _, u := range r.Unposted {
+		fmt.Fprintf(tw, "ledger post failed\t%s\t%s\t%s\t%v\n",
+			u.Transaction.Kind, u.Transaction.ProviderRef, u.Transaction.Amount(), u.Err)
+	}
+	err := tw.Flush()
+
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -1,8 +1,11 @@
 package designpattern
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"go-journey/design-pattern/payment"
 )
 
 /*
@@ -14,31 +17,28 @@ Payment gateways like PayPal, Stripe, etc.
 
 */
 
-type PaymentStrategy interface {
-	Pay(amount float64)
-}
-
-type PayPal struct{}
-
-func (e *PayPal) Pay(amount float64) {
-	fmt.Println("bayar by paypal dengan nominal ", amount)
-}
-
-type Stripe struct{}
-
-func (e *Stripe) Pay(amount float64) {
-	fmt.Println("bayar by string dengan nominal ", amount)
-}
-
-type PaymentContext struct {
-	Strategy PaymentStrategy
-}
-
-func (pc *PaymentContext) Execute(amount float64) {
-	pc.Strategy.Pay(amount)
-}
+// Dulu PaymentStrategy.Pay(amount float64) hanya mencetak ke layar. Sekarang
+// strategy-nya adalah payment.Provider dan PaymentContext diganti
+// payment.Processor yang memilih provider saat runtime, memakai Money
+// (bukan float64), idempotency key, dan mencatat ledger.
 
 func TestStrategyPattern(t *testing.T) {
-	ctx := PaymentContext{Strategy: &PayPal{}}
-	ctx.Execute(23)
+	ledger := payment.NewLedger()
+	processor := payment.NewProcessor(ledger, payment.NewFakeProvider("paypal"), payment.NewFakeProvider("stripe"))
+
+	for i, provider := range []string{"paypal", "stripe"} {
+		receipt, err := processor.Pay(context.Background(), payment.PaymentRequest{
+			IdempotencyKey: fmt.Sprint("order-", i),
+			Provider:       provider,
+			Amount:         payment.MustParse("23", "USD"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("bayar by", receipt.Provider, "dengan nominal", receipt.Amount, "ref", receipt.ProviderRef)
+	}
+
+	if got := ledger.Balance(payment.AccountRevenue, "USD").String(); got != "USD -46.00" {
+		t.Fatalf("revenue %s", got)
+	}
 }
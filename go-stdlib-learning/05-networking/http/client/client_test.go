@@ -0,0 +1,405 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient mengganti sleep supaya retry tidak benar-benar menunggu, dan
+// mencatat jeda yang diminta.
+func newTestClient(cfg Config) (*Client, *[]time.Duration) {
+	c := New(cfg)
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+
+	return c, &waits
+}
+
+func flaky(failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user{Name: "bisma", Echo: string(body)})
+	}))
+
+	return srv, &calls
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	srv, calls := flaky(2, http.StatusServiceUnavailable, "")
+	defer srv.Close()
+
+	var attempts []int
+	c, waits := newTestClient(Config{
+		InitialBackoff: 10 * time.Millisecond,
+		Hooks: Hooks{OnRequest: func(req *http.Request, attempt int) {
+			attempts = append(attempts, attempt)
+		}},
+	})
+
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || *calls != 3 || len(*waits) != 2 {
+		t.Fatalf("status %d after %d calls, waits %v", resp.StatusCode, *calls, *waits)
+	}
+	if len(attempts) != 3 || attempts[2] != 3 {
+		t.Fatalf("hooks must see every attempt, got %v", attempts)
+	}
+	for i, w := range *waits {
+		if max := 10 * time.Millisecond << i; w < 0 || w > max {
+			t.Errorf("wait %d = %v, want <= %v", i, w, max)
+		}
+	}
+}
+
+func TestRetryGivesUpAndReturnsLastResponse(t *testing.T) {
+	srv, calls := flaky(10, http.StatusBadGateway, "")
+	defer srv.Close()
+
+	c, _ := newTestClient(Config{MaxRetries: 1})
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway || *calls != 2 {
+		t.Fatalf("expected last 502 after 2 calls, got %d after %d", resp.StatusCode, *calls)
+	}
+}
+
+func TestNoRetryForPostWithoutIdempotencyKey(t *testing.T) {
+	srv, calls := flaky(1, http.StatusServiceUnavailable, "")
+	defer srv.Close()
+
+	c, _ := newTestClient(Config{})
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("a"))
+	resp, _ := c.Do(req)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || *calls != 1 {
+		t.Fatalf("POST must not be retried: %d after %d calls", resp.StatusCode, *calls)
+	}
+
+	*calls = 0
+	req, _ = http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	req.Header.Set("Idempotency-Key", "order-1")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if *calls != 2 || !strings.Contains(string(body), `"echo":"payload"`) {
+		t.Fatalf("body must be replayed on retry: %d calls, %s", *calls, body)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv, _ := flaky(1, http.StatusTooManyRequests, "3")
+	defer srv.Close()
+
+	c, waits := newTestClient(Config{})
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(*waits) != 1 || (*waits)[0] != 3*time.Second {
+		t.Fatalf("expected Retry-After wait of 3s, got %v", *waits)
+	}
+
+	srv2, calls := flaky(1, http.StatusTooManyRequests, "120")
+	defer srv2.Close()
+
+	resp, _ = c.Get(context.Background(), srv2.URL)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || *calls != 1 {
+		t.Fatal("Retry-After above MaxRetryAfter must not be retried")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]time.Duration{
+		"5":                             5 * time.Second,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+	}
+	for in, want := range cases {
+		if got, ok := parseRetryAfter(in, now); !ok || got != want {
+			t.Errorf("%q: got %v %v, want %v", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "soon"} {
+		if _, ok := parseRetryAfter(in, now); ok {
+			t.Errorf("%q must be rejected", in)
+		}
+	}
+}
+
+func TestContextCancelStopsRetry(t *testing.T) {
+	srv, calls := flaky(10, http.StatusServiceUnavailable, "")
+	defer srv.Close()
+
+	c := New(Config{InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Get(ctx, srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) || *calls != 1 {
+		t.Fatalf("expected deadline during backoff, got %v after %d calls", err, *calls)
+	}
+}
+
+type user struct {
+	Name string `json:"name"`
+	Echo string `json:"echo"`
+}
+
+func TestJSONHelpers(t *testing.T) {
+	srv, _ := flaky(0, 0, "")
+	defer srv.Close()
+
+	c := New(Config{})
+
+	u, err := GetJSON[user](context.Background(), c, srv.URL)
+	if err != nil || u.Name != "bisma" {
+		t.Fatalf("GetJSON: %+v %v", u, err)
+	}
+
+	u, err = PostJSON[user](context.Background(), c, srv.URL, map[string]int{"n": 1})
+	if err != nil || u.Echo != `{"n":1}` {
+		t.Fatalf("PostJSON: %+v %v", u, err)
+	}
+}
+
+func TestJSONErrorBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		io.WriteString(w, `{"title":"Validation failed","status":422}`)
+	}))
+	defer srv.Close()
+
+	_, err := GetJSON[user](context.Background(), New(Config{}), srv.URL+"/users")
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(err.Error(), "Validation failed") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var problem struct {
+		Title string `json:"title"`
+	}
+	if err := httpErr.DecodeBody(&problem); err != nil || problem.Title != "Validation failed" {
+		t.Fatalf("decode body: %+v %v", problem, err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user{Name: r.URL.Query().Get("name"), Echo: string(body)})
+	}))
+	fixture := filepath.Join(t.TempDir(), "testdata", "users.json")
+
+	rec, err := NewRecorder(fixture, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(Config{Transport: rec})
+
+	first, err := GetJSON[user](context.Background(), c, srv.URL+"/users?name=bisma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PostJSON[user](context.Background(), c, srv.URL+"/users", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	replay, err := NewRecorder(fixture, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = New(Config{Transport: replay, MaxRetries: -1})
+
+	got, err := GetJSON[user](context.Background(), c, srv.URL+"/users?name=bisma")
+	if err != nil || got != first {
+		t.Fatalf("replay: %+v %v, want %+v", got, err, first)
+	}
+
+	resp, err := c.Get(context.Background(), srv.URL+"/users?name=bisma")
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("each interaction is used once, got %v %v", resp, err)
+	}
+	if _, err := PostJSON[user](context.Background(), c, srv.URL+"/users", "other body"); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("body must be part of the match, got %v", err)
+	}
+
+	if len(replay.interactions) != 2 || replay.interactions[0].Response.Header.Get("Set-Cookie") != "REDACTED" {
+		t.Fatalf("sensitive headers must be redacted: %+v", replay.interactions)
+	}
+}
+
+func TestRecorderRedactsHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Auth-Token", "response-token")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	fixture := filepath.Join(t.TempDir(), "secrets.json")
+
+	rec, err := NewRecorder(fixture, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Redact("x-vendor-secret")
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("X-Api-Key", "api-key-value")
+	req.Header.Set("X-Vendor-Secret", "vendor-secret-value")
+	req.Header.Set("Accept", "application/json")
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(fixture)
+	for _, secret := range []string{"api-key-value", "vendor-secret-value", "response-token"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("fixture must not contain %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "application/json") {
+		t.Errorf("other headers must be kept:\n%s", data)
+	}
+}
+
+func TestRecorderRedactsQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	fixture := filepath.Join(t.TempDir(), "query.json")
+
+	rec, err := NewRecorder(fixture, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.RedactQuery("vendor_secret")
+	c := New(Config{Transport: rec})
+
+	resp, err := c.Get(context.Background(), srv.URL+"/users?name=bisma&API_KEY=api-key-value&vendor_secret=vendor-secret-value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(fixture)
+	for _, secret := range []string{"api-key-value", "vendor-secret-value"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("fixture must not contain %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), `/users?name=bisma\u0026API_KEY=REDACTED\u0026vendor_secret=REDACTED`) {
+		t.Errorf("other parameters and their order must be kept:\n%s", data)
+	}
+
+	// replay dengan key lain tetap cocok, parameter biasa tetap harus sama
+	replay, err := NewRecorder(fixture, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.RedactQuery("vendor_secret")
+	c = New(Config{Transport: replay, MaxRetries: -1})
+	if _, err := c.Get(context.Background(), srv.URL+"/users?name=other&API_KEY=x&vendor_secret=y"); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("non-redacted parameters must be part of the match, got %v", err)
+	}
+	resp, err = c.Get(context.Background(), srv.URL+"/users?name=bisma&API_KEY=rotated&vendor_secret=rotated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestLogHooksRedactQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := New(Config{Hooks: LogHooks(logger), MaxRetries: -1})
+
+	target := srv.URL + "/users?token=secret-token&page=2"
+	resp, err := c.Get(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	srv.Close()
+	// server sudah mati, error dari http.Client juga membawa URL
+	if _, err := c.Get(context.Background(), target); err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+
+	logs := buf.String()
+	if strings.Contains(logs, "secret-token") {
+		t.Fatalf("logs must not contain the token:\n%s", logs)
+	}
+	// 4 baris log, ditambah URL di dalam error pada baris terakhir
+	if strings.Count(logs, "token=REDACTED&page=2") != 5 {
+		t.Fatalf("every log line must keep the redacted URL:\n%s", logs)
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil)
+	if err == nil || !strings.Contains(err.Error(), RecordEnv) {
+		t.Fatalf("error must explain how to record, got %v", err)
+	}
+}
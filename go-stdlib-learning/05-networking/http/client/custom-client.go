@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Timeouts Timeouts
+
+	// MaxRetries adalah jumlah percobaan ulang setelah percobaan pertama.
+	// Default 2, isi -1 untuk mematikan retry.
+	MaxRetries     int
+	InitialBackoff time.Duration // default 100ms
+	MaxBackoff     time.Duration // default 5s
+	// MaxRetryAfter membatasi Retry-After dari server. Kalau server meminta
+	// menunggu lebih lama dari ini, response langsung dikembalikan. Default 30s.
+	MaxRetryAfter time.Duration
+	// RetryStatuses default 429, 502, 503 dan 504.
+	RetryStatuses []int
+
+	// MaxConnsPerHost membatasi koneksi (aktif + idle) ke satu host.
+	// Hanya berlaku untuk transport bawaan, bukan Transport yang diisi sendiri.
+	MaxConnsPerHost int
+	Transport       http.RoundTripper
+
+	Hooks Hooks
+}
+
+// Hooks dipanggil di setiap percobaan, termasuk retry. Cocok untuk logging
+// atau metrics. Body request dan response jangan dibaca di sini.
+type Hooks struct {
+	OnRequest  func(req *http.Request, attempt int)
+	OnResponse func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+}
+
+// LogHooks mencatat setiap percobaan memakai slog. Parameter query di
+// DefaultRedactedQuery diredaksi dari URL yang dicatat, termasuk URL di
+// dalam error.
+func LogHooks(logger *slog.Logger) Hooks {
+	return Hooks{
+		OnRequest: func(req *http.Request, attempt int) {
+			logger.Debug("http request", "method", req.Method, "url", redactURL(req.URL, DefaultRedactedQuery), "attempt", attempt)
+		},
+		OnResponse: func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+			u := redactURL(req.URL, DefaultRedactedQuery)
+			if err != nil {
+				var uerr *url.Error
+				if errors.As(err, &uerr) {
+					err = &url.Error{Op: uerr.Op, URL: u, Err: uerr.Err}
+				}
+				logger.Warn("http request failed", "method", req.Method, "url", u, "error", err, "duration", elapsed)
+				return
+			}
+			logger.Info("http response", "method", req.Method, "url", u, "status", resp.StatusCode, "duration", elapsed)
+		},
+	}
+}
+
+// Client membungkus http.Client dengan retry untuk method idempotent.
+// POST dan PATCH hanya diulang kalau request membawa header Idempotency-Key.
+type Client struct {
+	http *http.Client
+	cfg  Config
+
+	retryStatus map[int]bool
+	sleep       func(ctx context.Context, d time.Duration) error
+	now         func() time.Time
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func New(cfg Config) *Client {
+	cfg.Timeouts = cfg.Timeouts.withDefaults()
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 2
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = 30 * time.Second
+	}
+	if cfg.RetryStatuses == nil {
+		cfg.RetryStatuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+
+	transport := cfg.Transport
+	if transport == nil {
+		transport = newTransport(cfg.Timeouts, cfg.MaxConnsPerHost)
+	}
+
+	c := &Client{
+		http:        &http.Client{Transport: transport, Timeout: cfg.Timeouts.Request},
+		cfg:         cfg,
+		retryStatus: make(map[int]bool),
+		sleep:       sleepContext,
+		now:         time.Now,
+		rand:        newRand(),
+	}
+	for _, code := range cfg.RetryStatuses {
+		c.retryStatus[code] = true
+	}
+
+	return c
+}
+
+// HTTPClient mengembalikan http.Client di bawahnya, tanpa retry.
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Do mengirim request dengan retry. Body request harus bisa diputar ulang
+// (req.GetBody terisi, seperti dari http.NewRequest dengan bytes.Reader atau
+// strings.Reader), kalau tidak request hanya dikirim sekali.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	retryable := c.canRetry(req)
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		if c.cfg.Hooks.OnRequest != nil {
+			c.cfg.Hooks.OnRequest(r, attempt)
+		}
+		start := time.Now()
+		resp, err := c.http.Do(r)
+		if c.cfg.Hooks.OnResponse != nil {
+			c.cfg.Hooks.OnResponse(r, resp, err, time.Since(start))
+		}
+
+		if !retryable || attempt > c.cfg.MaxRetries {
+			return resp, err
+		}
+		wait, ok := c.retryDelay(req.Context(), attempt, resp, err)
+		if !ok {
+			return resp, err
+		}
+
+		if resp != nil {
+			// body harus habis dibaca supaya koneksi bisa dipakai ulang
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := c.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+func (c *Client) canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func (c *Client) retryDelay(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		return c.randomBackoff(attempt), true
+	}
+	if !c.retryStatus[resp.StatusCode] {
+		return 0, false
+	}
+
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), c.now()); ok {
+		if d > c.cfg.MaxRetryAfter {
+			return 0, false
+		}
+		return d, true
+	}
+
+	return c.randomBackoff(attempt), true
+}
+
+func (c *Client) randomBackoff(attempt int) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.backoff(attempt)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody membatasi body error yang disimpan di HTTPError.
+const maxErrorBody = 64 << 10
+
+// HTTPError dikembalikan GetJSON dan PostJSON untuk status selain 2xx. Body
+// disimpan supaya pesan error dari server (misalnya problem+json) tidak hilang.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("client: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Body) > 0 {
+		body := e.Body
+		if len(body) > 200 {
+			body = append(body[:200:200], "..."...)
+		}
+		msg += ": " + string(bytes.TrimSpace(body))
+	}
+
+	return msg
+}
+
+// DecodeBody mendecode body error sebagai JSON, misalnya ke struct problem.
+func (e *HTTPError) DecodeBody(v any) error {
+	return json.Unmarshal(e.Body, v)
+}
+
+func GetJSON[T any](ctx context.Context, c *Client, url string) (T, error) {
+	var zero T
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return zero, err
+	}
+
+	return DoJSON[T](c, req)
+}
+
+// PostJSON mengirim body sebagai JSON. POST hanya di-retry kalau header
+// Idempotency-Key diisi, pakai DoJSON untuk mengatur header sendiri.
+func PostJSON[T any](ctx context.Context, c *Client, url string, body any) (T, error) {
+	var zero T
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return zero, fmt.Errorf("client: encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return zero, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return DoJSON[T](c, req)
+}
+
+// DoJSON mengirim req lalu mendecode response 2xx ke T. Response 204 atau
+// body kosong menghasilkan zero value.
+func DoJSON[T any](c *Client, req *http.Request) (T, error) {
+	var out T
+
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return out, &HTTPError{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       body,
+		}
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return out, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && err != io.EOF {
+		return out, fmt.Errorf("client: decode %s %s: %w", req.Method, req.URL, err)
+	}
+
+	return out, nil
+}
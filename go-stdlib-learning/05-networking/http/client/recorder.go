@@ -0,0 +1,317 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type Mode int
+
+const (
+	// ModeReplay hanya memakai fixture. Request yang tidak ada di fixture
+	// menghasilkan ErrNoInteraction, tidak pernah keluar ke jaringan.
+	ModeReplay Mode = iota
+	// ModeRecord meneruskan semua request ke server asli dan menimpa fixture
+	// saat Save dipanggil.
+	ModeRecord
+)
+
+// RecordEnv adalah env var untuk merekam ulang fixture:
+//
+//	HTTP_RECORD=1 go test ./...
+const RecordEnv = "HTTP_RECORD"
+
+var ErrNoInteraction = errors.New("client: no recorded interaction")
+
+// DefaultRedactedHeaders adalah header yang nilainya diganti "REDACTED"
+// sebelum ditulis ke fixture. Tambahkan header lain lewat Recorder.Redact.
+var DefaultRedactedHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+	"X-Api-Key", "Api-Key", "X-Auth-Token", "X-Access-Token", "X-Amz-Security-Token",
+}
+
+// DefaultRedactedQuery adalah parameter query yang nilainya diganti
+// "REDACTED" di fixture dan di log LogHooks. Nama dicocokkan tanpa melihat
+// huruf besar/kecil. Tambahkan parameter lain lewat Recorder.RedactQuery.
+var DefaultRedactedQuery = []string{
+	"api_key", "apikey", "key", "token", "access_token", "refresh_token", "id_token",
+	"client_secret", "password", "secret", "signature", "sig",
+	"X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token",
+}
+
+// ModeFromEnv mengembalikan ModeRecord kalau HTTP_RECORD diisi.
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body disimpan sebagai teks biasa supaya fixture mudah dibaca dan di-diff.
+// Body biner ditulis sebagai {"base64": "..."}.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+
+	var bin struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &bin); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(bin.Base64)
+	*b = raw
+
+	return err
+}
+
+// Recorder adalah http.RoundTripper yang merekam atau memutar ulang
+// interaksi HTTP dari file fixture JSON. Pasang di Config.Transport:
+//
+//	rec, err := client.NewRecorder("testdata/users.json", client.ModeFromEnv(), nil)
+//	t.Cleanup(func() { rec.Save() })
+//	c := client.New(client.Config{Transport: rec})
+//
+// Saat replay, request dicocokkan berdasarkan method, URL dan body, dan
+// setiap interaksi hanya dipakai sekali sesuai urutan rekaman.
+type Recorder struct {
+	path string
+	mode Mode
+	base http.RoundTripper
+
+	mu            sync.Mutex
+	interactions  []Interaction
+	used          []bool
+	redacted      []string
+	redactedQuery []string
+}
+
+// NewRecorder membuka fixture di path. base hanya dipakai saat merekam,
+// default http.DefaultTransport.
+func NewRecorder(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, base: base}
+	r.Redact(DefaultRedactedHeaders...)
+	r.RedactQuery(DefaultRedactedQuery...)
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("client: load fixture (record it with %s=1): %w", RecordEnv, err)
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("client: parse fixture %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+// Redact menambahkan header yang tidak boleh ditulis ke fixture, misalnya
+// header API key khusus milik sebuah layanan.
+func (r *Recorder) Redact(headers ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range headers {
+		r.redacted = append(r.redacted, http.CanonicalHeaderKey(name))
+	}
+}
+
+// RedactQuery menambahkan parameter query yang tidak boleh ditulis ke
+// fixture. Saat replay, URL request diredaksi dengan cara yang sama sebelum
+// dicocokkan, jadi nilai asli parameter ini tidak ikut menentukan match.
+func (r *Recorder) RedactQuery(params ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.redactedQuery = append(r.redactedQuery, params...)
+}
+
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url := redactURL(req.URL, r.redactedQuery)
+	for i, in := range r.interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != url || !bytes.Equal(in.Request.Body, body) {
+			continue
+		}
+		r.used[i] = true
+
+		res := in.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+			StatusCode:    res.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        res.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(res.Body)),
+			ContentLength: int64(len(res.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w for %s %s in %s", ErrNoInteraction, req.Method, url, r.path)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL, r.redactedQuery),
+			Header: redact(req.Header, r.redacted),
+			Body:   body,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redact(resp.Header, r.redacted),
+			Body:       respBody,
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Save menulis rekaman ke fixture. Di ModeReplay tidak melakukan apa pun.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func redact(h http.Header, names []string) http.Header {
+	h = h.Clone()
+	for _, name := range names {
+		if _, ok := h[name]; ok {
+			h[name] = []string{"REDACTED"}
+		}
+	}
+
+	return h
+}
+
+// redactURL mengganti nilai parameter query di params dan password di
+// userinfo dengan "REDACTED". Urutan parameter dipertahankan supaya URL di
+// fixture tetap sama dengan yang dikirim.
+func redactURL(u *url.URL, params []string) string {
+	clean := *u
+	if clean.RawQuery != "" {
+		pairs := strings.Split(clean.RawQuery, "&")
+		for i, pair := range pairs {
+			key, _, _ := strings.Cut(pair, "=")
+			name, err := url.QueryUnescape(key)
+			if err != nil {
+				name = key
+			}
+			for _, p := range params {
+				if strings.EqualFold(name, p) {
+					pairs[i] = key + "=REDACTED"
+					break
+				}
+			}
+		}
+		clean.RawQuery = strings.Join(pairs, "&")
+	}
+
+	return clean.Redacted()
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Timeouts mengatur batas waktu di tiap tahap koneksi. http.Client default
+// tidak punya timeout sama sekali, jadi server yang diam bisa menggantung
+// goroutine selamanya.
+type Timeouts struct {
+	// Request adalah batas satu percobaan, dari dial sampai body selesai dibaca.
+	Request        time.Duration
+	Dial           time.Duration
+	TLSHandshake   time.Duration
+	ResponseHeader time.Duration
+	IdleConn       time.Duration
+}
+
+var DefaultTimeouts = Timeouts{
+	Request:        30 * time.Second,
+	Dial:           5 * time.Second,
+	TLSHandshake:   5 * time.Second,
+	ResponseHeader: 10 * time.Second,
+	IdleConn:       90 * time.Second,
+}
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Request == 0 {
+		t.Request = DefaultTimeouts.Request
+	}
+	if t.Dial == 0 {
+		t.Dial = DefaultTimeouts.Dial
+	}
+	if t.TLSHandshake == 0 {
+		t.TLSHandshake = DefaultTimeouts.TLSHandshake
+	}
+	if t.ResponseHeader == 0 {
+		t.ResponseHeader = DefaultTimeouts.ResponseHeader
+	}
+	if t.IdleConn == 0 {
+		t.IdleConn = DefaultTimeouts.IdleConn
+	}
+
+	return t
+}
+
+func newTransport(t Timeouts, maxConnsPerHost int) *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DialContext = (&net.Dialer{Timeout: t.Dial, KeepAlive: 30 * time.Second}).DialContext
+	tr.TLSHandshakeTimeout = t.TLSHandshake
+	tr.ResponseHeaderTimeout = t.ResponseHeader
+	tr.IdleConnTimeout = t.IdleConn
+	tr.MaxConnsPerHost = maxConnsPerHost
+	if maxConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = maxConnsPerHost
+	}
+
+	return tr
+}
+
+// backoff menghitung jeda sebelum percobaan ke-attempt+1: eksponensial
+// dengan full jitter supaya client yang gagal bersamaan tidak menyerbu
+// server di detik yang sama.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.InitialBackoff << (attempt - 1)
+	if d <= 0 || d > c.cfg.MaxBackoff {
+		d = c.cfg.MaxBackoff
+	}
+
+	return time.Duration(c.rand.Int63n(int64(d) + 1))
+}
+
+// parseRetryAfter membaca Retry-After dalam bentuk detik ("120") atau
+// HTTP-date ("Wed, 21 Oct 2015 07:28:00 GMT").
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+)
+
+// BasicAuth meminta HTTP Basic auth. validate dipanggil dengan username dan
+// password dari header Authorization.
+func BasicAuth(realm string, validate func(username, password string) bool) Middleware {
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || !validate(username, password) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StaticUsers membuat fungsi validate dari map username -> password.
+// Perbandingan dilakukan atas hash supaya waktunya tidak bergantung pada
+// panjang atau isi password.
+func StaticUsers(users map[string]string) func(username, password string) bool {
+	hashed := make(map[string][32]byte, len(users))
+	for u, p := range users {
+		hashed[u] = sha256.Sum256([]byte(p))
+	}
+
+	return func(username, password string) bool {
+		want, ok := hashed[username]
+		got := sha256.Sum256([]byte(password))
+
+		return subtle.ConstantTimeCompare(want[:], got[:]) == 1 && ok
+	}
+}
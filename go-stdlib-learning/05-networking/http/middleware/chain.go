@@ -0,0 +1,23 @@
+package middleware
+
+import "net/http"
+
+// Middleware membungkus handler. Tipenya sama dengan server.Middleware di
+// paket router, jadi bisa langsung dipakai di Router.Use dan RouteGroup.
+type Middleware func(http.Handler) http.Handler
+
+// Chain menggabungkan beberapa middleware menjadi satu. Yang pertama paling
+// luar, jadi Chain(a, b)(h) sama dengan a(b(h)).
+//
+// Urutan yang disarankan:
+//
+//	Chain(RequestID(), AccessLog(logger), Recover(logger), CORS(opts),
+//		Gzip(gzip.DefaultCompression), ETag(), MaxBodySize(1<<20), Timeout(5*time.Second))
+func Chain(mw ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(mw) - 1; i >= 0; i-- {
+			h = mw[i](h)
+		}
+		return h
+	}
+}
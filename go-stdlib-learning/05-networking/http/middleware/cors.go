@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSOptions struct {
+	// AllowedOrigins berisi origin lengkap ("https://app.example.com") atau "*".
+	AllowedOrigins []string
+	// AllowedMethods default GET, HEAD, POST.
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	// Kalau true, origin dikirim apa adanya bersama
+	// Access-Control-Allow-Credentials. Tidak boleh digabung dengan "*":
+	// itu sama dengan mengizinkan situs mana pun membaca response yang
+	// memakai cookie user, jadi CORS akan panic.
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS menambahkan header CORS untuk origin yang diizinkan dan menjawab
+// preflight (OPTIONS + Access-Control-Request-Method) tanpa memanggil handler.
+// Origin yang tidak diizinkan tetap diteruskan tapi tanpa header CORS, jadi
+// browser yang akan memblokir.
+func CORS(opts CORSOptions) Middleware {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	allowAll := false
+	origins := make(map[string]bool, len(opts.AllowedOrigins))
+	for _, o := range opts.AllowedOrigins {
+		if o == "*" {
+			allowAll = true
+		}
+		origins[strings.ToLower(o)] = true
+	}
+	if allowAll && opts.AllowCredentials {
+		panic(`middleware: CORS AllowedOrigins "*" cannot be combined with AllowCredentials`)
+	}
+
+	methods := make(map[string]bool, len(opts.AllowedMethods))
+	for _, m := range opts.AllowedMethods {
+		methods[strings.ToUpper(m)] = true
+	}
+
+	headers := make(map[string]bool, len(opts.AllowedHeaders))
+	for _, h := range opts.AllowedHeaders {
+		headers[http.CanonicalHeaderKey(h)] = true
+	}
+
+	allowOrigin := func(origin string) string {
+		if !allowAll && !origins[strings.ToLower(origin)] {
+			return ""
+		}
+		if allowAll {
+			return "*"
+		}
+		return origin
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			allowed := ""
+			if origin != "" {
+				allowed = allowOrigin(origin)
+			}
+
+			if !preflight {
+				if allowed != "" {
+					h.Set("Access-Control-Allow-Origin", allowed)
+					if opts.AllowCredentials {
+						h.Set("Access-Control-Allow-Credentials", "true")
+					}
+					if len(opts.ExposedHeaders) > 0 {
+						h.Set("Access-Control-Expose-Headers", strings.Join(opts.ExposedHeaders, ", "))
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowed == "" || !methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))] {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			requested := parseHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+			for _, name := range requested {
+				if !headers[http.CanonicalHeaderKey(name)] {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+
+			h.Set("Access-Control-Allow-Origin", allowed)
+			h.Set("Access-Control-Allow-Methods", strings.Join(opts.AllowedMethods, ", "))
+			if len(requested) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func parseHeaderList(s string) []string {
+	var list []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag menambahkan ETag ke response GET/HEAD berstatus 200 dan menjawab
+// If-None-Match yang cocok dengan 304 tanpa body. Kalau handler sudah
+// mengisi ETag sendiri, nilai itu yang dipakai.
+//
+// Seluruh body di-buffer untuk menghitung hash, jadi pasang di dalam Gzip
+// (ETag dihitung dari body asli) dan hindari untuk response yang besar.
+func ETag() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedWriter{header: make(http.Header)}
+			next.ServeHTTP(bw, r)
+
+			for k, v := range bw.header {
+				w.Header()[k] = v
+			}
+			if bw.status == 0 {
+				bw.status = http.StatusOK
+			}
+			if bw.status != http.StatusOK {
+				w.WriteHeader(bw.status)
+				w.Write(bw.body.Bytes())
+				return
+			}
+
+			etag := w.Header().Get("ETag")
+			if etag == "" {
+				sum := sha256.Sum256(bw.body.Bytes())
+				etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+				w.Header().Set("ETag", etag)
+			}
+
+			if etagMatch(r.Header.Get("If-None-Match"), etag) {
+				h := w.Header()
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			w.Write(bw.body.Bytes())
+		})
+	}
+}
+
+// etagMatch memakai perbandingan lemah seperti yang diminta RFC 9110 untuk
+// If-None-Match: W/"x" dianggap sama dengan "x".
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(p)
+}
@@ -0,0 +1,268 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize: response yang lebih kecil dari ini tidak dikompres, overhead
+// header gzip malah membuatnya lebih besar.
+const gzipMinSize = 1024
+
+var gzipPools sync.Map // level -> *sync.Pool
+
+// Gzip mengompres response kalau client mengirim Accept-Encoding: gzip.
+// Response yang sudah punya Content-Encoding, berstatus 204/206/304, punya
+// Content-Range, bertipe konten yang sudah terkompres (gambar, video, zip)
+// atau lebih kecil dari 1 KiB dikirim apa adanya. Offset di Content-Range
+// menunjuk ke body asli, jadi range response tidak boleh dikompres.
+//
+// Body gzip dan body asli adalah representasi berbeda, jadi ETag response
+// yang dikompres diberi akhiran "-gzip" supaya cache tidak tertukar.
+// If-None-Match dari client dikembalikan ke ETag asli sebelum sampai ke
+// handler (atau middleware ETag di dalamnya).
+func Gzip(level int) Middleware {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		panic("middleware: invalid gzip level")
+	}
+	pool, _ := gzipPools.LoadOrStore(level, &sync.Pool{
+		New: func() any {
+			gz, _ := gzip.NewWriterLevel(nil, level)
+			return gz
+		},
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipWriter{ResponseWriter: w, pool: pool.(*sync.Pool)}
+			if inm := r.Header.Get("If-None-Match"); inm != "" {
+				plain, hadGzip := stripGzipETags(inm)
+				r = r.Clone(r.Context())
+				r.Header.Set("If-None-Match", plain)
+				gw.clientHasGzip = hadGzip
+			}
+
+			// kalau handler panic, jangan kirim apa pun supaya Recover di luar
+			// masih bisa menulis 500
+			completed := false
+			defer func() {
+				if completed {
+					gw.close()
+				} else {
+					gw.discard()
+				}
+			}()
+
+			next.ServeHTTP(gw, r)
+			completed = true
+		})
+	}
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+
+		// "gzip;q=0" berarti client menolak gzip
+		q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+
+	return false
+}
+
+// gzipWriter menahan body sampai gzipMinSize byte sebelum memutuskan
+// kompres atau tidak, karena header harus dikirim sebelum body.
+type gzipWriter struct {
+	http.ResponseWriter
+	pool *sync.Pool
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+
+	// client mengirim If-None-Match berisi ETag versi gzip
+	clientHasGzip bool
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	switch {
+	case code >= 100 && code < 200:
+		w.ResponseWriter.WriteHeader(code)
+	case !w.decided && w.status == 0:
+		w.status = code
+	}
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= gzipMinSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *gzipWriter) decide() error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// kalau tidak diisi, net/http akan menebak dari byte gzip
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	compress := w.shouldCompress()
+	if etag := h.Get("ETag"); etag != "" && (compress || (w.status == http.StatusNotModified && w.clientHasGzip)) {
+		h.Set("ETag", gzipETag(etag))
+	}
+
+	if compress {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+
+	return err
+}
+
+func (w *gzipWriter) shouldCompress() bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return false
+	}
+	if len(w.buf) < gzipMinSize {
+		return false
+	}
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+
+	ct := h.Get("Content-Type")
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip"} {
+		if strings.HasPrefix(ct, prefix) && ct != "image/svg+xml" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Flush memaksa keputusan kompresi supaya streaming (misalnya SSE) tetap jalan.
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipWriter) close() {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			// handler tidak menulis apa pun, biarkan net/http yang mengirim 200
+			return
+		}
+		w.decide()
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.gz.Reset(nil)
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// discard dipanggil kalau handler panic: data yang masih di-buffer dibuang
+// dan gzip stream tidak ditutup, karena response-nya memang tidak lengkap.
+func (w *gzipWriter) discard() {
+	w.buf = nil
+	if w.gz != nil {
+		w.gz.Reset(nil)
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// gzipETag mengubah "abc" menjadi "abc-gzip" (W/ dipertahankan).
+func gzipETag(etag string) string {
+	weak := strings.HasPrefix(etag, "W/")
+	tag := strings.TrimPrefix(etag, "W/")
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return etag
+	}
+
+	tag = tag[:len(tag)-1] + `-gzip"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// stripGzipETags kebalikan dari gzipETag untuk setiap ETag di header
+// If-None-Match.
+func stripGzipETags(header string) (string, bool) {
+	found := false
+	parts := strings.Split(header, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if plain, ok := strings.CutSuffix(part, `-gzip"`); ok {
+			part = plain + `"`
+			found = true
+		}
+		parts[i] = part
+	}
+
+	return strings.Join(parts, ", "), found
+}
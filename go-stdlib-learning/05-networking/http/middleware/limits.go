@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// MaxBodySize membatasi ukuran body request. Request dengan Content-Length
+// di atas batas langsung dijawab 413; sisanya dibungkus http.MaxBytesReader,
+// jadi handler mendapat *http.MaxBytesError saat membaca melewati batas.
+func MaxBodySize(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Timeout memakai http.TimeoutHandler: context request dibatalkan setelah d
+// dan client mendapat 503. Response di-buffer, jadi jangan dipakai untuk
+// endpoint streaming.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, d, "request timeout")
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AccessLog mencatat satu baris log terstruktur per request. Level mengikuti
+// status: 5xx Error, 4xx Warn, selain itu Info.
+func AccessLog(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+
+			next.ServeHTTP(sw, r)
+
+			level := slog.LevelInfo
+			switch {
+			case sw.Status() >= 500:
+				level = slog.LevelError
+			case sw.Status() >= 400:
+				level = slog.LevelWarn
+			}
+
+			logger.LogAttrs(r.Context(), level, "http request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", sw.Status()),
+				slog.Int64("bytes", sw.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote", r.RemoteAddr),
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+			)
+		})
+	}
+}
+
+// statusWriter mencatat status dan jumlah byte yang ditulis handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// Status mengembalikan 200 kalau handler tidak menulis apa pun, sama seperti
+// yang dikirim net/http.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *statusWriter) wroteHeader() bool {
+	return w.status != 0
+}
+
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap dipakai http.ResponseController untuk mencari writer asli.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
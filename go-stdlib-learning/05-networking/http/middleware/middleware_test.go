@@ -0,0 +1,418 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func text(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	})
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	serve(Chain(mark("a"), mark("b"), mark("c"))(text("ok")), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := strings.Join(order, ","); got != "a,b,c" {
+		t.Fatalf("unexpected order %s", got)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(seen) != 32 || rec.Header().Get(RequestIDHeader) != seen {
+		t.Fatalf("generated id %q, header %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	serve(h, req)
+	if seen != "abc-123" {
+		t.Fatalf("incoming id must be kept, got %q", seen)
+	}
+
+	req.Header.Set(RequestIDHeader, "bad\nid")
+	serve(h, req)
+	if seen == "bad\nid" || len(seen) != 32 {
+		t.Fatalf("unsafe id must be replaced, got %q", seen)
+	}
+}
+
+func TestAccessLogAndRecover(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	h := Chain(RequestID(), AccessLog(logger), Recover(logger))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := serve(h, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, entry)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected panic log and access log, got %d lines", len(lines))
+	}
+
+	panicLog, access := lines[0], lines[1]
+	if panicLog["panic"] != "boom" || !strings.Contains(panicLog["stack"].(string), "middleware_test.go") {
+		t.Fatalf("panic log must include value and stack: %v", panicLog)
+	}
+	if access["level"] != "ERROR" || access["status"] != float64(500) || access["path"] != "/orders/7" || access["request_id"] != "req-1" {
+		t.Fatalf("unexpected access log %v", access)
+	}
+}
+
+func TestRecoverAfterHeadersWritten(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := Recover(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	}))
+
+	if rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusAccepted {
+		t.Fatalf("status already sent must be kept, got %d", rec.Code)
+	}
+
+	abort := Recover(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Fatal("ErrAbortHandler must be re-panicked")
+		}
+	}()
+	serve(abort, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestCORS(t *testing.T) {
+	h := CORS(CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})(text("ok"))
+
+	preflight := func(origin, method, headers string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/orders", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return serve(h, req)
+	}
+
+	rec := preflight("https://app.example.com", http.MethodPut, "content-type, x-request-id")
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Fatalf("preflight must not reach handler: %d %q", rec.Code, rec.Body)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Methods":     "GET, PUT",
+		"Access-Control-Allow-Headers":     "content-type, x-request-id",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "600",
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	for _, rec := range []*httptest.ResponseRecorder{
+		preflight("https://evil.example.com", http.MethodPut, ""),
+		preflight("https://app.example.com", http.MethodDelete, ""),
+		preflight("https://app.example.com", http.MethodPut, "X-Secret"),
+	} {
+		if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("rejected preflight must not carry CORS headers: %v", rec.Header())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = serve(h, req)
+	if rec.Body.String() != "ok" || rec.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" || rec.Header().Get("Vary") != "Origin" {
+		t.Fatalf("simple request: %q %v", rec.Body, rec.Header())
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	h := CORS(CORSOptions{AllowedOrigins: []string{"*"}})(text("ok"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	if got := serve(h, req).Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("expected *, got %q", got)
+	}
+}
+
+func TestCORSRejectsWildcardWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal(`"*" with AllowCredentials must panic at construction`)
+		}
+	}()
+	CORS(CORSOptions{AllowedOrigins: []string{"https://app.example.com", "*"}, AllowCredentials: true})
+}
+
+func TestGzip(t *testing.T) {
+	large := strings.Repeat("go-journey ", 500)
+	h := Gzip(gzip.BestSpeed)(text(large))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := serve(h, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected gzip response, got %v", rec.Header())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("content type must be sniffed from plain body, got %q", rec.Header().Get("Content-Type"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != large {
+		t.Fatal("decompressed body mismatch")
+	}
+
+	cases := []struct {
+		name    string
+		accept  string
+		handler http.Handler
+	}{
+		{"small body", "gzip", text("tiny")},
+		{"not accepted", "identity", text(large)},
+		{"q=0", "gzip;q=0", text(large)},
+		{"already compressed", "gzip", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, large)
+		})},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", c.accept)
+		rec := serve(Gzip(gzip.DefaultCompression)(c.handler), req)
+		if rec.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: must not be compressed", c.name)
+		}
+	}
+}
+
+func TestGzipKeepsStatus(t *testing.T) {
+	h := Gzip(gzip.DefaultCompression)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, strings.Repeat("x", 4096))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	if rec := serve(h, req); rec.Code != http.StatusCreated || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestGzipSkipsRangeResponses(t *testing.T) {
+	content := strings.NewReader(strings.Repeat("go-journey ", 500))
+	h := Gzip(gzip.DefaultCompression)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data.txt", time.Time{}, content)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Range", "bytes=0-2047")
+	rec := serve(h, req)
+	if rec.Code != http.StatusPartialContent || rec.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("range response must not be compressed: %d %v", rec.Code, rec.Header())
+	}
+	if rec.Body.Len() != 2048 || rec.Header().Get("Content-Range") != "bytes 0-2047/5500" {
+		t.Fatalf("got %d bytes, Content-Range %q", rec.Body.Len(), rec.Header().Get("Content-Range"))
+	}
+
+	// Content-Range tanpa 206, misalnya 416 atau handler yang tidak standar
+	h = Gzip(gzip.DefaultCompression)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-4095/8192")
+		io.WriteString(w, strings.Repeat("x", 4096))
+	}))
+	if rec := serve(h, req); rec.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("response with Content-Range must not be compressed: %v", rec.Header())
+	}
+}
+
+func TestGzipETagPerEncoding(t *testing.T) {
+	large := strings.Repeat("go-journey ", 500)
+	h := Chain(Gzip(gzip.DefaultCompression), ETag())(text(large))
+
+	get := func(accept, inm string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", accept)
+		if inm != "" {
+			req.Header.Set("If-None-Match", inm)
+		}
+		return serve(h, req)
+	}
+
+	gz, plain := get("gzip", ""), get("identity", "")
+	gzTag, plainTag := gz.Header().Get("ETag"), plain.Header().Get("ETag")
+	if gzTag == plainTag || !strings.HasSuffix(gzTag, `-gzip"`) {
+		t.Fatalf("gzip and identity bodies need different ETags: %q %q", gzTag, plainTag)
+	}
+	if gz.Header().Get("Vary") != "Accept-Encoding" || plain.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatal("both variants must carry Vary: Accept-Encoding")
+	}
+
+	if rec := get("gzip", gzTag); rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != gzTag {
+		t.Fatalf("revalidating gzip variant: %d etag=%q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if rec := get("identity", plainTag); rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != plainTag {
+		t.Fatalf("revalidating identity variant: %d etag=%q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestGzipPanicLeavesResponseToRecover(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	h := Chain(Recover(logger), Gzip(gzip.DefaultCompression))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := serve(h, req)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "partial") {
+		t.Fatalf("expected clean 500, got %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var readErr error
+	h := MaxBodySize(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	if rec := serve(h, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789"))); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("declared length over limit: %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789"))
+	req.ContentLength = -1
+	serve(h, req)
+	var tooLarge *http.MaxBytesError
+	if !errors.As(readErr, &tooLarge) {
+		t.Fatalf("expected MaxBytesError, got %v", readErr)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := Timeout(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	if rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestETag(t *testing.T) {
+	body := `{"id":1}`
+	h := ETag()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != body || !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("first request: %d %q etag=%q", rec.Code, rec.Body, etag)
+	}
+
+	for _, inm := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-None-Match", inm)
+		rec := serve(h, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: %d %q", inm, rec.Code, rec.Body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	if rec := serve(h, req); rec.Code != http.StatusOK {
+		t.Fatalf("stale etag must get full response, got %d", rec.Code)
+	}
+
+	if rec := serve(h, httptest.NewRequest(http.MethodPost, "/", nil)); rec.Header().Get("ETag") != "" {
+		t.Fatal("POST must not get an ETag")
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	h := BasicAuth("admin", StaticUsers(map[string]string{"bisma": "rahasia"}))(text("ok"))
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusUnauthorized || !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), `Basic realm="admin"`) {
+		t.Fatalf("expected challenge, got %d %v", rec.Code, rec.Header())
+	}
+
+	for _, c := range []struct {
+		user, pass string
+		code       int
+	}{
+		{"bisma", "rahasia", http.StatusOK},
+		{"bisma", "salah", http.StatusUnauthorized},
+		{"other", "rahasia", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(c.user, c.pass)
+		if rec := serve(h, req); rec.Code != c.code {
+			t.Errorf("%s/%s: expected %d, got %d", c.user, c.pass, c.code, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover menangkap panic dari handler, mencatat pesan beserta stack trace,
+// lalu membalas 500 kalau header belum terkirim. http.ErrAbortHandler
+// diteruskan karena memang dipakai untuk membatalkan response secara sengaja.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				logger.LogAttrs(r.Context(), slog.LevelError, "panic recovered",
+					slog.String("panic", fmt.Sprint(v)),
+					slog.String("stack", string(debug.Stack())),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("request_id", RequestIDFromContext(r.Context())),
+				)
+
+				if !sw.wroteHeader() {
+					http.Error(sw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Router adalah router radix tree di atas net/http.
+//
+// Pola route:
+//
+//	/users              statis
+//	/users/{id}         parameter, cocok dengan satu segmen yang tidak kosong
+//	/static/{path...}   wildcard, cocok dengan sisa path (boleh kosong), harus di akhir
+//
+// Kalau beberapa route cocok, prioritasnya statis > parameter > wildcard.
+// Path yang cocok tapi method-nya tidak terdaftar dijawab 405 dengan header
+// Allow, OPTIONS dijawab otomatis, dan HEAD memakai handler GET.
+type Router struct {
+	RouteGroup
+
+	// NotFound dipanggil kalau tidak ada route yang cocok. Default http.NotFound.
+	NotFound http.Handler
+	// MethodNotAllowed dipanggil setelah header Allow diisi.
+	MethodNotAllowed http.Handler
+
+	root       *node
+	routes     []RouteInfo
+	middleware []Middleware
+	handler    http.Handler
+}
+
+// Middleware membungkus handler, sama seperti middleware net/http pada umumnya.
+type Middleware func(http.Handler) http.Handler
+
+// RouteInfo dipakai untuk daftar route (Routes dan PrintRoutes).
+type RouteInfo struct {
+	Method     string
+	Pattern    string
+	Middleware int // jumlah middleware group yang membungkus route ini
+}
+
+func NewRouter() *Router {
+	r := &Router{root: &node{}}
+	r.RouteGroup = RouteGroup{router: r}
+	r.handler = http.HandlerFunc(r.dispatch)
+
+	return r
+}
+
+// Use memasang middleware global. Berbeda dengan RouteGroup.Use, middleware ini
+// juga membungkus respons 404 dan 405.
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+	r.handler = chain(http.HandlerFunc(r.dispatch), r.middleware)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
+	var params []param
+	n := r.root.match(req.URL.Path, &params)
+	if n == nil {
+		if r.NotFound != nil {
+			r.NotFound.ServeHTTP(w, req)
+		} else {
+			http.NotFound(w, req)
+		}
+		return
+	}
+
+	h := n.handlers[req.Method]
+	if h == nil && req.Method == http.MethodHead {
+		h = n.handlers[http.MethodGet]
+	}
+	if h == nil {
+		w.Header().Set("Allow", n.allow())
+		switch {
+		case req.Method == http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+		case r.MethodNotAllowed != nil:
+			r.MethodNotAllowed.ServeHTTP(w, req)
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), matchKey{}, &match{pattern: n.pattern, params: params})
+	h.ServeHTTP(w, req.WithContext(ctx))
+}
+
+// Routes mengembalikan semua route terurut per pattern lalu method.
+func (r *Router) Routes() []RouteInfo {
+	routes := append([]RouteInfo(nil), r.routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
+
+// PrintRoutes menulis tabel route, berguna untuk debugging saat startup.
+func (r *Router) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tMIDDLEWARE")
+	for _, route := range r.Routes() {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", route.Method, route.Pattern, route.Middleware)
+	}
+
+	return tw.Flush()
+}
+
+func (r *Router) handle(method, pattern string, h http.Handler, middleware int) {
+	tokens, err := parsePattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("server: %s %s: %v", method, pattern, err))
+	}
+
+	n := r.root.insert(tokens, pattern)
+	if n.handlers == nil {
+		n.handlers = make(map[string]http.Handler)
+		n.pattern = pattern
+	}
+	if _, ok := n.handlers[method]; ok {
+		panic(fmt.Sprintf("server: duplicate route %s %s", method, pattern))
+	}
+	n.handlers[method] = h
+
+	r.routes = append(r.routes, RouteInfo{Method: method, Pattern: pattern, Middleware: middleware})
+}
+
+// RouteGroup adalah sekumpulan route dengan prefix dan middleware yang sama.
+// Middleware group hanya membungkus route yang didaftarkan setelah Use.
+type RouteGroup struct {
+	router     *Router
+	prefix     string
+	middleware []Middleware
+}
+
+func (g *RouteGroup) Group(prefix string, mw ...Middleware) *RouteGroup {
+	return &RouteGroup{
+		router:     g.router,
+		prefix:     g.prefix + strings.TrimSuffix(prefix, "/"),
+		middleware: append(append([]Middleware(nil), g.middleware...), mw...),
+	}
+}
+
+func (g *RouteGroup) Use(mw ...Middleware) {
+	g.middleware = append(g.middleware, mw...)
+}
+
+func (g *RouteGroup) Handle(method, pattern string, h http.Handler) {
+	g.router.handle(method, g.prefix+pattern, chain(h, g.middleware), len(g.middleware))
+}
+
+func (g *RouteGroup) HandleFunc(method, pattern string, h http.HandlerFunc) {
+	g.Handle(method, pattern, h)
+}
+
+func (g *RouteGroup) GET(pattern string, h http.HandlerFunc) {
+	g.Handle(http.MethodGet, pattern, h)
+}
+
+func (g *RouteGroup) POST(pattern string, h http.HandlerFunc) {
+	g.Handle(http.MethodPost, pattern, h)
+}
+
+func (g *RouteGroup) PUT(pattern string, h http.HandlerFunc) {
+	g.Handle(http.MethodPut, pattern, h)
+}
+
+func (g *RouteGroup) PATCH(pattern string, h http.HandlerFunc) {
+	g.Handle(http.MethodPatch, pattern, h)
+}
+
+func (g *RouteGroup) DELETE(pattern string, h http.HandlerFunc) {
+	g.Handle(http.MethodDelete, pattern, h)
+}
+
+// chain memasang middleware dengan urutan: yang pertama paling luar.
+func chain(h http.Handler, mw []Middleware) http.Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	return h
+}
+
+type matchKey struct{}
+
+type match struct {
+	pattern string
+	params  []param
+}
+
+type param struct {
+	name, value string
+}
+
+// Param mengembalikan nilai parameter path, misalnya Param(r, "id") untuk
+// route /users/{id}. String kosong kalau tidak ada.
+func Param(r *http.Request, name string) string {
+	m, _ := r.Context().Value(matchKey{}).(*match)
+	if m == nil {
+		return ""
+	}
+	for _, p := range m.params {
+		if p.name == name {
+			return p.value
+		}
+	}
+
+	return ""
+}
+
+// RoutePattern mengembalikan pola route yang cocok, misalnya "/users/{id}".
+// Cocok dipakai sebagai label di log atau metrics supaya tidak meledak per id.
+func RoutePattern(r *http.Request) string {
+	m, _ := r.Context().Value(matchKey{}).(*match)
+	if m == nil {
+		return ""
+	}
+
+	return m.pattern
+}
+
+type tokenKind int
+
+const (
+	tokenStatic tokenKind = iota
+	tokenParam
+	tokenWildcard
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// parsePattern memecah "/users/{id}/posts" menjadi statis "/users/",
+// parameter "id" dan statis "/posts".
+func parsePattern(pattern string) ([]token, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("pattern must start with /")
+	}
+
+	var (
+		tokens []token
+		static strings.Builder
+		seen   = map[string]bool{}
+	)
+
+	segments := strings.Split(pattern[1:], "/")
+	for i, seg := range segments {
+		static.WriteByte('/')
+
+		if !strings.ContainsAny(seg, "{}") {
+			static.WriteString(seg)
+			continue
+		}
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			return nil, fmt.Errorf("parameter %q must fill the whole segment", seg)
+		}
+
+		name, kind := seg[1:len(seg)-1], tokenParam
+		if strings.HasSuffix(name, "...") {
+			name, kind = strings.TrimSuffix(name, "..."), tokenWildcard
+			if i != len(segments)-1 {
+				return nil, fmt.Errorf("wildcard {%s...} must be the last segment", name)
+			}
+		}
+		if name == "" || strings.ContainsAny(name, "{}") {
+			return nil, fmt.Errorf("invalid parameter %q", seg)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate parameter %q", name)
+		}
+		seen[name] = true
+
+		tokens = append(tokens, token{tokenStatic, static.String()})
+		tokens = append(tokens, token{kind, name})
+		static.Reset()
+	}
+	if static.Len() > 0 {
+		tokens = append(tokens, token{tokenStatic, static.String()})
+	}
+
+	return tokens, nil
+}
+
+// node adalah simpul radix tree. Anak statis berbagi prefix yang dipadatkan,
+// sedangkan parameter dan wildcard punya slot sendiri karena nilainya tidak
+// diketahui saat insert.
+type node struct {
+	prefix   string
+	children []*node
+	param    *node
+	wildcard *node
+	name     string // nama parameter untuk node param dan wildcard
+
+	handlers map[string]http.Handler
+	pattern  string
+}
+
+func (n *node) insert(tokens []token, pattern string) *node {
+	if len(tokens) == 0 {
+		return n
+	}
+
+	t, rest := tokens[0], tokens[1:]
+	switch t.kind {
+	case tokenParam:
+		if n.param == nil {
+			n.param = &node{name: t.text}
+		} else if n.param.name != t.text {
+			panic(fmt.Sprintf("server: %s: parameter {%s} conflicts with {%s}", pattern, t.text, n.param.name))
+		}
+		return n.param.insert(rest, pattern)
+	case tokenWildcard:
+		if n.wildcard == nil {
+			n.wildcard = &node{name: t.text}
+		} else if n.wildcard.name != t.text {
+			panic(fmt.Sprintf("server: %s: wildcard {%s...} conflicts with {%s...}", pattern, t.text, n.wildcard.name))
+		}
+		return n.wildcard
+	default:
+		return n.insertStatic(t.text, rest, pattern)
+	}
+}
+
+func (n *node) insertStatic(s string, rest []token, pattern string) *node {
+	for _, child := range n.children {
+		common := commonPrefix(child.prefix, s)
+		if common == 0 {
+			continue
+		}
+
+		if common < len(child.prefix) {
+			// pecah child: bagian sisa prefix turun satu level
+			split := *child
+			split.prefix = child.prefix[common:]
+			*child = node{prefix: child.prefix[:common], children: []*node{&split}}
+		}
+		if common == len(s) {
+			return child.insert(rest, pattern)
+		}
+		return child.insertStatic(s[common:], rest, pattern)
+	}
+
+	child := &node{prefix: s}
+	n.children = append(n.children, child)
+
+	return child.insert(rest, pattern)
+}
+
+func (n *node) match(path string, params *[]param) *node {
+	if path == "" && n.handlers != nil {
+		return n
+	}
+
+	if path != "" {
+		for _, child := range n.children {
+			if child.prefix[0] != path[0] {
+				continue
+			}
+			if strings.HasPrefix(path, child.prefix) {
+				if m := child.match(path[len(child.prefix):], params); m != nil {
+					return m
+				}
+			}
+			break
+		}
+
+		if n.param != nil {
+			end := strings.IndexByte(path, '/')
+			if end < 0 {
+				end = len(path)
+			}
+			if end > 0 {
+				*params = append(*params, param{n.param.name, path[:end]})
+				if m := n.param.match(path[end:], params); m != nil {
+					return m
+				}
+				*params = (*params)[:len(*params)-1]
+			}
+		}
+	}
+
+	if n.wildcard != nil {
+		*params = append(*params, param{n.wildcard.name, path})
+		return n.wildcard
+	}
+
+	return nil
+}
+
+func (n *node) allow() string {
+	methods := make([]string, 0, len(n.handlers)+2)
+	for m := range n.handlers {
+		methods = append(methods, m)
+	}
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	if _, ok := n.handlers[http.MethodOptions]; !ok {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+
+	return strings.Join(methods, ", ")
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
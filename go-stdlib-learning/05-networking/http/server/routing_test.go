@@ -0,0 +1,188 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func echoRoute(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(RoutePattern(r) + " id=" + Param(r, "id") + " path=" + Param(r, "path")))
+}
+
+func serve(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestMatchPriority(t *testing.T) {
+	r := NewRouter()
+	r.GET("/", echoRoute)
+	r.GET("/users", echoRoute)
+	r.GET("/users/new", echoRoute)
+	r.GET("/users/{id}", echoRoute)
+	r.GET("/users/{id}/posts", echoRoute)
+	r.GET("/user-settings", echoRoute)
+	r.GET("/files/{path...}", echoRoute)
+	r.GET("/files/readme", echoRoute)
+
+	cases := map[string]string{
+		"/":                   "/ id= path=",
+		"/users":              "/users id= path=",
+		"/users/new":          "/users/new id= path=",
+		"/users/42":           "/users/{id} id=42 path=",
+		"/users/newbie":       "/users/{id} id=newbie path=",
+		"/users/42/posts":     "/users/{id}/posts id=42 path=",
+		"/user-settings":      "/user-settings id= path=",
+		"/files/readme":       "/files/readme id= path=",
+		"/files/a/b/c.txt":    "/files/{path...} id= path=a/b/c.txt",
+		"/files/":             "/files/{path...} id= path=",
+		"/users/new/posts":    "/users/{id}/posts id=new path=",
+		"/users/%C3%A9/posts": "/users/{id}/posts id=é path=",
+	}
+	for path, want := range cases {
+		rec := serve(r, http.MethodGet, path)
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("GET %s: got %d %q, want %q", path, rec.Code, rec.Body, want)
+		}
+	}
+
+	for _, path := range []string{"/users/", "/users/42/", "/usersx", "/files", "/nope"} {
+		if rec := serve(r, http.MethodGet, path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d %q", path, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/{id}", echoRoute)
+	r.DELETE("/users/{id}", echoRoute)
+
+	rec := serve(r, http.MethodPost, "/users/1")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "DELETE, GET, HEAD, OPTIONS" {
+		t.Fatalf("unexpected Allow %q", allow)
+	}
+
+	rec = serve(r, http.MethodOptions, "/users/1")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") == "" {
+		t.Fatalf("OPTIONS: %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	if rec := serve(r, http.MethodHead, "/users/1"); rec.Code != http.StatusOK {
+		t.Fatalf("HEAD must fall back to GET, got %d", rec.Code)
+	}
+}
+
+func TestGroupsAndMiddleware(t *testing.T) {
+	var trace []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trace = append(trace, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r := NewRouter()
+	r.Use(mark("global"))
+	r.GET("/health", echoRoute)
+
+	api := r.Group("/api/", mark("api"))
+	v1 := api.Group("/v1", mark("v1"))
+	v1.GET("/users/{id}", echoRoute)
+
+	trace = nil
+	rec := serve(r, http.MethodGet, "/api/v1/users/7")
+	if rec.Body.String() != "/api/v1/users/{id} id=7 path=" {
+		t.Fatalf("unexpected body %q", rec.Body)
+	}
+	if got := strings.Join(trace, ","); got != "global,api,v1" {
+		t.Fatalf("middleware order: %s", got)
+	}
+
+	trace = nil
+	serve(r, http.MethodGet, "/health")
+	if got := strings.Join(trace, ","); got != "global" {
+		t.Fatalf("group middleware leaked to /health: %s", got)
+	}
+
+	trace = nil
+	serve(r, http.MethodGet, "/missing")
+	if got := strings.Join(trace, ","); got != "global" {
+		t.Fatalf("global middleware must wrap 404: %s", got)
+	}
+}
+
+func TestInvalidPatternsPanic(t *testing.T) {
+	cases := []func(r *Router){
+		func(r *Router) { r.GET("users", echoRoute) },
+		func(r *Router) { r.GET("/users/id-{id}", echoRoute) },
+		func(r *Router) { r.GET("/files/{path...}/x", echoRoute) },
+		func(r *Router) { r.GET("/a/{id}/b/{id}", echoRoute) },
+		func(r *Router) { r.GET("/users/{id}", echoRoute); r.GET("/users/{uid}/x", echoRoute) },
+		func(r *Router) { r.GET("/users", echoRoute); r.GET("/users", echoRoute) },
+	}
+
+	for i, register := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("case %d: expected panic", i)
+				}
+			}()
+			register(NewRouter())
+		}()
+	}
+}
+
+func TestStaticFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0644)
+	os.Mkdir(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "index.html"), []byte("<h1>docs</h1>"), 0644)
+	os.Mkdir(filepath.Join(dir, "private"), 0755)
+	os.WriteFile(filepath.Join(dir, "private", "secret.txt"), []byte("x"), 0644)
+
+	r := NewRouter()
+	r.Static("/assets", dir)
+
+	if rec := serve(r, http.MethodGet, "/assets/app.js"); rec.Code != http.StatusOK || rec.Body.String() != "console.log(1)" {
+		t.Fatalf("file: %d %q", rec.Code, rec.Body)
+	}
+	if rec := serve(r, http.MethodGet, "/assets/docs/"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "docs") {
+		t.Fatalf("index.html: %d %q", rec.Code, rec.Body)
+	}
+	if rec := serve(r, http.MethodGet, "/assets/private/"); rec.Code != http.StatusNotFound {
+		t.Fatalf("directory listing must be disabled, got %d", rec.Code)
+	}
+	if rec := serve(r, http.MethodGet, "/assets/../routing.go"); rec.Code == http.StatusOK {
+		t.Fatal("path traversal must not escape dir")
+	}
+}
+
+func ExampleRouter_PrintRoutes() {
+	r := NewRouter()
+	r.GET("/health", echoRoute)
+
+	api := r.Group("/api", func(next http.Handler) http.Handler { return next })
+	api.GET("/users/{id}", echoRoute)
+	api.PUT("/users/{id}", echoRoute)
+	api.GET("/users", echoRoute)
+
+	r.PrintRoutes(os.Stdout)
+	// Output:
+	// METHOD  PATTERN          MIDDLEWARE
+	// GET     /api/users       1
+	// GET     /api/users/{id}  1
+	// PUT     /api/users/{id}  1
+	// GET     /health          0
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path"
+)
+
+// Static melayani file dari dir di bawah prefix memakai route wildcard
+// prefix+"/{filepath...}". Directory listing dimatikan, folder hanya bisa
+// dibuka kalau ada index.html.
+func (g *RouteGroup) Static(prefix, dir string) {
+	fs := http.FileServer(noListingFS{http.Dir(dir)})
+
+	g.GET(prefix+"/{filepath...}", func(w http.ResponseWriter, r *http.Request) {
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/" + Param(r, "filepath")
+		r2.URL.RawPath = ""
+
+		fs.ServeHTTP(w, r2)
+	})
+}
+
+type noListingFS struct {
+	fs http.FileSystem
+}
+
+func (n noListingFS) Open(name string) (http.File, error) {
+	f, err := n.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		index, err := n.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, os.ErrNotExist
+		}
+		index.Close()
+	}
+
+	return f, nil
+}
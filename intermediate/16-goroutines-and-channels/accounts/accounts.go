@@ -0,0 +1,314 @@
+// Package accounts adalah versi serius dari contoh BankAccount di
+// 03/mutex_test.go: banyak rekening, transfer antar rekening yang aman dari
+// deadlock, aturan overdraft, riwayat transaksi, dan snapshot yang
+// konsisten.
+//
+// Setiap Account punya mutex sendiri sehingga transfer antar pasangan
+// rekening yang berbeda berjalan paralel. Deadlock klasik terjadi kalau
+// transfer A->B mengunci A lalu B, sementara B->A mengunci B lalu A. Di sini
+// kunci selalu diambil berurutan menurut nomor urut rekening, jadi siklus
+// tunggu tidak mungkin terjadi.
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAccountNotFound   = errors.New("accounts: account not found")
+	ErrAccountExists     = errors.New("accounts: account already exists")
+	ErrInsufficientFunds = errors.New("accounts: insufficient funds")
+	ErrInvalidAmount     = errors.New("accounts: amount must be positive")
+	ErrSameAccount       = errors.New("accounts: cannot transfer to the same account")
+	ErrBalanceOverflow   = errors.New("accounts: balance would overflow")
+)
+
+type Kind string
+
+const (
+	KindOpen     Kind = "open"
+	KindDeposit  Kind = "deposit"
+	KindWithdraw Kind = "withdraw"
+	KindTransfer Kind = "transfer"
+)
+
+// Transaction adalah satu baris riwayat. Amount dalam satuan terkecil
+// (misalnya sen), From kosong untuk deposit dan To kosong untuk withdraw.
+type Transaction struct {
+	ID     uint64
+	Kind   Kind
+	From   string
+	To     string
+	Amount int64
+	At     time.Time
+}
+
+type Account struct {
+	id  string
+	seq uint64 // urutan kunci, lihat doc package
+
+	mu        sync.Mutex
+	balance   int64
+	overdraft int64
+}
+
+// AccountOptions mengatur rekening baru.
+type AccountOptions struct {
+	// Initial adalah saldo awal, dicatat sebagai transaksi open.
+	Initial int64
+	// OverdraftLimit adalah seberapa jauh saldo boleh negatif; 0 berarti
+	// saldo tidak boleh di bawah nol.
+	OverdraftLimit int64
+}
+
+type Bank struct {
+	mu       sync.RWMutex
+	accounts map[string]*Account
+	nextSeq  uint64
+
+	historyMu sync.Mutex
+	history   []Transaction
+	now       func() time.Time
+}
+
+func NewBank() *Bank {
+	return &Bank{accounts: make(map[string]*Account), now: time.Now}
+}
+
+func (b *Bank) Open(id string, opts AccountOptions) error {
+	if opts.Initial < 0 || opts.OverdraftLimit < 0 {
+		return fmt.Errorf("accounts: open %s: initial balance and overdraft limit must not be negative", id)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.accounts[id]; ok {
+		return fmt.Errorf("%w: %s", ErrAccountExists, id)
+	}
+	b.nextSeq++
+	b.accounts[id] = &Account{id: id, seq: b.nextSeq, balance: opts.Initial, overdraft: opts.OverdraftLimit}
+	b.record(Transaction{Kind: KindOpen, To: id, Amount: opts.Initial})
+	return nil
+}
+
+func (b *Bank) account(id string) (*Account, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	a, ok := b.accounts[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, id)
+	}
+	return a, nil
+}
+
+func (b *Bank) Balance(id string) (int64, error) {
+	a, err := b.account(id)
+	if err != nil {
+		return 0, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.balance, nil
+}
+
+func (b *Bank) Deposit(id string, amount int64) (Transaction, error) {
+	if amount <= 0 {
+		return Transaction{}, ErrInvalidAmount
+	}
+	a, err := b.account(id)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.canCredit(amount); err != nil {
+		return Transaction{}, err
+	}
+	a.balance += amount
+	return b.record(Transaction{Kind: KindDeposit, To: id, Amount: amount}), nil
+}
+
+func (b *Bank) Withdraw(id string, amount int64) (Transaction, error) {
+	if amount <= 0 {
+		return Transaction{}, ErrInvalidAmount
+	}
+	a, err := b.account(id)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.canDebit(amount); err != nil {
+		return Transaction{}, err
+	}
+	a.balance -= amount
+	return b.record(Transaction{Kind: KindWithdraw, From: id, Amount: amount}), nil
+}
+
+// Transfer memindahkan amount dari satu rekening ke rekening lain secara
+// atomik: kedua saldo berubah bersamaan atau tidak sama sekali.
+func (b *Bank) Transfer(from, to string, amount int64) (Transaction, error) {
+	if amount <= 0 {
+		return Transaction{}, ErrInvalidAmount
+	}
+	if from == to {
+		return Transaction{}, ErrSameAccount
+	}
+	src, err := b.account(from)
+	if err != nil {
+		return Transaction{}, err
+	}
+	dst, err := b.account(to)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	// kunci selalu dari seq kecil ke besar, apa pun arah transfernya
+	first, second := src, dst
+	if second.seq < first.seq {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	second.mu.Lock()
+	defer second.mu.Unlock()
+
+	if err := src.canDebit(amount); err != nil {
+		return Transaction{}, err
+	}
+	if err := dst.canCredit(amount); err != nil {
+		return Transaction{}, err
+	}
+	src.balance -= amount
+	dst.balance += amount
+	return b.record(Transaction{Kind: KindTransfer, From: from, To: to, Amount: amount}), nil
+}
+
+// canDebit dipanggil dengan a.mu sudah terkunci. Batasnya dihitung sebagai
+// balance+overdraft, bukan balance-amount, supaya amount besar tidak
+// overflow lalu lolos pengecekan.
+func (a *Account) canDebit(amount int64) error {
+	available := a.balance + a.overdraft
+	if available < a.balance {
+		// balance dan overdraft sama-sama besar, dananya praktis tak terbatas
+		available = math.MaxInt64
+	}
+	if amount > available {
+		return fmt.Errorf("%w: %s has %d, overdraft limit %d, needs %d", ErrInsufficientFunds, a.id, a.balance, a.overdraft, amount)
+	}
+	return nil
+}
+
+// canCredit dipanggil dengan a.mu sudah terkunci.
+func (a *Account) canCredit(amount int64) error {
+	if a.balance > math.MaxInt64-amount {
+		return fmt.Errorf("%w: %s has %d, cannot add %d", ErrBalanceOverflow, a.id, a.balance, amount)
+	}
+	return nil
+}
+
+// record dipanggil selagi kunci rekening yang terlibat masih dipegang,
+// sehingga urutan riwayat sama dengan urutan perubahan saldo. historyMu
+// selalu diambil paling akhir.
+func (b *Bank) record(tx Transaction) Transaction {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	tx.ID = uint64(len(b.history) + 1)
+	tx.At = b.now()
+	b.history = append(b.history, tx)
+	return tx
+}
+
+// History mengembalikan transaksi yang melibatkan id, paling lama dulu.
+// id kosong berarti semua transaksi.
+func (b *Bank) History(id string) []Transaction {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	var out []Transaction
+	for _, tx := range b.history {
+		if id == "" || tx.From == id || tx.To == id {
+			out = append(out, tx)
+		}
+	}
+	return out
+}
+
+// Snapshot adalah saldo semua rekening pada satu titik waktu yang sama.
+// LastTxID adalah transaksi terakhir yang sudah tercermin di Balances.
+type Snapshot struct {
+	Balances map[string]int64
+	Total    int64
+	LastTxID uint64
+	At       time.Time
+}
+
+// Snapshot mengunci semua rekening (berurutan menurut seq, sama seperti
+// Transfer) supaya tidak ada transfer yang tertangkap setengah jalan. Selama
+// snapshot dibuat semua transaksi lain menunggu, jadi jangan dipanggil
+// terlalu sering di sistem yang sibuk.
+func (b *Bank) Snapshot() Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	accounts := make([]*Account, 0, len(b.accounts))
+	for _, a := range b.accounts {
+		accounts = append(accounts, a)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].seq < accounts[j].seq })
+
+	for _, a := range accounts {
+		a.mu.Lock()
+	}
+	defer func() {
+		for _, a := range accounts {
+			a.mu.Unlock()
+		}
+	}()
+
+	s := Snapshot{Balances: make(map[string]int64, len(accounts)), At: b.now()}
+	for _, a := range accounts {
+		s.Balances[a.id] = a.balance
+		s.Total += a.balance
+	}
+
+	b.historyMu.Lock()
+	s.LastTxID = uint64(len(b.history))
+	b.historyMu.Unlock()
+
+	return s
+}
+
+// Replay menghitung ulang saldo dari riwayat transaksi sampai upTo
+// (inklusif, 0 berarti semua). Hasilnya harus sama dengan Snapshot pada
+// LastTxID yang sama; dipakai untuk audit dan di stress test.
+func Replay(history []Transaction, upTo uint64) map[string]int64 {
+	balances := make(map[string]int64)
+	for _, tx := range history {
+		if upTo > 0 && tx.ID > upTo {
+			break
+		}
+		switch tx.Kind {
+		case KindOpen, KindDeposit:
+			balances[tx.To] += tx.Amount
+		case KindWithdraw:
+			balances[tx.From] -= tx.Amount
+		case KindTransfer:
+			balances[tx.From] -= tx.Amount
+			balances[tx.To] += tx.Amount
+		}
+	}
+	return balances
+}
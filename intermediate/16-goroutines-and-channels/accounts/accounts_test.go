@@ -0,0 +1,292 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransferAndOverdraft(t *testing.T) {
+	bank := NewBank()
+	if err := bank.Open("alice", AccountOptions{Initial: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bank.Open("bob", AccountOptions{OverdraftLimit: 50}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bank.Open("alice", AccountOptions{}); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("duplicate open: %v", err)
+	}
+
+	if _, err := bank.Transfer("alice", "bob", 30); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bank.Transfer("alice", "bob", 71); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("alice has no overdraft: %v", err)
+	}
+	// bob boleh minus sampai -50
+	if _, err := bank.Withdraw("bob", 80); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bank.Withdraw("bob", 1); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("overdraft limit exceeded: %v", err)
+	}
+
+	alice, _ := bank.Balance("alice")
+	bob, _ := bank.Balance("bob")
+	if alice != 70 || bob != -50 {
+		t.Fatalf("balances alice=%d bob=%d", alice, bob)
+	}
+
+	cases := map[string]struct {
+		from, to string
+		amount   int64
+		want     error
+	}{
+		"same account": {"alice", "alice", 1, ErrSameAccount},
+		"unknown":      {"alice", "carol", 1, ErrAccountNotFound},
+		"zero":         {"alice", "bob", 0, ErrInvalidAmount},
+		"negative":     {"alice", "bob", -5, ErrInvalidAmount},
+	}
+	for name, c := range cases {
+		if _, err := bank.Transfer(c.from, c.to, c.amount); !errors.Is(err, c.want) {
+			t.Errorf("%s: got %v want %v", name, err, c.want)
+		}
+	}
+}
+
+func TestAmountsNearInt64Limits(t *testing.T) {
+	bank := NewBank()
+	bank.Open("debtor", AccountOptions{OverdraftLimit: 10})
+	bank.Open("rich", AccountOptions{Initial: math.MaxInt64 - 5})
+	bank.Open("payer", AccountOptions{Initial: 100})
+	bank.Withdraw("debtor", 5)
+
+	if _, err := bank.Withdraw("debtor", math.MaxInt64); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("huge withdraw must not wrap around the overdraft check: %v", err)
+	}
+	if _, err := bank.Transfer("debtor", "rich", math.MaxInt64); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("huge transfer must not wrap around the overdraft check: %v", err)
+	}
+	if _, err := bank.Deposit("rich", 6); !errors.Is(err, ErrBalanceOverflow) {
+		t.Fatalf("deposit past MaxInt64: %v", err)
+	}
+	if _, err := bank.Transfer("payer", "rich", 6); !errors.Is(err, ErrBalanceOverflow) {
+		t.Fatalf("transfer past MaxInt64: %v", err)
+	}
+	if _, err := bank.Deposit("rich", 5); err != nil {
+		t.Fatalf("deposit up to MaxInt64: %v", err)
+	}
+
+	debtor, _ := bank.Balance("debtor")
+	rich, _ := bank.Balance("rich")
+	payer, _ := bank.Balance("payer")
+	if debtor != -5 || rich != math.MaxInt64 || payer != 100 {
+		t.Fatalf("failed operations must not change balances: debtor=%d rich=%d payer=%d", debtor, rich, payer)
+	}
+}
+
+func TestHistoryAndReplay(t *testing.T) {
+	bank := NewBank()
+	bank.Open("a", AccountOptions{Initial: 10})
+	bank.Open("b", AccountOptions{})
+	bank.Deposit("b", 5)
+	bank.Transfer("a", "b", 7)
+	bank.Withdraw("b", 2)
+	bank.Withdraw("a", 100) // gagal, tidak boleh masuk riwayat
+
+	all := bank.History("")
+	if len(all) != 5 {
+		t.Fatalf("history %v", all)
+	}
+	for i, tx := range all {
+		if tx.ID != uint64(i+1) {
+			t.Fatalf("IDs must be sequential: %v", all)
+		}
+	}
+	if got := bank.History("a"); len(got) != 2 || got[1].Kind != KindTransfer {
+		t.Fatalf("history a %v", got)
+	}
+
+	snap := bank.Snapshot()
+	if snap.Total != 13 || snap.LastTxID != 5 {
+		t.Fatalf("snapshot %+v", snap)
+	}
+	if fmt.Sprint(Replay(all, 0)) != fmt.Sprint(snap.Balances) {
+		t.Fatalf("replay %v snapshot %v", Replay(all, 0), snap.Balances)
+	}
+	if got := Replay(all, 3); got["b"] != 5 {
+		t.Fatalf("replay up to 3 %v", got)
+	}
+}
+
+// stressConfig mengatur harness di bawah. Setiap worker melakukan transfer
+// acak antar rekening (termasuk arah berlawanan yang memicu deadlock kalau
+// urutan kunci salah), sementara pengamat terus mengambil snapshot.
+type stressConfig struct {
+	Accounts  int
+	Workers   int
+	Transfers int // per worker
+	Initial   int64
+	Overdraft int64
+	MaxAmount int64
+	Seed      int64
+}
+
+type stressReport struct {
+	Succeeded, Rejected int64
+	Snapshots           []Snapshot
+}
+
+func runStress(t *testing.T, cfg stressConfig) (*Bank, stressReport) {
+	t.Helper()
+
+	bank := NewBank()
+	for i := 0; i < cfg.Accounts; i++ {
+		if err := bank.Open(fmt.Sprintf("acc-%02d", i), AccountOptions{Initial: cfg.Initial, OverdraftLimit: cfg.Overdraft}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var report stressReport
+	var wg sync.WaitGroup
+	// semua worker menunggu di start supaya benar-benar berjalan bersamaan,
+	// bukan satu per satu sesuai urutan goroutine dibuat
+	start := make(chan struct{})
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			<-start
+			rng := rand.New(rand.NewSource(cfg.Seed + int64(w)))
+			for i := 0; i < cfg.Transfers; i++ {
+				from := rng.Intn(cfg.Accounts)
+				to := (from + 1 + rng.Intn(cfg.Accounts-1)) % cfg.Accounts
+				amount := 1 + rng.Int63n(cfg.MaxAmount)
+
+				_, err := bank.Transfer(fmt.Sprintf("acc-%02d", from), fmt.Sprintf("acc-%02d", to), amount)
+				switch {
+				case err == nil:
+					atomic.AddInt64(&report.Succeeded, 1)
+				case errors.Is(err, ErrInsufficientFunds):
+					atomic.AddInt64(&report.Rejected, 1)
+				default:
+					t.Errorf("worker %d: %v", w, err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	stop := make(chan struct{})
+	observed := make(chan []Snapshot)
+	go func() {
+		var snaps []Snapshot
+		for {
+			select {
+			case <-stop:
+				observed <- snaps
+				return
+			default:
+				snaps = append(snaps, bank.Snapshot())
+			}
+		}
+	}()
+	close(start)
+
+	// deadlock tidak membuat test menggantung selamanya
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("transfers did not finish, possible deadlock")
+	}
+	close(stop)
+	report.Snapshots = <-observed
+	return bank, report
+}
+
+func TestStressInvariants(t *testing.T) {
+	cfg := stressConfig{
+		Accounts:  6,
+		Workers:   64,
+		Transfers: 500,
+		Initial:   1_000,
+		Overdraft: 200,
+		MaxAmount: 600,
+		Seed:      42,
+	}
+	if testing.Short() {
+		cfg.Workers = 10
+	}
+	bank, report := runStress(t, cfg)
+
+	want := int64(cfg.Accounts) * cfg.Initial
+	total := int64(cfg.Workers * cfg.Transfers)
+	if report.Succeeded+report.Rejected != total {
+		t.Fatalf("%d succeeded + %d rejected != %d", report.Succeeded, report.Rejected, total)
+	}
+	if report.Succeeded == 0 || report.Rejected == 0 {
+		t.Fatalf("stress config should exercise both paths: %+v", report)
+	}
+
+	history := bank.History("")
+	if n := int64(len(history)); n != int64(cfg.Accounts)+report.Succeeded {
+		t.Fatalf("history has %d entries, want %d", n, int64(cfg.Accounts)+report.Succeeded)
+	}
+
+	final := bank.Snapshot()
+	report.Snapshots = append(report.Snapshots, final)
+	// snapshot diambil berurutan oleh satu goroutine, jadi LastTxID naik dan
+	// riwayat cukup di-replay sekali secara bertahap
+	replayed := make(map[string]int64)
+	var replayedUpTo uint64
+	for i, snap := range report.Snapshots {
+		if snap.Total != want {
+			t.Fatalf("snapshot %d: total %d want %d", i, snap.Total, want)
+		}
+		for id, bal := range snap.Balances {
+			if bal < -cfg.Overdraft {
+				t.Fatalf("snapshot %d: %s at %d is beyond overdraft limit", i, id, bal)
+			}
+		}
+		// snapshot harus bisa direkonstruksi persis dari riwayat
+		for id, delta := range Replay(history[replayedUpTo:snap.LastTxID], 0) {
+			replayed[id] += delta
+		}
+		replayedUpTo = snap.LastTxID
+		for id, bal := range snap.Balances {
+			if replayed[id] != bal {
+				t.Fatalf("snapshot %d (tx %d): %s replayed %d, snapshot %d", i, snap.LastTxID, id, replayed[id], bal)
+			}
+		}
+	}
+	t.Logf("%d transfers ok, %d rejected, %d snapshots", report.Succeeded, report.Rejected, len(report.Snapshots))
+}
+
+func BenchmarkTransferParallel(b *testing.B) {
+	bank := NewBank()
+	ids := make([]string, 16)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("acc-%02d", i)
+		bank.Open(ids[i], AccountOptions{Initial: 1 << 40})
+	}
+	var seed int64
+	b.RunParallel(func(pb *testing.PB) {
+		rng := rand.New(rand.NewSource(atomic.AddInt64(&seed, 1)))
+		for pb.Next() {
+			from := rng.Intn(len(ids))
+			to := (from + 1 + rng.Intn(len(ids)-1)) % len(ids)
+			bank.Transfer(ids[from], ids[to], 1)
+		}
+	})
+}